@@ -28,13 +28,29 @@ func main() {
 	}
 
 	// Initialize WebSocket Hub
-	services.InitHub()
+	services.InitHub(config.AppConfig.WSBroker)
+
+	// Initialize attachment storage
+	if err := services.InitStorage(config.AppConfig.ChatStorage); err != nil {
+		log.Fatal("Failed to initialize attachment storage:", err)
+	}
+
+	// Initialize message content filter (length, profanity, links, rate limit)
+	if err := services.InitContentPipeline(); err != nil {
+		log.Fatal("Failed to initialize content filter:", err)
+	}
+
+	// Load member/guest profile vectors into the similar-profile search index
+	if err := services.InitProfileIndex(config.AppConfig.ProfileIndex); err != nil {
+		log.Fatal("Failed to initialize profile index:", err)
+	}
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:      "Ongi Backend API",
 		ServerHeader: "Fiber",
 		ErrorHandler: customErrorHandler,
+		BodyLimit:    int(services.AttachmentConfig.MaxSize) + 1<<20, // 첨부파일 + multipart 오버헤드
 	})
 
 	// Middleware
@@ -45,7 +61,7 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
-		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
+		AllowMethods: "GET, POST, PUT, PATCH, DELETE, OPTIONS",
 	}))
 
 	// Setup routes
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"log"
+	"ongi-back/config"
+	"ongi-back/database"
+	"ongi-back/services"
+)
+
+func main() {
+	log.Println("Starting club chat room backfill...")
+
+	// Load configuration
+	config.Load()
+
+	// Connect to database
+	if err := database.Connect(); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	// Run migrations first
+	if err := database.Migrate(); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	// Create missing club rooms and add club members to them
+	if err := services.BackfillClubChatRooms(); err != nil {
+		log.Fatal("Failed to backfill club chat rooms:", err)
+	}
+
+	log.Println("Club chat room backfill completed successfully!")
+}
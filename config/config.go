@@ -8,10 +8,13 @@ import (
 )
 
 type Config struct {
-	Port          string
-	DatabaseURL   string
-	JWTSecret     string
-	Environment   string
+	Port         string
+	DatabaseURL  string
+	JWTSecret    string
+	Environment  string
+	WSBroker     string // WebSocket 브로커 (memory, postgres)
+	ChatStorage  string // 채팅 첨부파일 저장소 (local, s3)
+	ProfileIndex string // 유사 프로필 검색 인덱스 (kdtree, bruteforce, pgvector)
 }
 
 var AppConfig *Config
@@ -23,10 +26,13 @@ func Load() {
 	}
 
 	AppConfig = &Config{
-		Port:        getEnv("PORT", "3000"),
-		DatabaseURL: getEnv("DATABASE_URL", ""),
-		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
-		Environment: getEnv("ENVIRONMENT", "development"),
+		Port:         getEnv("PORT", "3000"),
+		DatabaseURL:  getEnv("DATABASE_URL", ""),
+		JWTSecret:    getEnv("JWT_SECRET", "your-secret-key"),
+		Environment:  getEnv("ENVIRONMENT", "development"),
+		WSBroker:     getEnv("WS_BROKER", "memory"),
+		ChatStorage:  getEnv("CHAT_STORAGE", "local"),
+		ProfileIndex: getEnv("PROFILE_INDEX", "kdtree"),
 	}
 
 	log.Println("Configuration loaded")
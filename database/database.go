@@ -23,7 +23,8 @@ type DBConfig struct {
 	SSLMode  string
 }
 
-func Connect() error {
+// DSN 환경 변수로부터 Postgres 접속 문자열 생성
+func DSN() string {
 	config := DBConfig{
 		Host:     getEnv("DB_HOST", "localhost"),
 		Port:     getEnv("DB_PORT", "5432"),
@@ -33,12 +34,14 @@ func Connect() error {
 		SSLMode:  getEnv("DB_SSLMODE", "disable"),
 	}
 
-	dsn := fmt.Sprintf(
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
 	)
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+func Connect() error {
+	db, err := gorm.Open(postgres.Open(DSN()), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 
@@ -69,16 +72,87 @@ func Migrate() error {
 		&models.ChatRoom{},
 		&models.ChatRoomMember{},
 		&models.ChatMessage{},
+		&models.ChatMessageEdit{},
+		&models.ChatAttachment{},
+		&models.ChatRoomBan{},
+		&models.ChatModerationLog{},
+		&models.ChatMessageReaction{},
+		&models.ChatPresence{},
+		&models.RefreshToken{},
+		&models.UsedWSTicket{},
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	// 기존 last_read_at 기준으로 읽음 커서(last_read_message_id) 채우기
+	if err := DB.Exec(`
+		UPDATE chat_room_members m
+		SET last_read_message_id = (
+			SELECT MAX(id) FROM chat_messages
+			WHERE chat_room_id = m.chat_room_id AND created_at <= m.last_read_at
+		)
+		WHERE m.last_read_message_id IS NULL AND m.last_read_at IS NOT NULL`).Error; err != nil {
+		return fmt.Errorf("failed to backfill read cursors: %w", err)
+	}
+
+	// 메시지 검색용 trigram 인덱스 (한국어 부분 일치 검색)
+	// pg_trgm 확장 생성 권한이 없으면 인덱스 없이 검색 (느리지만 동작함)
+	if err := DB.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Printf("pg_trgm extension unavailable, message search will not use an index: %v", err)
+	} else if err := DB.Exec(`CREATE INDEX IF NOT EXISTS idx_chat_messages_message_trgm
+		ON chat_messages USING gin (message gin_trgm_ops)`).Error; err != nil {
+		return fmt.Errorf("failed to create message search index: %w", err)
+	}
+
 	log.Println("Database migration completed")
 	return nil
 }
 
+// EnablePgvector pgvector 확장과 유사 프로필 검색용 embedding 컬럼/인덱스 생성 (PROFILE_INDEX=pgvector)
+// embedding은 기존 값(세션 jsonb 벡터, 회원 성향 점수)에서 계산되는 생성 컬럼이라
+// 컬럼 추가 시 기존 행이 채워지고 이후 저장할 때마다 자동으로 갱신됨
+// metric: 회원 프로필 인덱스의 l2 (기본) 또는 cosine (세션 벡터는 게스트 기준인 cosine으로 고정)
+// 확장을 만들 수 없으면 에러 반환 (호출 측에서 메모리 인덱스로 대체)
+func EnablePgvector(metric string) error {
+	if err := DB.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+		return fmt.Errorf("pgvector extension unavailable: %w", err)
+	}
+
+	opclass := "vector_cosine_ops"
+	if metric != "cosine" {
+		metric, opclass = "l2", "vector_l2_ops"
+	}
+
+	statements := []string{
+		`ALTER TABLE session_vectors ADD COLUMN IF NOT EXISTS embedding vector(5)
+			GENERATED ALWAYS AS (
+				CASE WHEN jsonb_array_length("vector") = 5 THEN ARRAY[
+					("vector"->>0)::float8, ("vector"->>1)::float8, ("vector"->>2)::float8,
+					("vector"->>3)::float8, ("vector"->>4)::float8
+				]::vector(5) END
+			) STORED`,
+		`ALTER TABLE user_profiles ADD COLUMN IF NOT EXISTS embedding vector(5)
+			GENERATED ALWAYS AS (ARRAY[
+				COALESCE(sociality_score, 0), COALESCE(activity_score, 0), COALESCE(intimacy_score, 0),
+				COALESCE(immersion_score, 0), COALESCE(flexibility_score, 0)
+			]::vector(5)) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_session_vectors_embedding_cosine
+			ON session_vectors USING hnsw (embedding vector_cosine_ops)`,
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_user_profiles_embedding_%s
+			ON user_profiles USING hnsw (embedding %s)`, metric, opclass),
+	}
+	for _, statement := range statements {
+		if err := DB.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to set up pgvector columns: %w", err)
+		}
+	}
+
+	log.Printf("pgvector embedding columns ready (metric=%s)", metric)
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"ongi-back/database"
+	"ongi-back/middleware"
+	"ongi-back/models"
+	"ongi-back/services"
+	"ongi-back/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UploadAttachment 채팅 첨부파일 업로드 (multipart/form-data, 필드 이름: file)
+// POST /chat/rooms/:id/attachments
+// 업로드 후 응답의 attachment.id를 메시지 전송 시 attachment_id로 전달
+func UploadAttachment(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "file is required",
+		})
+	}
+
+	attachment, err := services.UploadChatAttachment(uint(roomID), userID, fileHeader)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Attachment uploaded successfully",
+		"data": fiber.Map{
+			"attachment": attachment,
+			"urls":       signedAttachmentURLs(attachment, userID),
+		},
+	})
+}
+
+// GetAttachmentURL 첨부파일 다운로드용 서명 URL 발급
+// GET /chat/attachments/:id/url
+func GetAttachmentURL(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var attachment models.ChatAttachment
+	if err := database.DB.First(&attachment, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Attachment not found",
+		})
+	}
+
+	if _, err := findMembership(attachment.ChatRoomID, userID); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "User is not a member of this chat room",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    signedAttachmentURLs(&attachment, userID),
+	})
+}
+
+// DownloadAttachment 서명 URL로 첨부파일 다운로드
+// GET /attachments/:id?variant=&uid=&exp=&sig=
+// <img src>에서 바로 쓸 수 있도록 Authorization 헤더 대신 서명으로 인증하고, 다운로드 시점의 멤버십을 다시 확인
+func DownloadAttachment(c *fiber.Ctx) error {
+	attachmentID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid attachment ID",
+		})
+	}
+
+	userID, _ := strconv.ParseUint(c.Query("uid"), 10, 32)
+	expires, _ := strconv.ParseInt(c.Query("exp"), 10, 64)
+	variant := c.Query("variant")
+
+	if !utils.VerifyAttachmentSignature(uint(attachmentID), uint(userID), variant, expires, c.Query("sig")) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid or expired signature",
+		})
+	}
+
+	attachment, reader, err := services.OpenChatAttachment(uint(attachmentID), uint(userID), variant)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+
+	contentType := attachment.ContentType
+	if variant == "thumbnail" {
+		contentType = "image/jpeg"
+	}
+
+	// 이미지만 inline으로 표시하고 나머지는 다운로드
+	disposition := "attachment"
+	if strings.HasPrefix(contentType, "image/") {
+		disposition = "inline"
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`%s; filename="%s"`, disposition, attachment.FileName))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=300")
+
+	// SendStream이 스트림을 다 읽은 뒤 닫음
+	return c.SendStream(reader)
+}
+
+// signedAttachmentURLs 원본/썸네일 서명 URL
+func signedAttachmentURLs(attachment *models.ChatAttachment, userID uint) fiber.Map {
+	expiresAt := time.Now().Add(utils.AttachmentURLExpiry)
+
+	var thumbnailURL *string
+	if attachment.ThumbnailKey != nil {
+		url := utils.SignAttachmentURL(attachment.ID, userID, "thumbnail", expiresAt)
+		thumbnailURL = &url
+	}
+
+	return fiber.Map{
+		"url":           utils.SignAttachmentURL(attachment.ID, userID, "", expiresAt),
+		"thumbnail_url": thumbnailURL,
+		"expires_at":    expiresAt,
+	}
+}
+
+// attachmentError 첨부파일 에러를 HTTP 응답으로 변환
+func attachmentError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrNotRoomMember):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "User is not a member of this chat room",
+		})
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Attachment not found",
+		})
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"success": false,
+			"error":   fmt.Sprintf("Attachment exceeds %d bytes", services.AttachmentConfig.MaxSize),
+		})
+	case errors.Is(err, services.ErrAttachmentType):
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"success": false,
+			"error":   "Attachment type is not allowed",
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   "Failed to process attachment",
+	})
+}
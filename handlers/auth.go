@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"ongi-back/database"
+	"ongi-back/middleware"
 	"ongi-back/models"
 	"ongi-back/services"
 	"ongi-back/utils"
@@ -25,10 +27,12 @@ type LoginRequest struct {
 
 // AuthResponse 인증 응답
 type AuthResponse struct {
-	Success   bool        `json:"success"`
-	Token     string      `json:"token"`
-	User      models.User `json:"user"`
-	IsNewUser bool        `json:"is_new_user,omitempty"`
+	Success      bool        `json:"success"`
+	Token        string      `json:"token"`
+	RefreshToken string      `json:"refresh_token"`
+	ExpiresIn    int64       `json:"expires_in"`
+	User         models.User `json:"user"`
+	IsNewUser    bool        `json:"is_new_user,omitempty"`
 }
 
 // KakaoLoginRequest 카카오 로그인 요청
@@ -38,10 +42,17 @@ type KakaoLoginRequest struct {
 
 // KakaoLoginResponse 카카오 로그인 응답
 type KakaoLoginResponse struct {
-	Success   bool        `json:"success"`
-	Token     string      `json:"token"`
-	User      models.User `json:"user"`
-	IsNewUser bool        `json:"is_new_user"`
+	Success      bool        `json:"success"`
+	Token        string      `json:"token"`
+	RefreshToken string      `json:"refresh_token"`
+	ExpiresIn    int64       `json:"expires_in"`
+	User         models.User `json:"user"`
+	IsNewUser    bool        `json:"is_new_user"`
+}
+
+// RefreshTokenRequest 토큰 재발급/로그아웃 요청
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 // KakaoLogin 카카오 로그인 처리 (클라이언트사이드 OAuth)
@@ -84,7 +95,7 @@ func KakaoLogin(c *fiber.Ctx) error {
 	}
 
 	// 3. JWT 토큰 발급
-	token, err := utils.GenerateJWT(user.ID, user.Email)
+	tokens, err := services.IssueTokenPair(user, c.Get(fiber.HeaderUserAgent))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -95,10 +106,12 @@ func KakaoLogin(c *fiber.Ctx) error {
 
 	// 4. 응답 반환
 	return c.Status(fiber.StatusOK).JSON(KakaoLoginResponse{
-		Success:   true,
-		Token:     token,
-		User:      user,
-		IsNewUser: isNewUser,
+		Success:      true,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user,
+		IsNewUser:    isNewUser,
 	})
 }
 
@@ -156,7 +169,7 @@ func KakaoCallback(c *fiber.Ctx) error {
 	}
 
 	// 5. JWT 토큰 발급
-	token, err := utils.GenerateJWT(user.ID, user.Email)
+	tokens, err := services.IssueTokenPair(user, c.Get(fiber.HeaderUserAgent))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -167,10 +180,12 @@ func KakaoCallback(c *fiber.Ctx) error {
 
 	// 6. 응답 반환
 	return c.Status(fiber.StatusOK).JSON(KakaoLoginResponse{
-		Success:   true,
-		Token:     token,
-		User:      user,
-		IsNewUser: isNewUser,
+		Success:      true,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user,
+		IsNewUser:    isNewUser,
 	})
 }
 
@@ -273,7 +288,7 @@ func Register(c *fiber.Ctx) error {
 	}
 
 	// JWT 토큰 발급
-	token, err := utils.GenerateJWT(user.ID, user.Email)
+	tokens, err := services.IssueTokenPair(user, c.Get(fiber.HeaderUserAgent))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -283,10 +298,12 @@ func Register(c *fiber.Ctx) error {
 
 	// 응답 반환
 	return c.Status(fiber.StatusCreated).JSON(AuthResponse{
-		Success:   true,
-		Token:     token,
-		User:      user,
-		IsNewUser: true,
+		Success:      true,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user,
+		IsNewUser:    true,
 	})
 }
 
@@ -337,7 +354,7 @@ func Login(c *fiber.Ctx) error {
 	}
 
 	// JWT 토큰 발급
-	token, err := utils.GenerateJWT(user.ID, user.Email)
+	tokens, err := services.IssueTokenPair(user, c.Get(fiber.HeaderUserAgent))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -347,8 +364,98 @@ func Login(c *fiber.Ctx) error {
 
 	// 응답 반환
 	return c.Status(fiber.StatusOK).JSON(AuthResponse{
-		Success: true,
-		Token:   token,
-		User:    user,
+		Success:      true,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user,
+	})
+}
+
+// RefreshAccessToken Refresh Token으로 토큰 재발급 (Refresh Token 회전)
+// POST /auth/refresh
+func RefreshAccessToken(c *fiber.Ctx) error {
+	var req RefreshTokenRequest
+
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Refresh token is required",
+		})
+	}
+
+	tokens, user, err := services.RotateRefreshToken(req.RefreshToken, c.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		message := "Failed to refresh token"
+
+		switch {
+		case errors.Is(err, services.ErrInvalidRefreshToken):
+			status, message = fiber.StatusUnauthorized, "Invalid refresh token"
+		case errors.Is(err, services.ErrRefreshTokenExpired):
+			status, message = fiber.StatusUnauthorized, "Refresh token expired"
+		case errors.Is(err, services.ErrRefreshTokenReused):
+			status, message = fiber.StatusUnauthorized, "Refresh token reuse detected. Please log in again."
+		}
+
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error":   message,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(AuthResponse{
+		Success:      true,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         *user,
+	})
+}
+
+// Logout 현재 기기 로그아웃 (Refresh Token 패밀리 폐기)
+// POST /auth/logout
+func Logout(c *fiber.Ctx) error {
+	var req RefreshTokenRequest
+
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Refresh token is required",
+		})
+	}
+
+	if err := services.RevokeRefreshToken(req.RefreshToken); err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid refresh token",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to logout",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Logged out successfully",
+	})
+}
+
+// LogoutAll 모든 기기에서 로그아웃
+// POST /auth/logout-all
+func LogoutAll(c *fiber.Ctx) error {
+	if err := services.RevokeAllRefreshTokens(middleware.GetUserID(c)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to logout from all devices",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Logged out from all devices",
 	})
 }
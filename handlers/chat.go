@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"ongi-back/database"
+	"ongi-back/middleware"
 	"ongi-back/models"
 	"ongi-back/services"
 	"strconv"
@@ -31,26 +34,51 @@ func CreateChatRoom(c *fiber.Ctx) error {
 		})
 	}
 
-	// 생성자 ID (실제로는 JWT에서 가져와야 함)
-	// 여기서는 임시로 request body에서 받거나 기본값 사용
-	createdBy := uint(1) // TODO: JWT에서 user_id 추출
+	// 생성자 ID (JWT에서 추출)
+	createdBy := middleware.GetUserID(c)
 
 	// 기본값 설정
 	if req.RoomType == "" {
 		req.RoomType = "group"
 	}
 
-	// 채팅방 생성
-	chatRoom := models.ChatRoom{
+	// 1:1 채팅방은 중복 방지를 위해 전용 API로만 생성
+	if req.RoomType == services.RoomTypeDirect {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Use POST /chat/direct to create a direct chat room",
+		})
+	}
+
+	// 클럽 채팅방은 클럽 생성/가입 시 자동으로 관리됨
+	if req.RoomType == services.RoomTypeClub {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Club chat rooms are created automatically with the club",
+		})
+	}
+
+	// 채팅방, 멤버, 생성 시스템 메시지를 한 번에 생성
+	chatRoom, err := services.CreateGroupChatRoom(createdBy, services.CreateGroupChatInput{
 		Name:        req.Name,
 		Description: req.Description,
 		ClubID:      req.ClubID,
 		RoomType:    req.RoomType,
-		CreatedBy:   createdBy,
-		MemberCount: len(req.MemberIDs) + 1, // 생성자 포함
-	}
-
-	if err := database.DB.Create(&chatRoom).Error; err != nil {
+		MemberIDs:   req.MemberIDs,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRoomName):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "name must be 1-50 characters",
+			})
+		case errors.Is(err, services.ErrInvalidMembers):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "member_ids contains unknown users",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
 			"error":   "Failed to create chat room",
@@ -58,32 +86,6 @@ func CreateChatRoom(c *fiber.Ctx) error {
 		})
 	}
 
-	// 생성자를 admin으로 추가
-	creatorMember := models.ChatRoomMember{
-		ChatRoomID: chatRoom.ID,
-		UserID:     createdBy,
-		Role:       "admin",
-		JoinedAt:   time.Now(),
-	}
-	database.DB.Create(&creatorMember)
-
-	// 멤버 추가
-	for _, memberID := range req.MemberIDs {
-		if memberID == createdBy {
-			continue // 생성자는 이미 추가됨
-		}
-		member := models.ChatRoomMember{
-			ChatRoomID: chatRoom.ID,
-			UserID:     memberID,
-			Role:       "member",
-			JoinedAt:   time.Now(),
-		}
-		database.DB.Create(&member)
-	}
-
-	// 생성된 채팅방 정보 조회 (멤버 정보 포함)
-	database.DB.Preload("Members.User").Preload("Creator").Preload("Club").First(&chatRoom, chatRoom.ID)
-
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
 		"message": "Chat room created successfully",
@@ -91,26 +93,69 @@ func CreateChatRoom(c *fiber.Ctx) error {
 	})
 }
 
-// GetChatRooms 사용자의 채팅방 목록 조회
-// GET /chat/rooms
-func GetChatRooms(c *fiber.Ctx) error {
-	// 사용자 ID (실제로는 JWT에서 가져와야 함)
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
+// CreateDirectChatRequest 1:1 채팅방 생성 요청
+type CreateDirectChatRequest struct {
+	UserID uint `json:"user_id" validate:"required"` // 상대방 사용자 ID
+}
+
+// CreateDirectChat 1:1 채팅방 조회 또는 생성
+// POST /chat/direct
+func CreateDirectChat(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var req CreateDirectChatRequest
+	if err := c.BodyParser(&req); err != nil || req.UserID == 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error":   "user_id is required",
 		})
 	}
 
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	chatRoom, created, err := services.GetOrCreateDirectRoom(userID, req.UserID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		switch {
+		case errors.Is(err, services.ErrDirectWithSelf):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Cannot create a direct chat with yourself",
+			})
+		case errors.Is(err, services.ErrUserNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"error":   "User not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"error":   "Invalid user_id",
+			"error":   "Failed to create direct chat room",
+			"details": err.Error(),
 		})
 	}
 
+	rooms := []models.ChatRoom{*chatRoom}
+	services.FillDirectRoomNames(userID, rooms)
+
+	if !created {
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "Direct chat room already exists",
+			"data":    rooms[0],
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Direct chat room created successfully",
+		"data":    rooms[0],
+	})
+}
+
+// GetChatRooms 사용자의 채팅방 목록 조회
+// GET /chat/rooms
+func GetChatRooms(c *fiber.Ctx) error {
+	// 사용자 ID (JWT에서 추출)
+	userID := middleware.GetUserID(c)
+
 	// 사용자가 속한 채팅방 멤버십 조회
 	var memberships []models.ChatRoomMember
 	if err := database.DB.Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
@@ -122,8 +167,10 @@ func GetChatRooms(c *fiber.Ctx) error {
 
 	// 채팅방 ID 목록 추출
 	var roomIDs []uint
+	membershipByRoom := make(map[uint]models.ChatRoomMember, len(memberships))
 	for _, membership := range memberships {
 		roomIDs = append(roomIDs, membership.ChatRoomID)
+		membershipByRoom[membership.ChatRoomID] = membership
 	}
 
 	if len(roomIDs) == 0 {
@@ -133,13 +180,22 @@ func GetChatRooms(c *fiber.Ctx) error {
 		})
 	}
 
-	// 채팅방 정보 조회
-	var chatRooms []models.ChatRoom
-	if err := database.DB.
+	// 채팅방 정보 조회 (?archived=true면 보관된 채팅방만, 고정한 채팅방이 먼저)
+	query := database.DB.
 		Preload("Creator").
 		Preload("Club").
-		Where("id IN ?", roomIDs).
-		Order("last_message_at DESC NULLS LAST, created_at DESC").
+		Select("chat_rooms.*").
+		Joins("JOIN chat_room_members m ON m.chat_room_id = chat_rooms.id AND m.user_id = ?", userID).
+		Where("chat_rooms.id IN ?", roomIDs)
+	if c.QueryBool("archived") {
+		query = query.Where("chat_rooms.archived_at IS NOT NULL")
+	} else {
+		query = query.Where("chat_rooms.archived_at IS NULL")
+	}
+
+	var chatRooms []models.ChatRoom
+	if err := query.
+		Order("m.pinned_at DESC NULLS LAST, chat_rooms.last_message_at DESC NULLS LAST, chat_rooms.created_at DESC").
 		Find(&chatRooms).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -147,6 +203,24 @@ func GetChatRooms(c *fiber.Ctx) error {
 		})
 	}
 
+	// 읽음 커서 기준 안 읽은 메시지 수
+	unreadCounts, err := services.GetUnreadCounts(userID, roomIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch unread counts",
+		})
+	}
+	for i := range chatRooms {
+		membership := membershipByRoom[chatRooms[i].ID]
+		chatRooms[i].UnreadCount = unreadCounts[chatRooms[i].ID]
+		chatRooms[i].Pinned = membership.PinnedAt != nil
+		chatRooms[i].NotificationsMuted = membership.NotificationsMuted
+	}
+
+	// 1:1 채팅방은 상대방 이름으로 표시
+	services.FillDirectRoomNames(userID, chatRooms)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    chatRooms,
@@ -157,6 +231,16 @@ func GetChatRooms(c *fiber.Ctx) error {
 // GET /chat/rooms/:id
 func GetChatRoom(c *fiber.Ctx) error {
 	roomID := c.Params("id")
+	userID := middleware.GetUserID(c)
+
+	// 사용자가 채팅방 멤버인지 확인
+	membership, err := findMembership(roomID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "User is not a member of this chat room",
+		})
+	}
 
 	var chatRoom models.ChatRoom
 	if err := database.DB.
@@ -170,26 +254,42 @@ func GetChatRoom(c *fiber.Ctx) error {
 		})
 	}
 
+	chatRoom.Pinned = membership.PinnedAt != nil
+	chatRoom.NotificationsMuted = membership.NotificationsMuted
+
+	rooms := []models.ChatRoom{chatRoom}
+	services.FillDirectRoomNames(userID, rooms)
+
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data":    chatRoom,
+		"data":    rooms[0],
 	})
 }
 
 // SendMessageRequest 메시지 전송 요청
 type SendMessageRequest struct {
-	UserID      uint   `json:"user_id" validate:"required"`
-	Message     string `json:"message" validate:"required"`
-	MessageType string `json:"message_type"` // text, image, file, system
-	FileURL     string `json:"file_url"`
+	Message         string `json:"message" validate:"required"`
+	MessageType     string `json:"message_type"` // text, image, file
+	FileURL         string `json:"file_url"`
+	AttachmentID    uint   `json:"attachment_id"`     // 업로드한 첨부파일 ID
+	ClientMessageID string `json:"client_message_id"` // 재전송 시 중복 방지용 멱등성 키
+	ReplyToID       uint   `json:"reply_to_id"`       // 답장할 메시지 ID (스레드)
 }
 
 // SendMessage 메시지 전송
 // POST /chat/rooms/:id/messages
 func SendMessage(c *fiber.Ctx) error {
-	roomID := c.Params("id")
+	userID := middleware.GetUserID(c)
 	var req SendMessageRequest
 
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
@@ -197,91 +297,261 @@ func SendMessage(c *fiber.Ctx) error {
 		})
 	}
 
-	// 채팅방 존재 확인
-	var chatRoom models.ChatRoom
-	if err := database.DB.First(&chatRoom, roomID).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"error":   "Chat room not found",
+	message, duplicate, err := services.SendChatMessage(uint(roomID), userID, services.SendMessageInput{
+		Message:         req.Message,
+		MessageType:     req.MessageType,
+		FileURL:         req.FileURL,
+		AttachmentID:    req.AttachmentID,
+		ClientMessageID: req.ClientMessageID,
+		ReplyToID:       req.ReplyToID,
+	})
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	// 이미 전송된 메시지 (재시도)
+	if duplicate {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "Message already sent",
+			"data":    message,
 		})
 	}
 
-	// 사용자가 채팅방 멤버인지 확인
-	var membership models.ChatRoomMember
-	if err := database.DB.Where("chat_room_id = ? AND user_id = ?", roomID, req.UserID).First(&membership).Error; err != nil {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Message sent successfully",
+		"data":    message,
+	})
+}
+
+// EditMessageRequest 메시지 수정 요청
+type EditMessageRequest struct {
+	Message string `json:"message" validate:"required"`
+}
+
+// EditMessage 메시지 수정 (작성자만 가능)
+// PATCH /chat/rooms/:id/messages/:msgId
+func EditMessage(c *fiber.Ctx) error {
+	roomID, messageID, err := parseMessageParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "User is not a member of this chat room",
+			"error":   "Invalid room or message ID",
 		})
 	}
 
-	// 기본값 설정
-	if req.MessageType == "" {
-		req.MessageType = "text"
+	var req EditMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
 	}
 
-	var fileURL *string
-	if req.FileURL != "" {
-		fileURL = &req.FileURL
+	message, err := services.EditChatMessage(roomID, messageID, middleware.GetUserID(c), req.Message)
+	if err != nil {
+		return chatServiceError(c, err)
 	}
 
-	// 메시지 생성
-	message := models.ChatMessage{
-		ChatRoomID:  chatRoom.ID,
-		UserID:      req.UserID,
-		Message:     req.Message,
-		MessageType: req.MessageType,
-		FileURL:     fileURL,
-	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Message updated successfully",
+		"data":    message,
+	})
+}
 
-	if err := database.DB.Create(&message).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+// DeleteMessage 메시지 삭제 (작성자 또는 채팅방 admin)
+// DELETE /chat/rooms/:id/messages/:msgId
+func DeleteMessage(c *fiber.Ctx) error {
+	roomID, messageID, err := parseMessageParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "Failed to send message",
-			"details": err.Error(),
+			"error":   "Invalid room or message ID",
 		})
 	}
 
-	// 채팅방의 last_message 및 last_message_at 업데이트
-	now := time.Now()
-	database.DB.Model(&chatRoom).Updates(map[string]interface{}{
-		"last_message":    req.Message,
-		"last_message_at": now,
-	})
+	message, err := services.DeleteChatMessage(roomID, messageID, middleware.GetUserID(c))
+	if err != nil {
+		return chatServiceError(c, err)
+	}
 
-	// 다른 멤버들의 unread_count 증가
-	database.DB.Model(&models.ChatRoomMember{}).
-		Where("chat_room_id = ? AND user_id != ?", roomID, req.UserID).
-		UpdateColumn("unread_count", database.DB.Raw("unread_count + 1"))
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Message deleted successfully",
+		"data":    message,
+	})
+}
 
-	// 메시지 정보 조회 (사용자 정보 포함)
-	database.DB.Preload("User").First(&message, message.ID)
+// parseMessageParams :id, :msgId 경로 파라미터 파싱
+func parseMessageParams(c *fiber.Ctx) (uint, uint, error) {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	messageID, err := strconv.ParseUint(c.Params("msgId"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(roomID), uint(messageID), nil
+}
 
-	// WebSocket으로 실시간 브로드캐스트
-	if services.GlobalHub != nil {
-		services.GlobalHub.BroadcastMessage(
-			chatRoom.ID,
-			"message",
-			req.UserID,
-			message,
-		)
+// chatServiceError 채팅 서비스 에러를 HTTP 응답으로 변환
+func chatServiceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrChatRoomNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Chat room not found",
+		})
+	case errors.Is(err, services.ErrNotRoomMember):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "User is not a member of this chat room",
+		})
+	case errors.Is(err, services.ErrEmptyMessage):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Message is required",
+		})
+	case errors.Is(err, services.ErrMessageNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Message not found",
+		})
+	case errors.Is(err, services.ErrMessageDeleted):
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"success": false,
+			"error":   "Message has been deleted",
+		})
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Attachment not found in this chat room",
+		})
+	case errors.Is(err, services.ErrAttachmentNotUploader):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Attachment was uploaded by another user",
+		})
+	case errors.Is(err, services.ErrMemberMuted):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, services.ErrNotRoomAdmin),
+		errors.Is(err, services.ErrNotRoomOwner),
+		errors.Is(err, services.ErrCannotModerateTarget),
+		errors.Is(err, services.ErrUserBanned):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, services.ErrTargetNotMember):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Member not found",
+		})
+	case errors.Is(err, services.ErrAlreadyRoomMember):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   "User is already a member",
+		})
+	case errors.Is(err, services.ErrInvalidRole):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "role must be admin or member",
+		})
+	case errors.Is(err, services.ErrDirectRoomMembers):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Direct chat room members cannot be changed",
+		})
+	case errors.Is(err, services.ErrRateLimited):
+		var rateErr *services.RateLimitError
+		if errors.As(err, &rateErr) {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(rateErr.RetryAfter.Seconds()))))
+		}
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"success": false,
+			"error":   "Too many messages, please slow down",
+		})
+	case errors.Is(err, services.ErrMessageTooLong):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Message is too long",
+		})
+	case errors.Is(err, services.ErrRoomArchived):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Chat room is archived",
+		})
+	case errors.Is(err, services.ErrInvalidRoomName):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "name must be 1-50 characters",
+		})
+	case errors.Is(err, services.ErrDirectRoomSettings):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Direct chat room settings cannot be changed",
+		})
+	case errors.Is(err, services.ErrSystemMessageType):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "message_type system cannot be sent by clients",
+		})
+	case errors.Is(err, services.ErrInvalidEmoji):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid emoji",
+		})
+	case errors.Is(err, services.ErrNotMessageAuthor):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Not allowed to modify this message",
+		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"success": true,
-		"message": "Message sent successfully",
-		"data":    message,
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   "Failed to process message",
+		"details": err.Error(),
 	})
 }
 
-// GetMessages 채팅방의 메시지 목록 조회
-// GET /chat/rooms/:id/messages
+// GetMessages 채팅방의 메시지 목록 조회 (메시지 ID 커서 기반)
+// GET /chat/rooms/:id/messages?before_id=&after_id=&around_id=&limit=
 func GetMessages(c *fiber.Ctx) error {
-	roomID := c.Params("id")
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
 
-	// 페이지네이션
-	limit := c.QueryInt("limit", 50)
-	offset := c.QueryInt("offset", 0)
+	query := services.MessageQuery{
+		BeforeID: uint(c.QueryInt("before_id", 0)),
+		AfterID:  uint(c.QueryInt("after_id", 0)),
+		AroundID: uint(c.QueryInt("around_id", 0)),
+		Limit:    c.QueryInt("limit", services.DefaultMessageLimit),
+	}
+
+	// 커서는 하나만 지정 가능
+	cursors := 0
+	for _, id := range []uint{query.BeforeID, query.AfterID, query.AroundID} {
+		if id != 0 {
+			cursors++
+		}
+	}
+	if cursors > 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Only one of before_id, after_id, around_id can be specified",
+		})
+	}
 
 	// 채팅방 존재 확인
 	var chatRoom models.ChatRoom
@@ -292,99 +562,198 @@ func GetMessages(c *fiber.Ctx) error {
 		})
 	}
 
-	// 메시지 조회
-	var messages []models.ChatMessage
-	if err := database.DB.
-		Preload("User").
-		Where("chat_room_id = ?", roomID).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&messages).Error; err != nil {
+	// 사용자가 채팅방 멤버인지 확인
+	if _, err := findMembership(roomID, middleware.GetUserID(c)); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "User is not a member of this chat room",
+		})
+	}
+
+	page, err := services.ListChatMessages(chatRoom.ID, middleware.GetUserID(c), query)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
 			"error":   "Failed to fetch messages",
 		})
 	}
 
-	// 전체 메시지 수
-	var total int64
-	database.DB.Model(&models.ChatMessage{}).Where("chat_room_id = ?", roomID).Count(&total)
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    page,
+	})
+}
+
+// SyncMessages 재연결 시 마지막으로 받은 메시지 이후의 메시지를 모든 채팅방에서 조회
+// GET /chat/sync?since_id=&limit=
+func SyncMessages(c *fiber.Ctx) error {
+	sinceID := c.QueryInt("since_id", -1)
+	if sinceID < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "since_id is required",
+		})
+	}
+
+	result, err := services.SyncChatMessages(middleware.GetUserID(c), uint(sinceID), c.QueryInt("limit", services.MaxSyncLimit))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to sync messages",
+		})
+	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data": fiber.Map{
-			"messages": messages,
-			"total":    total,
-			"limit":    limit,
-			"offset":   offset,
-		},
+		"data":    result,
 	})
 }
 
-// MarkAsRead 메시지 읽음 처리
+// SearchMessages 사용자가 속한 채팅방의 메시지 검색
+// GET /chat/search?q=&room_id=&user_id=&from=&to=&before_id=&limit=
+func SearchMessages(c *fiber.Ctx) error {
+	query := services.SearchQuery{
+		Query:    c.Query("q"),
+		RoomID:   uint(c.QueryInt("room_id", 0)),
+		AuthorID: uint(c.QueryInt("user_id", 0)),
+		BeforeID: uint(c.QueryInt("before_id", 0)),
+		Limit:    c.QueryInt("limit", services.DefaultSearchLimit),
+	}
+
+	// 날짜 필터 (RFC3339 또는 YYYY-MM-DD)
+	for param, target := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := parseSearchTime(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid " + param + " (use RFC3339 or YYYY-MM-DD)",
+			})
+		}
+		*target = &t
+	}
+
+	result, err := services.SearchChatMessages(middleware.GetUserID(c), query)
+	if err != nil {
+		if errors.Is(err, services.ErrEmptySearchQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "q is required",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to search messages",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// parseSearchTime RFC3339 또는 YYYY-MM-DD 형식 파싱
+func parseSearchTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// MarkAsReadRequest 읽음 처리 요청
+type MarkAsReadRequest struct {
+	MessageID uint `json:"message_id"` // 마지막으로 읽은 메시지 ID (생략 시 최신 메시지)
+}
+
+// MarkAsRead 메시지 읽음 처리 (읽음 커서 이동)
 // POST /chat/rooms/:id/read
 func MarkAsRead(c *fiber.Ctx) error {
-	roomID := c.Params("id")
+	userID := middleware.GetUserID(c)
 
-	type ReadRequest struct {
-		UserID uint `json:"user_id" validate:"required"`
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
 	}
 
-	var req ReadRequest
-	if err := c.BodyParser(&req); err != nil {
+	// 본문은 선택 사항
+	var req MarkAsReadRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid request body",
+			})
+		}
+	}
+
+	membership, err := services.MarkChatRead(uint(roomID), userID, req.MessageID)
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Messages marked as read",
+		"data": fiber.Map{
+			"last_read_message_id": membership.LastReadMessageID,
+			"last_read_at":         membership.LastReadAt,
+		},
+	})
+}
+
+// GetMessageReads 메시지를 읽은 멤버 목록 조회
+// GET /chat/rooms/:id/messages/:msgId/reads
+func GetMessageReads(c *fiber.Ctx) error {
+	roomID, messageID, err := parseMessageParams(c)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "Invalid request body",
+			"error":   "Invalid room or message ID",
 		})
 	}
 
-	// 멤버십 확인 및 업데이트
-	var membership models.ChatRoomMember
-	if err := database.DB.Where("chat_room_id = ? AND user_id = ?", roomID, req.UserID).First(&membership).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+	if _, err := findMembership(roomID, middleware.GetUserID(c)); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"success": false,
-			"error":   "Membership not found",
+			"error":   "User is not a member of this chat room",
 		})
 	}
 
-	// last_read_at 업데이트 및 unread_count 초기화
-	now := time.Now()
-	database.DB.Model(&membership).Updates(map[string]interface{}{
-		"last_read_at":  now,
-		"unread_count": 0,
-	})
-
-	// WebSocket으로 읽음 처리 브로드캐스트
-	roomIDUint, _ := strconv.ParseUint(roomID, 10, 32)
-	if services.GlobalHub != nil {
-		services.GlobalHub.BroadcastMessage(
-			uint(roomIDUint),
-			"read",
-			req.UserID,
-			fiber.Map{
-				"user_id":      req.UserID,
-				"last_read_at": now,
-			},
-		)
+	status, err := services.GetMessageReadStatus(roomID, messageID)
+	if err != nil {
+		return chatServiceError(c, err)
 	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"message": "Messages marked as read",
+		"data":    status,
 	})
 }
 
-// AddMember 채팅방에 멤버 추가
-// POST /chat/rooms/:id/members
-func AddChatRoomMember(c *fiber.Ctx) error {
-	roomID := c.Params("id")
+// ReactionRequest 반응 토글 요청
+type ReactionRequest struct {
+	Emoji string `json:"emoji" validate:"required"`
+}
 
-	type AddMemberRequest struct {
-		UserID uint `json:"user_id" validate:"required"`
+// ToggleReaction 메시지 반응 토글 (같은 이모지를 다시 보내면 취소)
+// POST /chat/rooms/:id/messages/:msgId/reactions
+func ToggleReaction(c *fiber.Ctx) error {
+	roomID, messageID, err := parseMessageParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room or message ID",
+		})
 	}
 
-	var req AddMemberRequest
+	var req ReactionRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
@@ -392,53 +761,74 @@ func AddChatRoomMember(c *fiber.Ctx) error {
 		})
 	}
 
-	// 채팅방 존재 확인
-	var chatRoom models.ChatRoom
-	if err := database.DB.First(&chatRoom, roomID).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+	result, err := services.ToggleReaction(roomID, messageID, middleware.GetUserID(c), req.Emoji)
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetThread 스레드 조회 (첫 메시지와 답장 목록)
+// GET /chat/rooms/:id/messages/:msgId/thread?after_id=&limit=
+func GetThread(c *fiber.Ctx) error {
+	roomID, messageID, err := parseMessageParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "Chat room not found",
+			"error":   "Invalid room or message ID",
 		})
 	}
 
-	// 이미 멤버인지 확인
-	var existingMember models.ChatRoomMember
-	if err := database.DB.Where("chat_room_id = ? AND user_id = ?", roomID, req.UserID).First(&existingMember).Error; err == nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+	userID := middleware.GetUserID(c)
+	if _, err := findMembership(roomID, userID); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"success": false,
-			"error":   "User is already a member",
+			"error":   "User is not a member of this chat room",
 		})
 	}
 
-	// 멤버 추가
-	member := models.ChatRoomMember{
-		ChatRoomID: chatRoom.ID,
-		UserID:     req.UserID,
-		Role:       "member",
-		JoinedAt:   time.Now(),
+	page, err := services.ListThreadMessages(roomID, userID, messageID,
+		uint(c.QueryInt("after_id", 0)), c.QueryInt("limit", services.DefaultMessageLimit))
+	if err != nil {
+		return chatServiceError(c, err)
 	}
 
-	if err := database.DB.Create(&member).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    page,
+	})
+}
+
+// AddMember 채팅방에 멤버 추가 (admin만 가능)
+// POST /chat/rooms/:id/members
+func AddChatRoomMember(c *fiber.Ctx) error {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "Failed to add member",
+			"error":   "Invalid room ID",
 		})
 	}
 
-	// 멤버 수 증가
-	database.DB.Model(&chatRoom).UpdateColumn("member_count", database.DB.Raw("member_count + 1"))
+	type AddMemberRequest struct {
+		UserID uint `json:"user_id" validate:"required"`
+	}
 
-	// 멤버 정보 조회
-	database.DB.Preload("User").First(&member, member.ID)
+	var req AddMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
 
-	// WebSocket으로 멤버 추가 브로드캐스트
-	if services.GlobalHub != nil {
-		services.GlobalHub.BroadcastMessage(
-			chatRoom.ID,
-			"member_join",
-			req.UserID,
-			member,
-		)
+	member, err := services.AddMemberToRoom(uint(roomID), middleware.GetUserID(c), req.UserID)
+	if err != nil {
+		return chatServiceError(c, err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -448,46 +838,27 @@ func AddChatRoomMember(c *fiber.Ctx) error {
 	})
 }
 
-// RemoveMember 채팅방에서 멤버 제거
+// RemoveMember 채팅방에서 멤버 제거 (본인이면 나가기, 다른 멤버면 강퇴)
 // DELETE /chat/rooms/:id/members/:userId
 func RemoveChatRoomMember(c *fiber.Ctx) error {
-	roomID := c.Params("id")
-	userID := c.Params("userId")
-
-	// 멤버십 확인
-	var member models.ChatRoomMember
-	if err := database.DB.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&member).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "Member not found",
+			"error":   "Invalid room ID",
 		})
 	}
 
-	// 멤버 삭제
-	if err := database.DB.Delete(&member).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	targetID, err := strconv.ParseUint(c.Params("userId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "Failed to remove member",
+			"error":   "Invalid user ID",
 		})
 	}
 
-	// 멤버 수 감소
-	var chatRoom models.ChatRoom
-	database.DB.First(&chatRoom, roomID)
-	database.DB.Model(&chatRoom).UpdateColumn("member_count", database.DB.Raw("member_count - 1"))
-
-	// WebSocket으로 멤버 제거 브로드캐스트
-	roomIDUint, _ := strconv.ParseUint(roomID, 10, 32)
-	userIDUint, _ := strconv.ParseUint(userID, 10, 32)
-	if services.GlobalHub != nil {
-		services.GlobalHub.BroadcastMessage(
-			uint(roomIDUint),
-			"member_leave",
-			uint(userIDUint),
-			fiber.Map{
-				"user_id": userIDUint,
-			},
-		)
+	if err := services.RemoveChatMember(uint(roomID), middleware.GetUserID(c), uint(targetID)); err != nil {
+		return chatServiceError(c, err)
 	}
 
 	return c.JSON(fiber.Map{
@@ -495,3 +866,12 @@ func RemoveChatRoomMember(c *fiber.Ctx) error {
 		"message": "Member removed successfully",
 	})
 }
+
+// findMembership 채팅방 멤버십 조회
+func findMembership(roomID interface{}, userID uint) (*models.ChatRoomMember, error) {
+	var membership models.ChatRoomMember
+	if err := database.DB.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&membership).Error; err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
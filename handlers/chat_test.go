@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"ongi-back/services"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestChatServiceErrorRateLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		retryAfter string
+	}{
+		{"rounds partial seconds up", &services.RateLimitError{RetryAfter: 1500 * time.Millisecond}, fiber.StatusTooManyRequests, "2"},
+		{"whole seconds", &services.RateLimitError{RetryAfter: 3 * time.Second}, fiber.StatusTooManyRequests, "3"},
+		{"wrapped rate limit error", fmt.Errorf("send: %w", &services.RateLimitError{RetryAfter: 200 * time.Millisecond}), fiber.StatusTooManyRequests, "1"},
+		{"sentinel without wait", services.ErrRateLimited, fiber.StatusTooManyRequests, ""},
+		{"message too long", services.ErrMessageTooLong, fiber.StatusBadRequest, ""},
+		{"unknown error", errors.New("boom"), fiber.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error { return chatServiceError(c, tt.err) })
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := resp.Header.Get(fiber.HeaderRetryAfter); got != tt.retryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.retryAfter)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"ongi-back/database"
+	"ongi-back/middleware"
 	"ongi-back/models"
+	"ongi-back/services"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -67,7 +70,8 @@ func CreateClub(c *fiber.Ctx) error {
 		MemberCount: 0,
 	}
 
-	err := database.DB.Create(&club).Error
+	// 클럽 채팅방도 함께 생성
+	err := services.CreateClubWithChatRoom(&club, middleware.GetUserID(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create club",
@@ -82,11 +86,11 @@ func CreateClub(c *fiber.Ctx) error {
 
 // 클럽 가입
 type JoinClubRequest struct {
-	UserID uint `json:"user_id"`
 	ClubID uint `json:"club_id"`
 }
 
 func JoinClub(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
 	var req JoinClubRequest
 
 	if err := c.BodyParser(&req); err != nil {
@@ -95,35 +99,61 @@ func JoinClub(c *fiber.Ctx) error {
 		})
 	}
 
-	// 이미 가입했는지 확인
-	var existing models.ClubMember
-	result := database.DB.Where("user_id = ? AND club_id = ?", req.UserID, req.ClubID).First(&existing)
-	if result.Error == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Already a member of this club",
+	// 클럽 가입 (클럽 채팅방 멤버도 함께 추가)
+	member, err := services.JoinClub(req.ClubID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAlreadyClubMember):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Already a member of this club",
+			})
+		case errors.Is(err, services.ErrClubNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Club not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to join club",
 		})
 	}
 
-	member := models.ClubMember{
-		UserID: req.UserID,
-		ClubID: req.ClubID,
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Successfully joined club",
+		"data":    member,
+	})
+}
+
+// 클럽 탈퇴
+type LeaveClubRequest struct {
+	ClubID uint `json:"club_id"`
+}
+
+func LeaveClub(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	var req LeaveClubRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
 	}
 
-	err := database.DB.Create(&member).Error
-	if err != nil {
+	// 클럽 탈퇴 (클럽 채팅방에서도 함께 제거)
+	if err := services.LeaveClub(req.ClubID, userID); err != nil {
+		if errors.Is(err, services.ErrNotClubMember) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Not a member of this club",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to join club",
+			"error": "Failed to leave club",
 		})
 	}
 
-	// 클럽의 멤버 수 증가
-	database.DB.Model(&models.Club{}).Where("id = ?", req.ClubID).
-		Update("member_count", database.DB.Raw("member_count + 1"))
-
 	return c.JSON(fiber.Map{
 		"success": true,
-		"message": "Successfully joined club",
-		"data":    member,
+		"message": "Successfully left club",
 	})
 }
 
@@ -2,9 +2,9 @@ package handlers
 
 import (
 	"ongi-back/database"
+	"ongi-back/middleware"
 	"ongi-back/models"
 	"ongi-back/services"
-	"ongi-back/utils"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -82,35 +82,13 @@ func GetGuestResult(c *fiber.Ctx) error {
 		return returnGuestResult(c, sessionID, session)
 	}
 
-	// 점수 계산
-	scores, err := services.CalculateGuestScores(sessionID)
-	if err != nil {
+	// 점수 계산, 프로필 타입/설명 생성 및 세션 결과/벡터 저장
+	if _, err := services.AnalyzeProfile(services.GuestSubject(sessionID)); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to calculate scores: " + err.Error(),
 		})
 	}
 
-	// 프로필 타입 및 설명 생성
-	profileType := services.DetermineProfileType(scores)
-	descriptions := services.GenerateDescriptions(scores)
-	summary := strings.Join(descriptions, " ")
-
-	// 결과 저장
-	err = services.SaveGuestResult(sessionID, scores, profileType, summary)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to save result",
-		})
-	}
-
-	// 벡터 생성
-	err = services.CreateSessionVector(sessionID, nil, scores)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create session vector",
-		})
-	}
-
 	// 세션 다시 조회
 	session, _ = services.GetGuestSession(sessionID)
 	return returnGuestResult(c, sessionID, session)
@@ -131,10 +109,10 @@ func returnGuestResult(c *fiber.Ctx, sessionID string, session *models.GuestSess
 	}
 
 	// 추천 데이터 가져오기
-	recommendedClubs, _ := services.GetRecommendedClubsForSession(sessionID, 5)
-	similarClubs, _ := services.GetClubsWithSimilarMembersForSession(sessionID, 5)
-	recommendedMeetings, _ := services.GetRecommendedMeetingsForSession(sessionID, 5)
-	similarProfiles, _ := services.GetSimilarProfilesFast(sessionID, 5)
+	subject := services.GuestSubject(sessionID)
+	recommendedClubs, _ := services.RecommendClubs(subject, recommendOptions(c), 5)
+	recommendedMeetings, _ := services.GetRecommendedMeetings(subject, 5)
+	similarProfiles, _ := services.GetSimilarProfiles(subject, 5)
 
 	result := fiber.Map{
 		"session_id":  sessionID,
@@ -143,9 +121,8 @@ func returnGuestResult(c *fiber.Ctx, sessionID string, session *models.GuestSess
 		"profile_type": session.ProfileType,
 		"descriptions": descriptions,
 		"recommendations": fiber.Map{
-			"clubs":           recommendedClubs,
-			"similar_clubs":   similarClubs,
-			"meetings":        recommendedMeetings,
+			"clubs":            recommendedClubs,
+			"meetings":         recommendedMeetings,
 			"similar_profiles": similarProfiles,
 		},
 		"expires_at": session.ExpiresAt,
@@ -159,9 +136,9 @@ func returnGuestResult(c *fiber.Ctx, sessionID string, session *models.GuestSess
 
 // LinkSessionToAccount - 세션을 계정과 연동
 func LinkSessionToAccount(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
 	var req struct {
 		SessionID string `json:"session_id"`
-		UserID    uint   `json:"user_id"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -187,7 +164,7 @@ func LinkSessionToAccount(c *fiber.Ctx) error {
 
 	// 사용자 확인
 	var user models.User
-	err = database.DB.First(&user, req.UserID).Error
+	err = database.DB.First(&user, userID).Error
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "User not found",
@@ -195,7 +172,7 @@ func LinkSessionToAccount(c *fiber.Ctx) error {
 	}
 
 	// 연동 처리
-	err = services.LinkSessionToUser(req.SessionID, req.UserID)
+	err = services.LinkSessionToUser(req.SessionID, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to link session: " + err.Error(),
@@ -206,7 +183,7 @@ func LinkSessionToAccount(c *fiber.Ctx) error {
 		"success": true,
 		"message": "Session successfully linked to user account",
 		"data": fiber.Map{
-			"user_id":    req.UserID,
+			"user_id":    userID,
 			"session_id": req.SessionID,
 		},
 	})
@@ -251,9 +228,8 @@ func GetCompatibility(c *fiber.Ctx) error {
 	}
 
 	// 두 세션의 벡터 가져오기
-	var vector1, vector2 models.SessionVector
-	err1 := database.DB.Where("session_id = ?", req.SessionID1).First(&vector1).Error
-	err2 := database.DB.Where("session_id = ?", req.SessionID2).First(&vector2).Error
+	v1, err1 := services.GuestSubject(req.SessionID1).Vector()
+	v2, err2 := services.GuestSubject(req.SessionID2).Vector()
 
 	if err1 != nil || err2 != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -261,16 +237,6 @@ func GetCompatibility(c *fiber.Ctx) error {
 		})
 	}
 
-	// 벡터로 변환
-	v1 := utils.FromSlice(vector1.Vector)
-	v2 := utils.FromSlice(vector2.Vector)
-
-	if v1 == nil || v2 == nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Invalid vector data",
-		})
-	}
-
 	// 궁합 계산
 	compatibility := services.CalculateProfileCompatibility(v1, v2)
 
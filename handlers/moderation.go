@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"ongi-back/middleware"
+	"ongi-back/services"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ChangeRoleRequest 역할 변경 요청
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required"` // admin, member
+}
+
+// MuteMemberRequest 음소거 요청
+type MuteMemberRequest struct {
+	DurationMinutes int `json:"duration_minutes" validate:"required"` // 음소거 시간 (분)
+}
+
+// BanMemberRequest 차단 요청
+type BanMemberRequest struct {
+	UserID          uint   `json:"user_id" validate:"required"`
+	Reason          string `json:"reason"`
+	DurationMinutes int    `json:"duration_minutes"` // 0이면 영구 차단
+}
+
+// TransferOwnershipRequest 방장 위임 요청
+type TransferOwnershipRequest struct {
+	UserID uint `json:"user_id" validate:"required"`
+}
+
+// ChangeMemberRole 멤버 역할 변경 (방장만 가능)
+// PATCH /chat/rooms/:id/members/:userId/role
+func ChangeMemberRole(c *fiber.Ctx) error {
+	roomID, targetID, err := parseMemberParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room or user ID",
+		})
+	}
+
+	var req ChangeRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	member, err := services.ChangeMemberRole(roomID, middleware.GetUserID(c), targetID, req.Role)
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Role updated successfully",
+		"data":    member,
+	})
+}
+
+// TransferOwnership 방장 위임
+// POST /chat/rooms/:id/transfer
+func TransferOwnership(c *fiber.Ctx) error {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	var req TransferOwnershipRequest
+	if err := c.BodyParser(&req); err != nil || req.UserID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "user_id is required",
+		})
+	}
+
+	if err := services.TransferOwnership(uint(roomID), middleware.GetUserID(c), req.UserID); err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Ownership transferred successfully",
+	})
+}
+
+// MuteMember 멤버 음소거
+// POST /chat/rooms/:id/members/:userId/mute
+func MuteMember(c *fiber.Ctx) error {
+	roomID, targetID, err := parseMemberParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room or user ID",
+		})
+	}
+
+	var req MuteMemberRequest
+	if err := c.BodyParser(&req); err != nil || req.DurationMinutes <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "duration_minutes must be positive",
+		})
+	}
+
+	member, err := services.MuteMember(roomID, middleware.GetUserID(c), targetID, time.Duration(req.DurationMinutes)*time.Minute)
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Member muted successfully",
+		"data":    member,
+	})
+}
+
+// UnmuteMember 음소거 해제
+// DELETE /chat/rooms/:id/members/:userId/mute
+func UnmuteMember(c *fiber.Ctx) error {
+	roomID, targetID, err := parseMemberParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room or user ID",
+		})
+	}
+
+	member, err := services.MuteMember(roomID, middleware.GetUserID(c), targetID, 0)
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Member unmuted successfully",
+		"data":    member,
+	})
+}
+
+// GetBans 차단 목록 조회 (admin)
+// GET /chat/rooms/:id/bans
+func GetBans(c *fiber.Ctx) error {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	bans, err := services.ListBans(uint(roomID), middleware.GetUserID(c))
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    bans,
+	})
+}
+
+// BanMember 사용자 차단 (멤버인 경우 강퇴)
+// POST /chat/rooms/:id/bans
+func BanMember(c *fiber.Ctx) error {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	var req BanMemberRequest
+	if err := c.BodyParser(&req); err != nil || req.UserID == 0 || req.DurationMinutes < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "user_id is required",
+		})
+	}
+
+	ban, err := services.BanMember(uint(roomID), middleware.GetUserID(c), req.UserID, req.Reason,
+		time.Duration(req.DurationMinutes)*time.Minute)
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "User banned successfully",
+		"data":    ban,
+	})
+}
+
+// UnbanMember 차단 해제
+// DELETE /chat/rooms/:id/bans/:userId
+func UnbanMember(c *fiber.Ctx) error {
+	roomID, targetID, err := parseMemberParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room or user ID",
+		})
+	}
+
+	if err := services.UnbanMember(roomID, middleware.GetUserID(c), targetID); err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "User unbanned successfully",
+	})
+}
+
+// GetModerationLogs 채팅방 관리 기록 조회 (admin)
+// GET /chat/rooms/:id/moderation-logs
+func GetModerationLogs(c *fiber.Ctx) error {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	logs, err := services.ListModerationLogs(uint(roomID), middleware.GetUserID(c), c.QueryInt("limit", services.DefaultMessageLimit))
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    logs,
+	})
+}
+
+// parseMemberParams :id, :userId 경로 파라미터 파싱
+func parseMemberParams(c *fiber.Ctx) (uint, uint, error) {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	userID, err := strconv.ParseUint(c.Params("userId"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(roomID), uint(userID), nil
+}
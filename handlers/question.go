@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"ongi-back/database"
+	"ongi-back/middleware"
 	"ongi-back/models"
 
 	"github.com/gofiber/fiber/v2"
@@ -44,12 +45,12 @@ func GetQuestion(c *fiber.Ctx) error {
 
 // 사용자 답변 제출
 type SubmitAnswerRequest struct {
-	UserID     uint `json:"user_id"`
 	QuestionID uint `json:"question_id"`
 	OptionID   uint `json:"option_id"`
 }
 
 func SubmitAnswer(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
 	var req SubmitAnswerRequest
 
 	if err := c.BodyParser(&req); err != nil {
@@ -60,7 +61,7 @@ func SubmitAnswer(c *fiber.Ctx) error {
 
 	// 답변 저장
 	answer := models.UserAnswer{
-		UserID:     req.UserID,
+		UserID:     userID,
 		QuestionID: req.QuestionID,
 		OptionID:   req.OptionID,
 	}
@@ -81,7 +82,6 @@ func SubmitAnswer(c *fiber.Ctx) error {
 
 // 여러 답변 한번에 제출
 type SubmitAnswersRequest struct {
-	UserID  uint              `json:"user_id"`
 	Answers []AnswerSubmission `json:"answers"`
 }
 
@@ -91,6 +91,7 @@ type AnswerSubmission struct {
 }
 
 func SubmitAnswers(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
 	var req SubmitAnswersRequest
 
 	if err := c.BodyParser(&req); err != nil {
@@ -100,12 +101,12 @@ func SubmitAnswers(c *fiber.Ctx) error {
 	}
 
 	// 기존 답변 삭제 (재시험 가능하도록)
-	database.DB.Where("user_id = ?", req.UserID).Delete(&models.UserAnswer{})
+	database.DB.Where("user_id = ?", userID).Delete(&models.UserAnswer{})
 
 	// 새 답변들 저장
 	for _, ans := range req.Answers {
 		answer := models.UserAnswer{
-			UserID:     req.UserID,
+			UserID:     userID,
 			QuestionID: ans.QuestionID,
 			OptionID:   ans.OptionID,
 		}
@@ -5,7 +5,6 @@ import (
 	"ongi-back/models"
 	"ongi-back/services"
 	"strconv"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,57 +18,28 @@ func GetAnalysisResult(c *fiber.Ctx) error {
 		})
 	}
 
-	// 점수 계산
-	scores, err := services.CalculateScores(uint(userID))
+	// 점수 계산, 프로필 타입/설명 생성 및 UserProfile 저장
+	subject := services.MemberSubject(uint(userID))
+	result, err := services.AnalyzeProfile(subject)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to calculate scores: " + err.Error(),
 		})
 	}
 
-	// 프로필 타입 및 설명 생성
-	profileType := services.DetermineProfileType(scores)
-	descriptions := services.GenerateDescriptions(scores)
-
-	// UserProfile 저장 또는 업데이트
-	profile := models.UserProfile{
-		UserID:           uint(userID),
-		SocialityScore:   scores.SocialityScore,
-		ActivityScore:    scores.ActivityScore,
-		IntimacyScore:    scores.IntimacyScore,
-		ImmersionScore:   scores.ImmersionScore,
-		FlexibilityScore: scores.FlexibilityScore,
-		ProfileType:      profileType,
-		ResultSummary:    strings.Join(descriptions, " "),
-	}
-
-	// upsert (존재하면 업데이트, 없으면 생성)
-	var existingProfile models.UserProfile
-	result := database.DB.Where("user_id = ?", userID).First(&existingProfile)
-
-	if result.Error == nil {
-		// 업데이트
-		database.DB.Model(&existingProfile).Updates(profile)
-	} else {
-		// 생성
-		database.DB.Create(&profile)
-	}
-
 	// 추천 데이터 가져오기
-	recommendedClubs, _ := services.GetRecommendedClubs(uint(userID), 5)
-	similarClubs, _ := services.GetClubsWithSimilarMembers(uint(userID), 5)
-	recommendedMeetings, _ := services.GetRecommendedMeetings(uint(userID), 5)
+	recommendedClubs, _ := services.RecommendClubs(subject, recommendOptions(c), 5)
+	recommendedMeetings, _ := services.GetRecommendedMeetings(subject, 5)
 	similarUsers, _ := services.GetSimilarUsers(uint(userID), 5)
 
 	analysisResult := fiber.Map{
-		"scores":       scores,
-		"profile_type": profileType,
-		"descriptions": descriptions,
+		"scores":       result.Scores,
+		"profile_type": result.ProfileType,
+		"descriptions": result.Descriptions,
 		"recommendations": fiber.Map{
-			"clubs":          recommendedClubs,
-			"similar_clubs":  similarClubs,
-			"meetings":       recommendedMeetings,
-			"similar_users":  similarUsers,
+			"clubs":         recommendedClubs,
+			"meetings":      recommendedMeetings,
+			"similar_users": similarUsers,
 		},
 	}
 
@@ -99,3 +69,11 @@ func GetUserAnswers(c *fiber.Ctx) error {
 		"data":    answers,
 	})
 }
+
+// recommendOptions 추천 필터 쿼리 파라미터 (?location=강남&category=운동)
+func recommendOptions(c *fiber.Ctx) services.RecommendOptions {
+	return services.RecommendOptions{
+		Location: c.Query("location"),
+		Category: c.Query("category"),
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"ongi-back/middleware"
+	"ongi-back/services"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateChatRoomRequest 채팅방 설정 변경 요청 (보낸 항목만 변경)
+type UpdateChatRoomRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	AvatarURL   *string `json:"avatar_url"` // 빈 문자열이면 제거
+	Archived    *bool   `json:"archived"`
+}
+
+// UpdateRoomSettingsRequest 본인의 채팅방 설정 변경 요청
+type UpdateRoomSettingsRequest struct {
+	Pinned             *bool `json:"pinned"`
+	NotificationsMuted *bool `json:"notifications_muted"`
+}
+
+// UpdateChatRoom 채팅방 이름/설명/대표 이미지/보관 상태 변경 (admin만 가능)
+// PATCH /chat/rooms/:id
+func UpdateChatRoom(c *fiber.Ctx) error {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	var req UpdateChatRoomRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	room, err := services.UpdateChatRoom(uint(roomID), middleware.GetUserID(c), services.UpdateRoomInput{
+		Name:        req.Name,
+		Description: req.Description,
+		AvatarURL:   req.AvatarURL,
+		Archived:    req.Archived,
+	})
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Chat room updated successfully",
+		"data":    room,
+	})
+}
+
+// UpdateRoomSettings 본인의 채팅방 고정/알림 끄기 설정
+// PATCH /chat/rooms/:id/settings
+func UpdateRoomSettings(c *fiber.Ctx) error {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	var req UpdateRoomSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	member, err := services.UpdateMemberSettings(uint(roomID), middleware.GetUserID(c), services.MemberSettingsInput{
+		Pinned:             req.Pinned,
+		NotificationsMuted: req.NotificationsMuted,
+	})
+	if err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    member,
+	})
+}
+
+// LeaveChatRoom 채팅방 나가기
+// POST /chat/rooms/:id/leave
+func LeaveChatRoom(c *fiber.Ctx) error {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	if err := services.LeaveChatRoom(uint(roomID), middleware.GetUserID(c)); err != nil {
+		return chatServiceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Left chat room successfully",
+	})
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"ongi-back/database"
+	"ongi-back/middleware"
 	"ongi-back/models"
 	"ongi-back/services"
 	"time"
@@ -96,7 +98,6 @@ func analyzeTendency(score float64) string {
 // CreateOrUpdateUserProfile 사용자 프로필 생성/수정
 // POST /users/profile
 type CreateUserProfileRequest struct {
-	UserID           uint    `json:"user_id" validate:"required"`
 	SocialityScore   float64 `json:"sociality_score"`
 	ActivityScore    float64 `json:"activity_score"`
 	IntimacyScore    float64 `json:"intimacy_score"`
@@ -107,6 +108,7 @@ type CreateUserProfileRequest struct {
 }
 
 func CreateOrUpdateUserProfile(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
 	var req CreateUserProfileRequest
 
 	if err := c.BodyParser(&req); err != nil {
@@ -118,7 +120,7 @@ func CreateOrUpdateUserProfile(c *fiber.Ctx) error {
 
 	// 사용자 존재 확인
 	var user models.User
-	if err := database.DB.First(&user, req.UserID).Error; err != nil {
+	if err := database.DB.First(&user, userID).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
 			"error":   "User not found",
@@ -127,12 +129,12 @@ func CreateOrUpdateUserProfile(c *fiber.Ctx) error {
 
 	// 기존 프로필 확인
 	var profile models.UserProfile
-	result := database.DB.Where("user_id = ?", req.UserID).First(&profile)
+	result := database.DB.Where("user_id = ?", userID).First(&profile)
 
 	if result.Error != nil {
 		// 신규 프로필 생성
 		profile = models.UserProfile{
-			UserID:           req.UserID,
+			UserID:           userID,
 			SocialityScore:   req.SocialityScore,
 			ActivityScore:    req.ActivityScore,
 			IntimacyScore:    req.IntimacyScore,
@@ -148,6 +150,7 @@ func CreateOrUpdateUserProfile(c *fiber.Ctx) error {
 				"error":   "Failed to create profile",
 			})
 		}
+		services.IndexMemberProfile(&profile)
 
 		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 			"success": true,
@@ -171,6 +174,7 @@ func CreateOrUpdateUserProfile(c *fiber.Ctx) error {
 			"error":   "Failed to update profile",
 		})
 	}
+	services.IndexMemberProfile(&profile)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -203,16 +207,11 @@ func GetUserProfile(c *fiber.Ctx) error {
 	// 유사 사용자 추천 (70% 이상 유사도)
 	var uid uint
 	if _, err := fmt.Sscanf(userID, "%d", &uid); err == nil {
+		subject := services.MemberSubject(uid)
 		similarUsers, _ := services.GetSimilarUsers(uid, 20) // 상위 20명
 
-		// 클럽 추천 (유사한 멤버들이 있는 클럽 우선)
-		recommendedClubs, _ := services.GetClubsWithSimilarMembers(uid, 10)
-
-		// 만약 유사 멤버 기반 클럽이 부족하면 성향 기반 클럽 추가
-		if len(recommendedClubs) < 5 {
-			additionalClubs, _ := services.GetRecommendedClubs(uid, 10)
-			recommendedClubs = append(recommendedClubs, additionalClubs...)
-		}
+		// 클럽 추천 (성향 벡터 + 유사 멤버 + 인기도 합산)
+		recommendedClubs, _ := services.RecommendClubs(subject, recommendOptions(c), 10)
 
 		return c.JSON(fiber.Map{
 			"success": true,
@@ -254,13 +253,9 @@ func AutoMatchClubs(c *fiber.Ctx) error {
 		})
 	}
 
-	// 추천 클럽 가져오기 (유사 멤버 기반 + 성향 기반)
-	recommendedClubs, err := services.GetClubsWithSimilarMembers(uid, 20)
-	if err != nil || len(recommendedClubs) < 5 {
-		// 추가 클럽 가져오기
-		additionalClubs, _ := services.GetRecommendedClubs(uid, 20)
-		recommendedClubs = append(recommendedClubs, additionalClubs...)
-	}
+	// 추천 클럽 가져오기 (유사 멤버 기반 + 성향 기반, 중복 없음)
+	recommendations, _ := services.RecommendClubs(services.MemberSubject(uid), services.RecommendOptions{}, 20)
+	recommendedClubs := services.ClubsFromRecommendations(recommendations)
 
 	if len(recommendedClubs) == 0 {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -288,30 +283,15 @@ func AutoMatchClubs(c *fiber.Ctx) error {
 	for i := 0; i < numClubsToJoin && i < len(recommendedClubs); i++ {
 		club := recommendedClubs[i]
 
-		// 이미 가입된 클럽인지 확인
-		var existingMember models.ClubMember
-		checkErr := database.DB.Where("club_id = ? AND user_id = ?", club.ID, uid).First(&existingMember).Error
-
-		if checkErr == nil {
-			// 이미 가입된 경우
-			alreadyMember = append(alreadyMember, club)
-			continue
-		}
-
-		// 클럽 가입
-		newMember := models.ClubMember{
-			ClubID:   club.ID,
-			UserID:   uid,
-			JoinedAt: time.Now(),
-		}
-
-		if err := database.DB.Create(&newMember).Error; err != nil {
+		// 클럽 가입 (클럽 채팅방 멤버도 함께 추가)
+		if _, err := services.JoinClub(club.ID, uid); err != nil {
+			if errors.Is(err, services.ErrAlreadyClubMember) {
+				// 이미 가입된 경우
+				alreadyMember = append(alreadyMember, club)
+			}
 			continue // 에러 발생시 다음 클럽으로
 		}
 
-		// 멤버 수 증가
-		database.DB.Model(&models.Club{}).Where("id = ?", club.ID).Update("member_count", club.MemberCount+1)
-
 		joinedClubs = append(joinedClubs, club)
 	}
 
@@ -361,7 +341,7 @@ func AutoMatchWithSimilarUsers(c *fiber.Ctx) error {
 	}
 
 	// 유사한 사용자 찾기 (유사도 70% 이상, 최대 20명)
-	similarUsers, err := services.GetSimilarUsers(uid, 20)
+	similarUsers, err := services.GetSimilarProfiles(services.MemberSubject(uid), 20)
 	if err != nil || len(similarUsers) == 0 {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "No similar users found for group matching",
@@ -383,15 +363,12 @@ func AutoMatchWithSimilarUsers(c *fiber.Ctx) error {
 	selectedUsers := make([]uint, groupSize+1)
 	selectedUsers[0] = uid // 본인 포함
 	for i := 0; i < groupSize; i++ {
-		selectedUsers[i+1] = similarUsers[i].User.ID
+		selectedUsers[i+1] = *similarUsers[i].UserID
 	}
 
 	// 그룹이 함께 들어갈 추천 클럽 찾기
-	recommendedClubs, err := services.GetClubsWithSimilarMembers(uid, 20)
-	if err != nil || len(recommendedClubs) < 5 {
-		additionalClubs, _ := services.GetRecommendedClubs(uid, 20)
-		recommendedClubs = append(recommendedClubs, additionalClubs...)
-	}
+	recommendations, _ := services.RecommendClubs(services.MemberSubject(uid), services.RecommendOptions{}, 20)
+	recommendedClubs := services.ClubsFromRecommendations(recommendations)
 
 	if len(recommendedClubs) == 0 {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -425,23 +402,8 @@ func AutoMatchWithSimilarUsers(c *fiber.Ctx) error {
 
 		// 그룹의 각 사용자를 클럽에 가입시키기
 		for _, userID := range selectedUsers {
-			// 이미 가입된 클럽인지 확인
-			var existingMember models.ClubMember
-			checkErr := database.DB.Where("club_id = ? AND user_id = ?", club.ID, userID).First(&existingMember).Error
-
-			if checkErr == nil {
-				skippedUsers = append(skippedUsers, userID)
-				continue
-			}
-
-			// 클럽 가입
-			newMember := models.ClubMember{
-				ClubID:   club.ID,
-				UserID:   userID,
-				JoinedAt: time.Now(),
-			}
-
-			if err := database.DB.Create(&newMember).Error; err != nil {
+			// 클럽 가입 (이미 가입된 경우 건너뜀, 클럽 채팅방 멤버도 함께 추가)
+			if _, err := services.JoinClub(club.ID, userID); err != nil {
 				skippedUsers = append(skippedUsers, userID)
 				continue
 			}
@@ -449,11 +411,7 @@ func AutoMatchWithSimilarUsers(c *fiber.Ctx) error {
 			joinedUsers = append(joinedUsers, userID)
 		}
 
-		// 멤버 수 업데이트
 		if len(joinedUsers) > 0 {
-			database.DB.Model(&models.Club{}).Where("id = ?", club.ID).
-				UpdateColumn("member_count", club.MemberCount+len(joinedUsers))
-
 			results = append(results, JoinResult{
 				Club:         club,
 				JoinedUsers:  joinedUsers,
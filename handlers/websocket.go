@@ -2,49 +2,142 @@ package handlers
 
 import (
 	"log"
-	"ongi-back/database"
-	"ongi-back/models"
+	"ongi-back/middleware"
 	"ongi-back/services"
+	"ongi-back/utils"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
 )
 
+// WebSocketAuthProtocol JWT 전달용 Sec-WebSocket-Protocol 이름
+// 클라이언트는 ["access_token", "<JWT>"] 형태로 서브프로토콜을 전달
+const WebSocketAuthProtocol = "access_token"
+
+// Locals 키
+const localsRoomID = "room_id"
+
 // WebSocketHandler WebSocket 연결 핸들러
+// 업그레이드 전에 JWT(서브프로토콜) 또는 티켓(?ticket=)을 검증하고 멤버십을 확인
 func WebSocketHandler(c *fiber.Ctx) error {
-	// WebSocket 업그레이드
-	if websocket.IsWebSocketUpgrade(c) {
-		c.Locals("allowed", true)
-		return c.Next()
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+
+	roomID, err := strconv.ParseUint(c.Params("roomId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	userID, ok := authenticateWebSocket(c, uint(roomID))
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid or missing websocket credentials",
+		})
 	}
-	return fiber.ErrUpgradeRequired
+
+	// 채팅방 멤버 확인
+	if _, err := findMembership(roomID, userID); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "User is not a member of this chat room",
+		})
+	}
+
+	c.Locals("allowed", true)
+	c.Locals(middleware.LocalsUserID, userID)
+	c.Locals(localsRoomID, uint(roomID))
+	return c.Next()
 }
 
-// HandleWebSocket WebSocket 연결 처리
-func HandleWebSocket(c *websocket.Conn) {
-	// URL 파라미터에서 roomID와 userID 가져오기
-	roomIDStr := c.Params("roomId")
-	userIDStr := c.Query("user_id")
+// authenticateWebSocket 티켓 또는 서브프로토콜 JWT로 사용자 인증
+func authenticateWebSocket(c *fiber.Ctx, roomID uint) (uint, bool) {
+	// 1. 단기 티켓 (POST /chat/rooms/:id/ws-ticket 으로 발급)
+	if ticket := c.Query("ticket"); ticket != "" {
+		claims, err := utils.ValidateWSTicket(ticket)
+		if err != nil || claims.RoomID != roomID {
+			return 0, false
+		}
+		if !services.ConsumeWSTicket(claims.ID, claims.ExpiresAt.Time) {
+			return 0, false
+		}
+		return claims.UserID, true
+	}
+
+	// 2. Sec-WebSocket-Protocol: access_token, <JWT>
+	protocols := strings.Split(c.Get(fiber.HeaderSecWebSocketProtocol), ",")
+	for i := 0; i+1 < len(protocols); i++ {
+		if strings.TrimSpace(protocols[i]) != WebSocketAuthProtocol {
+			continue
+		}
+		claims, err := utils.ValidateJWT(strings.TrimSpace(protocols[i+1]))
+		if err != nil {
+			return 0, false
+		}
+		return claims.UserID, true
+	}
+
+	return 0, false
+}
+
+// IssueWebSocketTicket WebSocket 연결용 단기 티켓 발급
+// POST /chat/rooms/:id/ws-ticket
+func IssueWebSocketTicket(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
 
-	roomID, err := strconv.ParseUint(roomIDStr, 10, 32)
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
-		log.Printf("Invalid room ID: %v", err)
-		c.Close()
-		return
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	if _, err := findMembership(roomID, userID); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "User is not a member of this chat room",
+		})
 	}
 
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	claims, ticket, err := utils.GenerateWSTicket(userID, uint(roomID))
 	if err != nil {
-		log.Printf("Invalid user ID: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to issue websocket ticket",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"ticket":     ticket,
+			"expires_at": claims.ExpiresAt.Time,
+			"expires_in": int64(time.Until(claims.ExpiresAt.Time).Seconds()),
+		},
+	})
+}
+
+// HandleWebSocket WebSocket 연결 처리
+// 인증과 멤버십 확인은 WebSocketHandler에서 업그레이드 전에 완료됨
+func HandleWebSocket(c *websocket.Conn) {
+	userID, ok := c.Locals(middleware.LocalsUserID).(uint)
+	if !ok {
+		log.Printf("WebSocket connection without authenticated user")
 		c.Close()
 		return
 	}
 
-	// 채팅방 멤버 확인
-	var membership models.ChatRoomMember
-	if err := database.DB.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&membership).Error; err != nil {
-		log.Printf("User is not a member of this room: roomID=%d, userID=%d", roomID, userID)
+	roomID, ok := c.Locals(localsRoomID).(uint)
+	if !ok {
+		log.Printf("WebSocket connection without room ID")
 		c.Close()
 		return
 	}
@@ -54,36 +147,66 @@ func HandleWebSocket(c *websocket.Conn) {
 		Hub:    services.GlobalHub,
 		Conn:   c,
 		Send:   make(chan []byte, 256),
-		UserID: uint(userID),
-		RoomID: uint(roomID),
+		UserID: userID,
+		RoomID: roomID,
 	}
 
 	// Hub에 등록
 	client.Hub.Register <- client
 
-	// 입장 메시지 브로드캐스트
-	client.Hub.BroadcastMessage(
-		uint(roomID),
-		"member_online",
-		uint(userID),
-		fiber.Map{
-			"user_id": userID,
-			"status":  "online",
-		},
-	)
-
-	// 고루틴 시작
-	go client.WritePump()
-	client.ReadPump()
-
-	// 퇴장 메시지 브로드캐스트
-	client.Hub.BroadcastMessage(
-		uint(roomID),
-		"member_offline",
-		uint(userID),
-		fiber.Map{
-			"user_id": userID,
-			"status":  "offline",
-		},
-	)
+	// 접속 상태 갱신 (첫 연결인 경우에만 member_online 브로드캐스트)
+	client.Hub.Presence.Connect(roomID, userID)
+
+	// 현재 접속 중인 멤버 목록 전송
+	client.Hub.SendToClient(client, "presence", fiber.Map{
+		"online_user_ids": client.Hub.Presence.OnlineUsers(roomID),
+	})
+
+	// 연결이 끊길 때까지 읽기/쓰기 실행
+	client.Serve()
+
+	// 접속 종료 (마지막 연결이면 잠시 후 member_offline 브로드캐스트)
+	// 입력 중 상태는 마지막 연결이 끊긴 경우에만 정리 (다른 탭의 입력 표시 유지)
+	if client.Hub.Presence.Disconnect(roomID, userID) {
+		client.Hub.Typing.Stop(roomID, userID)
+	}
+}
+
+// GetRoomPresence 채팅방 멤버 접속 상태 조회
+// GET /chat/rooms/:id/presence
+func GetRoomPresence(c *fiber.Ctx) error {
+	roomID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid room ID",
+		})
+	}
+
+	if _, err := findMembership(roomID, middleware.GetUserID(c)); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "User is not a member of this chat room",
+		})
+	}
+
+	if services.GlobalHub == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"error":   "WebSocket hub is not running",
+		})
+	}
+
+	presence, err := services.GlobalHub.Presence.GetRoomPresence(uint(roomID))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to fetch presence",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    presence,
+	})
 }
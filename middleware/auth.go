@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"ongi-back/utils"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Locals 키
+const (
+	LocalsUserID = "user_id"
+	LocalsEmail  = "email"
+)
+
+// RequireAuth Authorization 헤더의 JWT를 검증하고 인증된 사용자를 Locals에 저장
+func RequireAuth(c *fiber.Ctx) error {
+	tokenString := extractBearerToken(c)
+	if tokenString == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Authorization token is required",
+		})
+	}
+
+	claims, err := utils.ValidateJWT(tokenString)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid or expired token",
+		})
+	}
+
+	c.Locals(LocalsUserID, claims.UserID)
+	c.Locals(LocalsEmail, claims.Email)
+
+	return c.Next()
+}
+
+// RequireSelf URL 파라미터의 사용자 ID가 인증된 사용자와 같은지 확인
+// RequireAuth 이후에 사용해야 함
+func RequireSelf(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		targetID, err := strconv.ParseUint(c.Params(param), 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid user ID",
+			})
+		}
+
+		if uint(targetID) != GetUserID(c) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"error":   "You can only access your own resources",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireAdmin 인증된 사용자가 운영자인지 확인
+// 운영자는 ADMIN_USER_IDS 환경 변수 (쉼표로 구분한 사용자 ID 목록)로 지정, RequireAuth 이후에 사용해야 함
+func RequireAdmin(c *fiber.Ctx) error {
+	if !isAdmin(GetUserID(c)) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Admin permission is required",
+		})
+	}
+	return c.Next()
+}
+
+// isAdmin ADMIN_USER_IDS에 포함된 사용자인지 확인
+func isAdmin(userID uint) bool {
+	if userID == 0 {
+		return false
+	}
+	for _, field := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+		if err == nil && uint(id) == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserID 인증된 사용자 ID 조회 (인증되지 않은 경우 0)
+func GetUserID(c *fiber.Ctx) uint {
+	userID, _ := c.Locals(LocalsUserID).(uint)
+	return userID
+}
+
+// GetEmail 인증된 사용자 이메일 조회
+func GetEmail(c *fiber.Ctx) string {
+	email, _ := c.Locals(LocalsEmail).(string)
+	return email
+}
+
+// extractBearerToken "Authorization: Bearer <token>" 헤더에서 토큰 추출
+func extractBearerToken(c *fiber.Ctx) string {
+	authHeader := c.Get(fiber.HeaderAuthorization)
+	if authHeader == "" {
+		return ""
+	}
+
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(parts[1])
+}
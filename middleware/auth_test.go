@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"ongi-back/utils"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testApp 인증이 필요한 테스트용 라우트 (인증된 사용자 ID를 응답)
+func testApp() *fiber.App {
+	app := fiber.New()
+	whoami := func(c *fiber.Ctx) error { return c.SendString(strconv.FormatUint(uint64(GetUserID(c)), 10)) }
+	app.Get("/me", RequireAuth, whoami)
+	app.Get("/users/:id", RequireAuth, RequireSelf("id"), whoami)
+	app.Get("/admin", RequireAuth, RequireAdmin, whoami)
+	app.Get("/public", whoami)
+	return app
+}
+
+// signedToken 지정한 비밀키와 만료 시간으로 서명한 액세스 토큰
+func signedToken(t *testing.T, secret string, userID uint, expiresAt time.Time) string {
+	t.Helper()
+	claims := &utils.Claims{
+		UserID:           userID,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(expiresAt)},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("ADMIN_USER_IDS", "1, 9")
+
+	token := func(userID uint) string {
+		token, err := utils.GenerateJWT(userID, "user@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	ticket := func(userID uint) string {
+		_, ticket, err := utils.GenerateWSTicket(userID, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ticket
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+		body          string
+	}{
+		{"missing header", "/me", "", fiber.StatusUnauthorized, ""},
+		{"not a bearer token", "/me", "Basic " + token(7), fiber.StatusUnauthorized, ""},
+		{"empty bearer token", "/me", "Bearer ", fiber.StatusUnauthorized, ""},
+		{"malformed token", "/me", "Bearer not-a-jwt", fiber.StatusUnauthorized, ""},
+		{"other secret", "/me", "Bearer " + signedToken(t, "other-secret", 7, time.Now().Add(time.Minute)), fiber.StatusUnauthorized, ""},
+		{"expired token", "/me", "Bearer " + signedToken(t, "test-secret", 7, time.Now().Add(-time.Minute)), fiber.StatusUnauthorized, ""},
+		{"websocket ticket as access token", "/me", "Bearer " + ticket(7), fiber.StatusUnauthorized, ""},
+		{"valid token", "/me", "Bearer " + token(7), fiber.StatusOK, "7"},
+		{"scheme is case-insensitive", "/me", "bearer " + token(7), fiber.StatusOK, "7"},
+		{"self", "/users/7", "Bearer " + token(7), fiber.StatusOK, "7"},
+		{"other user", "/users/8", "Bearer " + token(7), fiber.StatusForbidden, ""},
+		{"invalid user id", "/users/abc", "Bearer " + token(7), fiber.StatusBadRequest, ""},
+		{"self without token", "/users/7", "", fiber.StatusUnauthorized, ""},
+		{"admin", "/admin", "Bearer " + token(9), fiber.StatusOK, "9"},
+		{"not admin", "/admin", "Bearer " + token(7), fiber.StatusForbidden, ""},
+		{"admin without token", "/admin", "", fiber.StatusUnauthorized, ""},
+		{"unauthenticated user id is zero", "/public", "", fiber.StatusOK, "0"},
+	}
+
+	app := testApp()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.authorization)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.body != "" {
+				body, _ := io.ReadAll(resp.Body)
+				if got := string(body); got != tt.body {
+					t.Errorf("user id = %q, want %q", got, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	tests := []struct {
+		env    string
+		userID uint
+		want   bool
+	}{
+		{"", 1, false},
+		{"1,2", 2, true},
+		{" 1 , 2 ", 1, true},
+		{"12", 1, false},
+		{"1,x,3", 3, true},
+		{"0", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("ADMIN_USER_IDS", tt.env)
+		if got := isAdmin(tt.userID); got != tt.want {
+			t.Errorf("isAdmin(%d) with ADMIN_USER_IDS=%q = %v, want %v", tt.userID, tt.env, got, tt.want)
+		}
+	}
+}
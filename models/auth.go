@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// RefreshToken 발급된 Refresh Token (원문은 저장하지 않고 해시만 저장)
+type RefreshToken struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	User         User       `json:"-" gorm:"foreignKey:UserID"`
+	TokenHash    string     `json:"-" gorm:"uniqueIndex;not null"`   // SHA-256 해시
+	FamilyID     string     `json:"family_id" gorm:"index;not null"` // 같은 로그인에서 회전된 토큰 묶음
+	ReplacedByID *uint      `json:"replaced_by_id"`                  // 회전으로 대체한 토큰 ID
+	UserAgent    string     `json:"user_agent"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// UsedWSTicket 사용된 WebSocket 티켓 ID (여러 인스턴스에서 재사용 방지, 만료 후 삭제)
+type UsedWSTicket struct {
+	TicketID  string    `json:"ticket_id" gorm:"primaryKey"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
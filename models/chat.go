@@ -7,14 +7,21 @@ type ChatRoom struct {
 	ID          uint             `json:"id" gorm:"primaryKey"`
 	Name        string           `json:"name" gorm:"not null"`               // 채팅방 이름
 	Description string           `json:"description" gorm:"type:text"`       // 채팅방 설명
-	ClubID      *uint            `json:"club_id" gorm:"index"`               // 클럽 채팅방인 경우 (nullable)
+	AvatarURL   *string          `json:"avatar_url"`                         // 채팅방 대표 이미지 URL (nullable)
+	ClubID      *uint            `json:"club_id" gorm:"index;uniqueIndex:idx_chat_rooms_club_room,where:room_type = 'club'"` // 클럽 채팅방인 경우 (클럽당 하나)
 	Club        *Club            `json:"club,omitempty" gorm:"foreignKey:ClubID"`
 	RoomType    string           `json:"room_type" gorm:"default:'group'"`   // group, club, direct
+	DirectKey   *string          `json:"-" gorm:"uniqueIndex"`               // 1:1 채팅방 중복 방지 키 ("작은ID:큰ID")
+	DisplayName string           `json:"display_name,omitempty" gorm:"-"`    // 1:1 채팅방의 상대방 이름 (조회 시 계산)
 	CreatedBy   uint             `json:"created_by" gorm:"not null"`         // 생성자 ID
 	Creator     User             `json:"creator" gorm:"foreignKey:CreatedBy"`
 	MemberCount int              `json:"member_count" gorm:"default:0"`      // 멤버 수
 	LastMessage *string          `json:"last_message"`                       // 마지막 메시지
 	LastMessageAt *time.Time     `json:"last_message_at"`                    // 마지막 메시지 시간
+	UnreadCount int              `json:"unread_count" gorm:"-"`              // 요청한 사용자의 읽지 않은 메시지 수 (조회 시 계산)
+	Pinned      bool             `json:"pinned" gorm:"-"`                    // 요청한 사용자가 고정했는지 (조회 시 계산)
+	NotificationsMuted bool      `json:"notifications_muted" gorm:"-"`       // 요청한 사용자의 알림 끄기 여부 (조회 시 계산)
+	ArchivedAt  *time.Time       `json:"archived_at" gorm:"index"`           // 보관 시간 (보관된 채팅방은 읽기 전용)
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
 	Members     []ChatRoomMember `json:"members,omitempty" gorm:"foreignKey:ChatRoomID"`
@@ -31,21 +38,116 @@ type ChatRoomMember struct {
 	Role          string     `json:"role" gorm:"default:'member'"`          // admin, member
 	JoinedAt      time.Time  `json:"joined_at"`
 	LastReadAt    *time.Time `json:"last_read_at"`                          // 마지막으로 읽은 시간
-	UnreadCount   int        `json:"unread_count" gorm:"default:0"`         // 읽지 않은 메시지 수
+	LastReadMessageID *uint  `json:"last_read_message_id"`                  // 마지막으로 읽은 메시지 ID (읽음 커서)
+	MutedUntil    *time.Time `json:"muted_until"`                           // 이 시간까지 메시지 전송 불가 (nullable)
+	NotificationsMuted bool  `json:"notifications_muted" gorm:"default:false"` // 채팅방 알림 끄기
+	PinnedAt      *time.Time `json:"pinned_at"`                             // 채팅방 목록 상단 고정 시간 (nullable)
 	CreatedAt     time.Time  `json:"created_at"`
 }
 
 // ChatMessage 채팅 메시지
 type ChatMessage struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	ChatRoomID uint      `json:"chat_room_id" gorm:"not null;index"`
+	ID         uint      `json:"id" gorm:"primaryKey;index:idx_chat_messages_room_id,priority:2"`
+	ChatRoomID uint      `json:"chat_room_id" gorm:"not null;index:idx_chat_messages_room_id,priority:1"` // (chat_room_id, id) 커서 페이지네이션용
 	ChatRoom   ChatRoom  `json:"-" gorm:"foreignKey:ChatRoomID"`
-	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	UserID     uint      `json:"user_id" gorm:"not null;index;uniqueIndex:idx_chat_messages_client_message,priority:1"`
 	User       User      `json:"user" gorm:"foreignKey:UserID"`
 	Message    string    `json:"message" gorm:"type:text;not null"`         // 메시지 내용
 	MessageType string   `json:"message_type" gorm:"default:'text'"`        // text, image, file, system
 	FileURL    *string   `json:"file_url"`                                  // 파일/이미지 URL (nullable)
-	IsRead     bool      `json:"is_read" gorm:"default:false"`              // 읽음 여부
+	AttachmentID *uint   `json:"attachment_id"`                             // 업로드된 첨부파일 ID (nullable)
+	Attachment *ChatAttachment `json:"attachment,omitempty" gorm:"foreignKey:AttachmentID"`
+	ClientMessageID *string `json:"client_message_id,omitempty" gorm:"uniqueIndex:idx_chat_messages_client_message,priority:2"` // 클라이언트 생성 멱등성 키
+	Links      []string  `json:"links,omitempty" gorm:"serializer:json;type:text"` // 메시지에서 감지한 링크
+	System     *SystemPayload `json:"system,omitempty" gorm:"serializer:json;type:text"` // 시스템 메시지 구조화 데이터 (message_type이 system인 경우)
+	ReplyToID  *uint     `json:"reply_to_id" gorm:"index"`                  // 답장 대상 스레드의 첫 메시지 ID (nullable)
+	ReplyCount int       `json:"reply_count" gorm:"default:0"`              // 스레드 답장 수
+	Reactions  []ReactionSummary `json:"reactions" gorm:"-"`               // 이모지별 반응 수 (조회 시 계산)
+	UnreadMemberCount int `json:"unread_member_count" gorm:"-"`          // 아직 읽지 않은 멤버 수 (조회 시 계산)
+	EditedAt   *time.Time `json:"edited_at"`                                // 마지막 수정 시간 (nullable)
+	DeletedAt  *time.Time `json:"deleted_at" gorm:"index"`                  // 삭제 시간 (삭제된 메시지는 내용 없이 표시)
+	DeletedBy  *uint      `json:"deleted_by"`                               // 삭제한 사용자 ID
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
+
+// SystemPayload 시스템 메시지 데이터 (클라이언트가 message 대신 이 값으로 문구를 현지화)
+type SystemPayload struct {
+	Event      string            `json:"event"`                 // room_created, member_join, member_leave, member_kicked, member_banned, role_changed, owner_changed, room_renamed
+	ActorID    uint              `json:"actor_id,omitempty"`    // 수행한 사용자
+	ActorName  string            `json:"actor_name,omitempty"`
+	TargetID   uint              `json:"target_id,omitempty"`   // 대상 사용자
+	TargetName string            `json:"target_name,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`      // role, old_name, new_name 등
+}
+
+// ChatMessageEdit 메시지 수정 이력
+type ChatMessageEdit struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	ChatMessageID   uint      `json:"chat_message_id" gorm:"not null;index"`
+	PreviousMessage string    `json:"previous_message" gorm:"type:text"` // 수정 전 내용
+	EditedBy        uint      `json:"edited_by" gorm:"not null"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ChatAttachment 채팅 첨부파일 (이미지/파일)
+type ChatAttachment struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ChatRoomID   uint      `json:"chat_room_id" gorm:"not null;index"`
+	UploaderID   uint      `json:"uploader_id" gorm:"not null;index"`
+	FileName     string    `json:"file_name" gorm:"not null"`          // 원본 파일 이름
+	ContentType  string    `json:"content_type" gorm:"not null"`       // 서버에서 판별한 MIME 타입
+	Size         int64     `json:"size"`                               // bytes
+	Width        int       `json:"width,omitempty"`                    // 이미지인 경우
+	Height       int       `json:"height,omitempty"`
+	StorageKey   string    `json:"-" gorm:"not null"`                  // 저장소 내 경로
+	ThumbnailKey *string   `json:"-"`                                  // 썸네일 경로 (이미지인 경우)
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ChatRoomBan 채팅방 차단 (차단된 사용자는 다시 추가할 수 없음)
+type ChatRoomBan struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ChatRoomID uint       `json:"chat_room_id" gorm:"not null;uniqueIndex:idx_chat_room_bans_room_user,priority:1"`
+	UserID     uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_chat_room_bans_room_user,priority:2"`
+	User       User       `json:"user" gorm:"foreignKey:UserID"`
+	BannedBy   uint       `json:"banned_by" gorm:"not null"`
+	Reason     string     `json:"reason" gorm:"type:text"`
+	ExpiresAt  *time.Time `json:"expires_at"` // nullable: 영구 차단
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ChatModerationLog 채팅방 관리 기록 (역할 변경, 음소거, 강퇴, 차단 등)
+type ChatModerationLog struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ChatRoomID   uint      `json:"chat_room_id" gorm:"not null;index"`
+	ActorID      uint      `json:"actor_id" gorm:"not null"`       // 수행한 사용자
+	TargetUserID uint      `json:"target_user_id" gorm:"not null"` // 대상 사용자
+	Action       string    `json:"action" gorm:"not null"`         // role_change, transfer_ownership, mute, unmute, kick, ban, unban
+	Detail       string    `json:"detail" gorm:"type:text"`        // 변경 내용 (역할, 음소거 종료 시간, 사유 등)
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ChatMessageReaction 메시지 이모지 반응 (사용자당 이모지별 하나)
+type ChatMessageReaction struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ChatMessageID uint      `json:"chat_message_id" gorm:"not null;uniqueIndex:idx_chat_message_reactions_unique,priority:1"`
+	UserID        uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_chat_message_reactions_unique,priority:2"`
+	Emoji         string    `json:"emoji" gorm:"not null;size:32;uniqueIndex:idx_chat_message_reactions_unique,priority:3"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ReactionSummary 이모지별 반응 집계
+type ReactionSummary struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted"` // 요청한 사용자가 반응했는지
+}
+
+// ChatPresence 서버 인스턴스별 채팅방 접속 상태 (인스턴스 간 공유, heartbeat가 끊긴 행은 오프라인으로 간주)
+type ChatPresence struct {
+	ChatRoomID  uint      `json:"chat_room_id" gorm:"primaryKey;autoIncrement:false"`
+	UserID      uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	InstanceID  string    `json:"instance_id" gorm:"primaryKey"`
+	HeartbeatAt time.Time `json:"heartbeat_at" gorm:"index;not null"`
+}
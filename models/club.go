@@ -1,6 +1,12 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"ongi-back/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Club struct {
 	ID               uint         `json:"id" gorm:"primaryKey"`
@@ -15,11 +21,62 @@ type Club struct {
 	MaxMembers       int          `json:"max_members"`      // 최대 멤버 수
 	Tags             string       `json:"tags" gorm:"type:text"` // JSON 배열 형태로 저장
 	PreferredScores  string       `json:"preferred_scores" gorm:"type:text"` // 선호 성향 점수 (JSON)
+	PreferenceVector *utils.Vector5D `json:"-" gorm:"-"` // PreferredScores를 파싱한 벡터 (조회 시 계산, 응답에는 preferred_scores 사용)
 	CreatedAt        time.Time    `json:"created_at"`
 	UpdatedAt        time.Time    `json:"updated_at"`
 	Members          []ClubMember `json:"members" gorm:"foreignKey:ClubID"`
 }
 
+// preferredScores PreferredScores JSON 형식 ({"sociality": 30, ...})
+type preferredScores struct {
+	Sociality   float64 `json:"sociality"`
+	Activity    float64 `json:"activity"`
+	Intimacy    float64 `json:"intimacy"`
+	Immersion   float64 `json:"immersion"`
+	Flexibility float64 `json:"flexibility"`
+}
+
+// AfterFind PreferredScores JSON을 PreferenceVector로 파싱 (값이 없거나 잘못된 경우 nil)
+func (c *Club) AfterFind(tx *gorm.DB) error {
+	c.PreferenceVector = nil
+	if c.PreferredScores == "" {
+		return nil
+	}
+
+	var scores preferredScores
+	if err := json.Unmarshal([]byte(c.PreferredScores), &scores); err == nil {
+		c.PreferenceVector = &utils.Vector5D{
+			Sociality:   scores.Sociality,
+			Activity:    scores.Activity,
+			Intimacy:    scores.Intimacy,
+			Immersion:   scores.Immersion,
+			Flexibility: scores.Flexibility,
+		}
+	}
+	return nil
+}
+
+// BeforeSave PreferenceVector가 있으면 PreferredScores JSON으로 저장
+func (c *Club) BeforeSave(tx *gorm.DB) error {
+	if c.PreferenceVector == nil {
+		return nil
+	}
+
+	v := c.PreferenceVector
+	data, err := json.Marshal(preferredScores{
+		Sociality:   v.Sociality,
+		Activity:    v.Activity,
+		Intimacy:    v.Intimacy,
+		Immersion:   v.Immersion,
+		Flexibility: v.Flexibility,
+	})
+	if err != nil {
+		return err
+	}
+	c.PreferredScores = string(data)
+	return nil
+}
+
 type ClubMember struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	ClubID    uint      `json:"club_id" gorm:"not null"`
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // GuestSession - 비회원 설문 세션
@@ -42,4 +44,5 @@ type SessionVector struct {
 	Magnitude float64   `json:"magnitude"` // 벡터 크기 (미리 계산)
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"` // 소프트 삭제 (다른 인스턴스의 검색 인덱스가 삭제를 반영한 뒤 영구 삭제)
 }
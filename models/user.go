@@ -9,6 +9,7 @@ type User struct {
 	Email     string    `json:"email" gorm:"unique;not null"`
 	Name      string    `json:"name" gorm:"not null"`
 	Password  string    `json:"-" gorm:"default:null"` // 비밀번호 (카카오 로그인 시 null)
+	LastSeenAt *time.Time `json:"last_seen_at"`         // 마지막 채팅 접속 시간
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
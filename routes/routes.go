@@ -2,6 +2,7 @@ package routes
 
 import (
 	"ongi-back/handlers"
+	"ongi-back/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
@@ -16,6 +17,9 @@ func Setup(app *fiber.App) {
 	auth.Post("/login", handlers.Login)                  // 일반 로그인
 	auth.Post("/kakao/login", handlers.KakaoLogin)       // 클라이언트사이드 OAuth
 	auth.Get("/kakao/callback", handlers.KakaoCallback)  // 서버사이드 OAuth 콜백
+	auth.Post("/refresh", handlers.RefreshAccessToken)   // 토큰 재발급 (Refresh Token 회전)
+	auth.Post("/logout", handlers.Logout)                // 로그아웃 (현재 기기)
+	auth.Post("/logout-all", middleware.RequireAuth, handlers.LogoutAll) // 모든 기기 로그아웃
 
 	// Guest/Session routes (비회원 설문)
 	guest := api.Group("/guest")
@@ -23,36 +27,63 @@ func Setup(app *fiber.App) {
 	guest.Post("/answers", handlers.SubmitGuestAnswers)            // 답변 제출
 	guest.Get("/result/:sessionId", handlers.GetGuestResult)       // 결과 조회
 	guest.Get("/session/:sessionId", handlers.GetSessionInfo)      // 세션 정보
-	guest.Post("/link", handlers.LinkSessionToAccount)             // 계정 연동
+	guest.Post("/link", middleware.RequireAuth, handlers.LinkSessionToAccount) // 계정 연동
 	guest.Post("/compatibility", handlers.GetCompatibility)        // 궁합 계산
 
 	// User routes
 	users := api.Group("/users")
 	users.Get("/", handlers.GetUsers)
-	users.Post("/", handlers.CreateUser)
+	users.Post("/", middleware.RequireAuth, middleware.RequireAdmin, handlers.CreateUser) // 운영자 전용
 	users.Get("/:id", handlers.GetUser)
-	users.Post("/profile", handlers.CreateOrUpdateUserProfile)
+	users.Post("/profile", middleware.RequireAuth, handlers.CreateOrUpdateUserProfile)
 	users.Get("/:id/profile", handlers.GetUserProfile)
-	users.Post("/:id/auto-match", handlers.AutoMatchClubs)
-	users.Post("/:id/auto-match-group", handlers.AutoMatchWithSimilarUsers)
+	users.Post("/:id/auto-match", middleware.RequireAuth, middleware.RequireSelf("id"), handlers.AutoMatchClubs)
+	users.Post("/:id/auto-match-group", middleware.RequireAuth, middleware.RequireSelf("id"), handlers.AutoMatchWithSimilarUsers)
 
-	// Matching routes - 전체 사용자 그룹 매칭
-	api.Post("/match-all", handlers.MatchAllUsersToClubs)
+	// Matching routes - 전체 사용자 그룹 매칭 (운영자 전용)
+	api.Post("/match-all", middleware.RequireAuth, middleware.RequireAdmin, handlers.MatchAllUsersToClubs)
 
-	// Chat routes (그룹 채팅)
-	chat := api.Group("/chat")
+	// Chat routes (그룹 채팅) - 모든 요청에 인증 필요
+	chat := api.Group("/chat", middleware.RequireAuth)
 	chat.Post("/rooms", handlers.CreateChatRoom)                         // 채팅방 생성
+	chat.Post("/direct", handlers.CreateDirectChat)                      // 1:1 채팅방 조회/생성
 	chat.Get("/rooms", handlers.GetChatRooms)                            // 채팅방 목록 조회
 	chat.Get("/rooms/:id", handlers.GetChatRoom)                         // 채팅방 상세 조회
+	chat.Patch("/rooms/:id", handlers.UpdateChatRoom)                    // 채팅방 설정 변경 (admin)
+	chat.Patch("/rooms/:id/settings", handlers.UpdateRoomSettings)       // 본인 설정 (고정, 알림 끄기)
+	chat.Post("/rooms/:id/leave", handlers.LeaveChatRoom)                // 채팅방 나가기
 	chat.Post("/rooms/:id/messages", handlers.SendMessage)               // 메시지 전송
 	chat.Get("/rooms/:id/messages", handlers.GetMessages)                // 메시지 목록 조회
+	chat.Patch("/rooms/:id/messages/:msgId", handlers.EditMessage)       // 메시지 수정
+	chat.Delete("/rooms/:id/messages/:msgId", handlers.DeleteMessage)    // 메시지 삭제
+	chat.Get("/rooms/:id/messages/:msgId/reads", handlers.GetMessageReads) // 메시지 읽은 멤버 조회
+	chat.Post("/rooms/:id/messages/:msgId/reactions", handlers.ToggleReaction) // 반응 토글
+	chat.Get("/rooms/:id/messages/:msgId/thread", handlers.GetThread)         // 스레드 조회
 	chat.Post("/rooms/:id/read", handlers.MarkAsRead)                    // 메시지 읽음 처리
 	chat.Post("/rooms/:id/members", handlers.AddChatRoomMember)          // 멤버 추가
-	chat.Delete("/rooms/:id/members/:userId", handlers.RemoveChatRoomMember) // 멤버 제거
+	chat.Delete("/rooms/:id/members/:userId", handlers.RemoveChatRoomMember) // 멤버 제거 (본인이면 나가기)
+	chat.Patch("/rooms/:id/members/:userId/role", handlers.ChangeMemberRole) // 역할 변경 (방장)
+	chat.Post("/rooms/:id/members/:userId/mute", handlers.MuteMember)     // 음소거 (admin)
+	chat.Delete("/rooms/:id/members/:userId/mute", handlers.UnmuteMember) // 음소거 해제 (admin)
+	chat.Post("/rooms/:id/transfer", handlers.TransferOwnership)         // 방장 위임
+	chat.Get("/rooms/:id/bans", handlers.GetBans)                        // 차단 목록 (admin)
+	chat.Post("/rooms/:id/bans", handlers.BanMember)                     // 차단 (admin)
+	chat.Delete("/rooms/:id/bans/:userId", handlers.UnbanMember)         // 차단 해제 (admin)
+	chat.Get("/rooms/:id/moderation-logs", handlers.GetModerationLogs)   // 관리 기록 (admin)
+	chat.Post("/rooms/:id/ws-ticket", handlers.IssueWebSocketTicket)     // WebSocket 연결 티켓 발급
+	chat.Get("/rooms/:id/presence", handlers.GetRoomPresence)            // 멤버 접속 상태 조회
+	chat.Get("/sync", handlers.SyncMessages)                             // 재연결 시 누락 메시지 동기화
+	chat.Get("/search", handlers.SearchMessages)                         // 메시지 검색
+	chat.Post("/rooms/:id/attachments", handlers.UploadAttachment)       // 첨부파일 업로드
+	chat.Get("/attachments/:id/url", handlers.GetAttachmentURL)          // 첨부파일 서명 URL 발급
 
-	// WebSocket route (실시간 채팅)
-	app.Use("/ws", handlers.WebSocketHandler)
-	app.Get("/ws/chat/:roomId", websocket.New(handlers.HandleWebSocket))
+	// 첨부파일 다운로드 (서명 URL로 인증)
+	api.Get("/attachments/:id", handlers.DownloadAttachment)
+
+	// WebSocket route (실시간 채팅) - 업그레이드 전 인증
+	app.Get("/ws/chat/:roomId", handlers.WebSocketHandler, websocket.New(handlers.HandleWebSocket, websocket.Config{
+		Subprotocols: []string{handlers.WebSocketAuthProtocol},
+	}))
 
 	// Question routes
 	questions := api.Group("/questions")
@@ -61,8 +92,8 @@ func Setup(app *fiber.App) {
 
 	// Answer routes
 	answers := api.Group("/answers")
-	answers.Post("/", handlers.SubmitAnswer)
-	answers.Post("/batch", handlers.SubmitAnswers)
+	answers.Post("/", middleware.RequireAuth, handlers.SubmitAnswer)
+	answers.Post("/batch", middleware.RequireAuth, handlers.SubmitAnswers)
 	answers.Get("/user/:userId", handlers.GetUserAnswers)
 
 	// Result routes
@@ -72,14 +103,15 @@ func Setup(app *fiber.App) {
 	// Club routes
 	clubs := api.Group("/clubs")
 	clubs.Get("/", handlers.GetClubs)
-	clubs.Post("/", handlers.CreateClub)
+	clubs.Post("/", middleware.RequireAuth, handlers.CreateClub)
 	clubs.Get("/:id", handlers.GetClub)
-	clubs.Post("/join", handlers.JoinClub)
+	clubs.Post("/join", middleware.RequireAuth, handlers.JoinClub)
+	clubs.Post("/leave", middleware.RequireAuth, handlers.LeaveClub)
 
 	// Meeting routes
 	meetings := api.Group("/meetings")
 	meetings.Get("/", handlers.GetMeetings)
-	meetings.Post("/", handlers.CreateMeeting)
+	meetings.Post("/", middleware.RequireAuth, handlers.CreateMeeting)
 	meetings.Get("/:id", handlers.GetMeeting)
 
 	// Health check
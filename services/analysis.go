@@ -1,9 +1,7 @@
 package services
 
 import (
-	"fmt"
 	"math"
-	"ongi-back/database"
 	"ongi-back/models"
 )
 
@@ -19,49 +17,11 @@ type AnalysisResult struct {
 	Scores        ScoreResult `json:"scores"`
 	ProfileType   string      `json:"profile_type"`
 	Descriptions  []string    `json:"descriptions"`
-	SuggestedClubs    []models.Club   `json:"suggested_clubs"`
+	SuggestedClubs    []ClubRecommendation `json:"suggested_clubs"`
 	SuggestedMeetings []models.Meeting `json:"suggested_meetings"`
 	SimilarUsers      []models.User    `json:"similar_users"`
 }
 
-func CalculateScores(userID uint) (*ScoreResult, error) {
-	var answers []models.UserAnswer
-
-	err := database.DB.Preload("Option").
-		Where("user_id = ?", userID).
-		Find(&answers).Error
-
-	if err != nil {
-		return nil, err
-	}
-
-	if len(answers) == 0 {
-		return nil, fmt.Errorf("no answers found for user")
-	}
-
-	scores := &ScoreResult{}
-	categoryScores := make(map[string][]int)
-
-	// 각 답변의 점수를 카테고리별로 분류
-	for _, answer := range answers {
-		weight := answer.Option.Weight
-		score := answer.Option.Score
-
-		if weight != "" {
-			categoryScores[weight] = append(categoryScores[weight], score)
-		}
-	}
-
-	// 카테고리별 평균 계산 (0-100 스케일로 변환)
-	scores.SocialityScore = calculateAverage(categoryScores["sociality"])
-	scores.ActivityScore = calculateAverage(categoryScores["activity"])
-	scores.IntimacyScore = calculateAverage(categoryScores["intimacy"])
-	scores.ImmersionScore = calculateAverage(categoryScores["immersion"])
-	scores.FlexibilityScore = calculateAverage(categoryScores["flexibility"])
-
-	return scores, nil
-}
-
 func calculateAverage(scores []int) float64 {
 	if len(scores) == 0 {
 		return 0
@@ -133,8 +93,8 @@ func DetermineProfileType(scores *ScoreResult) string {
 	}
 }
 
-func GetCompleteAnalysis(userID uint) (*AnalysisResult, error) {
-	scores, err := CalculateScores(userID)
+func GetCompleteAnalysis(subject ProfileSubject) (*AnalysisResult, error) {
+	scores, err := CalculateScores(subject)
 	if err != nil {
 		return nil, err
 	}
@@ -142,41 +102,26 @@ func GetCompleteAnalysis(userID uint) (*AnalysisResult, error) {
 	profileType := DetermineProfileType(scores)
 	descriptions := GenerateDescriptions(scores)
 
-	// 클럽 추천 (성향 기반)
-	suggestedClubs, _ := GetRecommendedClubs(userID, 10)
-
-	// 유사 멤버 기반 클럽 추천
-	similarClubs, _ := GetClubsWithSimilarMembers(userID, 5)
-
-	// 중복 제거하면서 결합
-	clubMap := make(map[uint]models.Club)
-	for _, club := range suggestedClubs {
-		clubMap[club.ID] = club
-	}
-	for _, club := range similarClubs {
-		clubMap[club.ID] = club
-	}
-
-	allClubs := make([]models.Club, 0, len(clubMap))
-	for _, club := range clubMap {
-		allClubs = append(allClubs, club)
-	}
+	// 클럽 추천 (성향 벡터 + 유사 멤버 + 인기도 합산, 순위 유지)
+	suggestedClubs, _ := RecommendClubs(subject, RecommendOptions{}, 10)
 
 	// 모임 추천
-	suggestedMeetings, _ := GetRecommendedMeetings(userID, 10)
+	suggestedMeetings, _ := GetRecommendedMeetings(subject, 10)
 
 	// 유사 사용자 추천
-	similarUsers, _ := GetSimilarUsers(userID, 10)
-	users := make([]models.User, len(similarUsers))
-	for i, sim := range similarUsers {
-		users[i] = sim.User
+	similarProfiles, _ := GetSimilarProfiles(subject, 10)
+	users := make([]models.User, 0, len(similarProfiles))
+	for _, sim := range similarProfiles {
+		if sim.User != nil {
+			users = append(users, *sim.User)
+		}
 	}
 
 	result := &AnalysisResult{
 		Scores:            *scores,
 		ProfileType:       profileType,
 		Descriptions:      descriptions,
-		SuggestedClubs:    allClubs,
+		SuggestedClubs:    suggestedClubs,
 		SuggestedMeetings: suggestedMeetings,
 		SimilarUsers:      users,
 	}
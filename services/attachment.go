@@ -0,0 +1,349 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // 썸네일 생성용 디코더 등록
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"ongi-back/database"
+	"ongi-back/models"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttachmentTooLarge    = errors.New("attachment is too large")
+	ErrAttachmentType        = errors.New("attachment type is not allowed")
+	ErrAttachmentNotFound    = errors.New("attachment not found")
+	ErrAttachmentNotUploader = errors.New("attachment was uploaded by another user")
+)
+
+// 썸네일 기본 설정
+const (
+	ThumbnailMaxSize      = 320              // 긴 변 기준 px
+	thumbnailJPEGQuality  = 80               // 썸네일 JPEG 품질
+	maxThumbnailSourcePix = 40 * 1000 * 1000 // 이보다 큰 이미지는 썸네일 생성 생략 (압축 폭탄 방지)
+)
+
+// allowedAttachmentTypes 업로드 허용 MIME 타입 (내용으로 판별한 타입 기준)
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"application/zip": true,
+	"text/plain":      true,
+}
+
+// AttachmentConfigType 첨부파일 설정
+type AttachmentConfigType struct {
+	MaxSize int64 // 최대 파일 크기 (bytes)
+}
+
+// AttachmentConfig 현재 첨부파일 설정
+var AttachmentConfig = LoadAttachmentConfig()
+
+// LoadAttachmentConfig 첨부파일 설정 로드 (CHAT_MAX_UPLOAD_SIZE, 기본값 10MB)
+func LoadAttachmentConfig() AttachmentConfigType {
+	config := AttachmentConfigType{MaxSize: 10 << 20}
+	if n, err := strconv.ParseInt(os.Getenv("CHAT_MAX_UPLOAD_SIZE"), 10, 64); err == nil && n > 0 {
+		config.MaxSize = n
+	}
+	return config
+}
+
+// UploadChatAttachment 첨부파일 업로드
+// MIME 타입은 클라이언트가 보낸 값이 아니라 파일 내용으로 판별하며, 이미지는 썸네일을 함께 저장
+func UploadChatAttachment(roomID, userID uint, fileHeader *multipart.FileHeader) (*models.ChatAttachment, error) {
+	if GlobalStorage == nil {
+		return nil, errors.New("attachment storage is not initialized")
+	}
+
+	var membership models.ChatRoomMember
+	if err := database.DB.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&membership).Error; err != nil {
+		return nil, ErrNotRoomMember
+	}
+
+	if fileHeader.Size > AttachmentConfig.MaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, AttachmentConfig.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > AttachmentConfig.MaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	contentType := sniffContentType(data)
+	if !allowedAttachmentTypes[contentType] {
+		return nil, ErrAttachmentType
+	}
+
+	prefix, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	fileName := sanitizeFileName(fileHeader.Filename)
+	storageKey := fmt.Sprintf("chat/%d/%s/%s", roomID, prefix, fileName)
+
+	if err := GlobalStorage.Put(storageKey, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	attachment := models.ChatAttachment{
+		ChatRoomID:  roomID,
+		UploaderID:  userID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  storageKey,
+	}
+
+	// 이미지 크기 및 썸네일
+	if strings.HasPrefix(contentType, "image/") {
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			attachment.Width = cfg.Width
+			attachment.Height = cfg.Height
+
+			if thumbnail, err := makeThumbnail(data, cfg); err == nil {
+				thumbnailKey := storageKey + ".thumb.jpg"
+				if err := GlobalStorage.Put(thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err == nil {
+					attachment.ThumbnailKey = &thumbnailKey
+				} else {
+					log.Printf("Failed to store thumbnail: key=%s, err=%v", thumbnailKey, err)
+				}
+			}
+		}
+	}
+
+	if err := database.DB.Create(&attachment).Error; err != nil {
+		GlobalStorage.Delete(storageKey)
+		if attachment.ThumbnailKey != nil {
+			GlobalStorage.Delete(*attachment.ThumbnailKey)
+		}
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+// OpenChatAttachment 첨부파일 읽기 (variant가 "thumbnail"이면 썸네일)
+// userID가 현재 채팅방 멤버가 아니면 ErrNotRoomMember
+func OpenChatAttachment(attachmentID, userID uint, variant string) (*models.ChatAttachment, io.ReadCloser, error) {
+	if GlobalStorage == nil {
+		return nil, nil, errors.New("attachment storage is not initialized")
+	}
+
+	var attachment models.ChatAttachment
+	if err := database.DB.First(&attachment, attachmentID).Error; err != nil {
+		return nil, nil, ErrAttachmentNotFound
+	}
+
+	var membership models.ChatRoomMember
+	if err := database.DB.Where("chat_room_id = ? AND user_id = ?", attachment.ChatRoomID, userID).First(&membership).Error; err != nil {
+		return nil, nil, ErrNotRoomMember
+	}
+
+	key := attachment.StorageKey
+	if variant == "thumbnail" {
+		if attachment.ThumbnailKey == nil {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		key = *attachment.ThumbnailKey
+	}
+
+	reader, err := GlobalStorage.Open(key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &attachment, reader, nil
+}
+
+// deleteAttachmentObjects 첨부파일의 원본/썸네일을 저장소에서 삭제 (DB 트랜잭션 커밋 후 호출)
+func deleteAttachmentObjects(attachments []models.ChatAttachment) {
+	if GlobalStorage == nil {
+		return
+	}
+	for _, attachment := range attachments {
+		keys := []string{attachment.StorageKey}
+		if attachment.ThumbnailKey != nil {
+			keys = append(keys, *attachment.ThumbnailKey)
+		}
+		for _, key := range keys {
+			if err := GlobalStorage.Delete(key); err != nil && !errors.Is(err, ErrObjectNotFound) {
+				log.Printf("Failed to delete attachment object: AttachmentID=%d, key=%s, err=%v", attachment.ID, key, err)
+			}
+		}
+	}
+}
+
+// releaseAttachment 다른 메시지에서 쓰지 않는 첨부파일 레코드 삭제 (삭제했으면 반환, 저장소 파일은 커밋 후 삭제)
+func releaseAttachment(tx *gorm.DB, attachmentID uint) (*models.ChatAttachment, error) {
+	var inUse int64
+	if err := tx.Model(&models.ChatMessage{}).Where("attachment_id = ?", attachmentID).Count(&inUse).Error; err != nil {
+		return nil, err
+	}
+	if inUse > 0 {
+		return nil, nil
+	}
+
+	var attachment models.ChatAttachment
+	if err := tx.First(&attachment, attachmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := tx.Delete(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// findAttachmentForMessage 메시지에 첨부할 파일 확인 (같은 채팅방, 본인이 업로드한 파일만)
+func findAttachmentForMessage(roomID, userID, attachmentID uint) (*models.ChatAttachment, error) {
+	var attachment models.ChatAttachment
+	if err := database.DB.Where("id = ? AND chat_room_id = ?", attachmentID, roomID).First(&attachment).Error; err != nil {
+		return nil, ErrAttachmentNotFound
+	}
+	if attachment.UploaderID != userID {
+		return nil, ErrAttachmentNotUploader
+	}
+	return &attachment, nil
+}
+
+// sniffContentType 파일 내용으로 MIME 타입 판별 (파라미터 제외)
+func sniffContentType(data []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitizeFileName 저장소 key에 안전한 파일 이름으로 변환
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Trim(unsafeFileNameChars.ReplaceAllString(name, "_"), "._")
+	if name == "" {
+		name = "file"
+	}
+	if len(name) > 100 {
+		name = name[len(name)-100:]
+	}
+	return name
+}
+
+// makeThumbnail 긴 변이 ThumbnailMaxSize가 되도록 축소한 JPEG 썸네일 생성
+func makeThumbnail(data []byte, cfg image.Config) ([]byte, error) {
+	if cfg.Width*cfg.Height > maxThumbnailSourcePix {
+		return nil, errors.New("image is too large for thumbnail")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnail := resizeImage(src, ThumbnailMaxSize)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: thumbnailJPEGQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resizeImage 박스 필터로 축소 (원본이 더 작으면 크기 유지)
+func resizeImage(src image.Image, maxSize int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := srcW, srcH
+	if srcW >= srcH && srcW > maxSize {
+		dstW, dstH = maxSize, srcH*maxSize/srcW
+	} else if srcH > srcW && srcH > maxSize {
+		dstW, dstH = srcW*maxSize/srcH, maxSize
+	}
+	if dstW < 1 {
+		dstW = 1
+	}
+	if dstH < 1 {
+		dstH = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := bounds.Min.Y + (y+1)*srcH/dstH
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := bounds.Min.X + (x+1)*srcW/dstW
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
+
+// randomHex n바이트 랜덤 hex 문자열
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"ongi-back/database"
+	"ongi-back/models"
+	"testing"
+	"time"
+)
+
+// encodeTestImage 1x1 이미지를 지정한 형식으로 인코딩
+func encodeTestImage(t *testing.T, format string) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("failed to encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniffContentTypeAgainstAllowList(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		allowed     bool
+	}{
+		{"png", encodeTestImage(t, "png"), "image/png", true},
+		{"jpeg", encodeTestImage(t, "jpeg"), "image/jpeg", true},
+		{"gif", encodeTestImage(t, "gif"), "image/gif", true},
+		{"webp", []byte("RIFF\x1a\x00\x00\x00WEBPVP8 "), "image/webp", true},
+		{"pdf", []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"), "application/pdf", true},
+		{"zip", []byte("PK\x03\x04\x14\x00\x00\x00"), "application/zip", true},
+		{"plain text with charset parameter stripped", []byte("hello, 안녕하세요"), "text/plain", true},
+		{"html", []byte("<!DOCTYPE html><html><script>alert(1)</script></html>"), "text/html", false},
+		{"html disguised with leading whitespace", []byte("  \n<html><body></body></html>"), "text/html", false},
+		{"svg is sniffed as text/xml", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`), "text/xml", false},
+		{"executable", []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00"), "application/octet-stream", false},
+		{"elf binary", []byte("\x7fELF\x02\x01\x01\x00"), "application/octet-stream", false},
+		{"gzip", []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00"), "application/x-gzip", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sniffContentType(tt.data)
+			if got != tt.contentType {
+				t.Fatalf("sniffContentType() = %q, want %q", got, tt.contentType)
+			}
+			if allowedAttachmentTypes[got] != tt.allowed {
+				t.Errorf("allowedAttachmentTypes[%q] = %v, want %v", got, allowedAttachmentTypes[got], tt.allowed)
+			}
+		})
+	}
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	long := bytes.Repeat([]byte("a"), 120)
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "photo.png", "photo.png"},
+		{"unix path traversal", "../../etc/passwd", "passwd"},
+		{"windows path", `C:\Users\me\report.pdf`, "report.pdf"},
+		{"spaces and unicode", "여행 사진 (1).jpg", "1_.jpg"},
+		{"leading dots", "..hidden", "hidden"},
+		{"only unsafe characters", "???", "file"},
+		{"empty", "", "file"},
+		{"long name keeps extension", string(long) + ".txt", string(long[:96]) + ".txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeFileName(tt.in); got != tt.want {
+				t.Errorf("sanitizeFileName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// testFileHeader 업로드 요청과 같은 multipart.FileHeader 생성
+func testFileHeader(t *testing.T, fileName string, data []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	writer.Close()
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+// TestAttachmentDownloadRechecksMembership 서명 URL을 받은 뒤 채팅방에서 나가면 다운로드 불가
+func TestAttachmentDownloadRechecksMembership(t *testing.T) {
+	openTestDB(t)
+
+	storage, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := GlobalStorage
+	GlobalStorage = storage
+	t.Cleanup(func() { GlobalStorage = previous })
+
+	suffix := time.Now().UnixNano()
+	member := models.User{Email: fmt.Sprintf("attachment-member-%d@example.com", suffix), Name: "member"}
+	outsider := models.User{Email: fmt.Sprintf("attachment-outsider-%d@example.com", suffix), Name: "outsider"}
+	for _, user := range []*models.User{&member, &outsider} {
+		if err := database.DB.Create(user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	room := models.ChatRoom{Name: "attachment test", CreatedBy: member.ID}
+	if err := database.DB.Create(&room).Error; err != nil {
+		t.Fatal(err)
+	}
+	membership := models.ChatRoomMember{ChatRoomID: room.ID, UserID: member.ID, Role: RoleMember, JoinedAt: time.Now()}
+	if err := database.DB.Create(&membership).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		database.DB.Where("chat_room_id = ?", room.ID).Delete(&models.ChatAttachment{})
+		database.DB.Where("chat_room_id = ?", room.ID).Delete(&models.ChatRoomMember{})
+		database.DB.Delete(&room)
+		database.DB.Delete(&member)
+		database.DB.Delete(&outsider)
+	})
+
+	if _, err := UploadChatAttachment(room.ID, outsider.ID, testFileHeader(t, "a.png", encodeTestImage(t, "png"))); !errors.Is(err, ErrNotRoomMember) {
+		t.Fatalf("upload by non-member: err=%v, want ErrNotRoomMember", err)
+	}
+	if _, err := UploadChatAttachment(room.ID, member.ID, testFileHeader(t, "page.png", []byte("<html></html>"))); !errors.Is(err, ErrAttachmentType) {
+		t.Fatalf("upload with forged extension: err=%v, want ErrAttachmentType", err)
+	}
+
+	data := encodeTestImage(t, "png")
+	attachment, err := UploadChatAttachment(room.ID, member.ID, testFileHeader(t, "my photo.png", data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachment.ContentType != "image/png" || attachment.FileName != "my_photo.png" || attachment.ThumbnailKey == nil {
+		t.Fatalf("unexpected attachment: %+v", attachment)
+	}
+
+	_, reader, err := OpenChatAttachment(attachment.ID, member.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(reader)
+	reader.Close()
+	if !bytes.Equal(got, data) {
+		t.Fatal("downloaded content differs from upload")
+	}
+
+	if _, _, err := OpenChatAttachment(attachment.ID, outsider.ID, ""); !errors.Is(err, ErrNotRoomMember) {
+		t.Fatalf("download by non-member: err=%v, want ErrNotRoomMember", err)
+	}
+
+	database.DB.Delete(&membership)
+	if _, _, err := OpenChatAttachment(attachment.ID, member.ID, "thumbnail"); !errors.Is(err, ErrNotRoomMember) {
+		t.Fatalf("download after leaving: err=%v, want ErrNotRoomMember", err)
+	}
+}
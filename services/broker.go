@@ -0,0 +1,156 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"ongi-back/database"
+	"ongi-back/models"
+	"sync"
+)
+
+// Broker Hub 메시지를 서버 인스턴스 간에 전달하는 인터페이스
+// Publish된 메시지는 구독 중인 모든 Hub(자기 자신 포함)에 전달됨
+type Broker interface {
+	// Publish 메시지 발행
+	Publish(msg *Message) error
+	// Subscribe 발행된 메시지를 받을 콜백 등록
+	Subscribe(deliver func(msg *Message)) error
+	// Close 구독 종료 및 리소스 정리
+	Close() error
+}
+
+// MemoryBroker 단일 프로세스용 브로커 (기본값)
+type MemoryBroker struct {
+	mu          sync.RWMutex
+	subscribers []func(msg *Message)
+}
+
+// NewMemoryBroker MemoryBroker 생성
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+// Publish 구독자에게 바로 전달
+func (b *MemoryBroker) Publish(msg *Message) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, deliver := range b.subscribers {
+		deliver(msg)
+	}
+	return nil
+}
+
+// Subscribe 구독자 등록
+func (b *MemoryBroker) Subscribe(deliver func(msg *Message)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers = append(b.subscribers, deliver)
+	return nil
+}
+
+// Close 구독자 정리
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers = nil
+	return nil
+}
+
+// MessageRef 종류
+const (
+	MessageRefChatMessage = "chat_message"
+	MessageRefChatRoom    = "chat_room"
+)
+
+// withRef DB에 저장된 레코드(메시지, 채팅방)를 담은 메시지를 참조만 담은 복사본으로 변환
+// 메시지 본문 크기와 관계없이 브로커 페이로드를 작게 유지 (해당하지 않으면 nil)
+func withRef(msg *Message) *Message {
+	var ref *MessageRef
+	switch data := msg.Data.(type) {
+	case models.ChatMessage:
+		ref = chatMessageRef(&data)
+	case *models.ChatMessage:
+		ref = chatMessageRef(data)
+	case models.ChatRoom:
+		ref = &MessageRef{Kind: MessageRefChatRoom, ID: data.ID}
+	case *models.ChatRoom:
+		ref = &MessageRef{Kind: MessageRefChatRoom, ID: data.ID}
+	default:
+		return nil
+	}
+
+	envelope := *msg
+	envelope.Data = nil
+	envelope.Ref = ref
+	return &envelope
+}
+
+// chatMessageRef 채팅 메시지 참조 (조회 시 계산되는 반응 수, 안 읽은 멤버 수 포함)
+func chatMessageRef(message *models.ChatMessage) *MessageRef {
+	return &MessageRef{
+		Kind:              MessageRefChatMessage,
+		ID:                message.ID,
+		Reactions:         message.Reactions,
+		UnreadMemberCount: message.UnreadMemberCount,
+	}
+}
+
+// resolveMessageRef 참조로 받은 메시지의 Data를 DB에서 다시 조회해 채움
+func resolveMessageRef(msg *Message) error {
+	switch msg.Ref.Kind {
+	case MessageRefChatMessage:
+		var message models.ChatMessage
+		if err := database.DB.Preload("User").Preload("Attachment").First(&message, msg.Ref.ID).Error; err != nil {
+			return err
+		}
+		RedactDeletedMessage(&message)
+		message.Reactions = msg.Ref.Reactions
+		message.UnreadMemberCount = msg.Ref.UnreadMemberCount
+		msg.Data = message
+	case MessageRefChatRoom:
+		var room models.ChatRoom
+		if err := database.DB.Preload("Creator").Preload("Club").First(&room, msg.Ref.ID).Error; err != nil {
+			return err
+		}
+		msg.Data = room
+	default:
+		return fmt.Errorf("unknown message ref kind: %s", msg.Ref.Kind)
+	}
+
+	msg.Ref = nil
+	return nil
+}
+
+// brokerEnvelope 브로커로 전달하는 메시지 (클라이언트에는 보내지 않는 Hub 내부 필드 포함)
+type brokerEnvelope struct {
+	Message
+	ExcludeUserID    uint        `json:"exclude_user_id,omitempty"`
+	DisconnectUserID uint        `json:"disconnect_user_id,omitempty"`
+	Ref              *MessageRef `json:"ref,omitempty"`
+}
+
+// encodeBrokerMessage 브로커 페이로드로 직렬화
+func encodeBrokerMessage(msg *Message) ([]byte, error) {
+	return json.Marshal(brokerEnvelope{
+		Message:          *msg,
+		ExcludeUserID:    msg.ExcludeUserID,
+		DisconnectUserID: msg.DisconnectUserID,
+		Ref:              msg.Ref,
+	})
+}
+
+// decodeBrokerMessage 브로커 페이로드를 Hub 메시지로 복원
+func decodeBrokerMessage(payload []byte) (*Message, error) {
+	var envelope brokerEnvelope
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil, err
+	}
+	msg := envelope.Message
+	msg.ExcludeUserID = envelope.ExcludeUserID
+	msg.DisconnectUserID = envelope.DisconnectUserID
+	msg.Ref = envelope.Ref
+	return &msg, nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// DefaultBrokerChannel Postgres NOTIFY 채널 이름
+const DefaultBrokerChannel = "ongi_chat_events"
+
+// pgNotifyMaxPayload NOTIFY 페이로드 최대 크기 (Postgres 기본 8000 bytes 미만)
+const pgNotifyMaxPayload = 7900
+
+// PostgresBroker Postgres LISTEN/NOTIFY 기반 브로커 (다중 인스턴스용)
+type PostgresBroker struct {
+	db      *gorm.DB
+	dsn     string
+	channel string
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewPostgresBroker PostgresBroker 생성
+// db는 NOTIFY 발행용, dsn은 LISTEN 전용 연결에 사용
+func NewPostgresBroker(db *gorm.DB, dsn string, channel string) *PostgresBroker {
+	if channel == "" {
+		channel = DefaultBrokerChannel
+	}
+	return &PostgresBroker{
+		db:      db,
+		dsn:     dsn,
+		channel: channel,
+	}
+}
+
+// Publish pg_notify로 메시지 발행
+// 채팅 메시지/채팅방 이벤트는 ID만 보내고 받는 쪽에서 DB에서 다시 조회 (NOTIFY 크기 제한 회피)
+func (b *PostgresBroker) Publish(msg *Message) error {
+	if envelope := withRef(msg); envelope != nil {
+		msg = envelope
+	}
+
+	payload, err := encodeBrokerMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	if len(payload) > pgNotifyMaxPayload {
+		return fmt.Errorf("message payload too large for NOTIFY: %d bytes", len(payload))
+	}
+
+	return b.db.Exec("SELECT pg_notify(?, ?)", b.channel, string(payload)).Error
+}
+
+// Subscribe LISTEN 연결을 열고 알림을 받을 때마다 deliver 호출
+func (b *PostgresBroker) Subscribe(deliver func(msg *Message)) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	conn, err := b.listen(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	go b.run(ctx, conn, deliver)
+	return nil
+}
+
+// Close LISTEN 연결 종료
+func (b *PostgresBroker) Close() error {
+	if b.cancel == nil {
+		return nil
+	}
+	b.cancel()
+	<-b.done
+	return nil
+}
+
+// listen LISTEN 전용 연결 생성
+func (b *PostgresBroker) listen(ctx context.Context) (*pgx.Conn, error) {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect listener: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		conn.Close(ctx)
+		return nil, fmt.Errorf("failed to listen on channel %s: %w", b.channel, err)
+	}
+
+	return conn, nil
+}
+
+// run 알림 수신 루프 (연결이 끊어지면 재연결)
+func (b *PostgresBroker) run(ctx context.Context, conn *pgx.Conn, deliver func(msg *Message)) {
+	defer close(b.done)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			conn.Close(context.Background())
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Printf("Broker listener error: %v", err)
+			conn = b.reconnect(ctx)
+			if conn == nil {
+				return
+			}
+			continue
+		}
+
+		msg, err := decodeBrokerMessage([]byte(notification.Payload))
+		if err != nil {
+			log.Printf("Error unmarshaling broker message: %v", err)
+			continue
+		}
+
+		deliver(msg)
+	}
+}
+
+// reconnect LISTEN 연결 재시도 (ctx 취소 시 nil 반환)
+func (b *PostgresBroker) reconnect(ctx context.Context) *pgx.Conn {
+	backoff := time.Second
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		conn, err := b.listen(ctx)
+		if err == nil {
+			log.Printf("Broker listener reconnected")
+			return conn
+		}
+
+		log.Printf("Broker reconnect failed: %v", err)
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
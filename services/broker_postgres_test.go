@@ -0,0 +1,152 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"ongi-back/database"
+	"ongi-back/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// largeMessageText 허용 최대 길이의 메시지 (이모지 4 bytes, <와 &는 JSON에서 6 bytes)
+func largeMessageText() string {
+	return strings.Repeat("😀", DefaultMaxMessageLength-2) + "<&"
+}
+
+func TestWithRefKeepsNotifyPayloadSmall(t *testing.T) {
+	message := models.ChatMessage{
+		ID:         42,
+		ChatRoomID: 7,
+		UserID:     3,
+		Message:    largeMessageText(),
+		Links:      []string{"https://example.com/" + strings.Repeat("a", 2000)},
+	}
+
+	full, _ := json.Marshal(&Message{Type: "message", RoomID: 7, UserID: 3, Data: message})
+	if len(full) <= pgNotifyMaxPayload {
+		t.Fatalf("expected full payload over NOTIFY limit, got %d bytes", len(full))
+	}
+
+	envelope := withRef(&Message{Type: "message", RoomID: 7, UserID: 3, Data: message})
+	if envelope == nil || envelope.Ref == nil || envelope.Ref.Kind != MessageRefChatMessage || envelope.Ref.ID != 42 {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+	payload, _ := json.Marshal(envelope)
+	if len(payload) > 256 {
+		t.Fatalf("envelope too large: %d bytes", len(payload))
+	}
+
+	if withRef(&Message{Type: "read", Data: map[string]interface{}{"user_id": 1}}) != nil {
+		t.Fatal("expected non-record payload to be published as is")
+	}
+}
+
+// TestPostgresBrokerTwoHubs 같은 Postgres를 쓰는 두 Hub 사이에 큰 메시지가 전달되는지 확인
+func TestPostgresBrokerTwoHubs(t *testing.T) {
+	dsn := openTestDB(t)
+	channel := fmt.Sprintf("ongi_chat_events_test_%d", time.Now().UnixNano())
+
+	user := models.User{Email: fmt.Sprintf("broker-%d@example.com", time.Now().UnixNano()), Name: "broker"}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	room := models.ChatRoom{Name: "broker test", CreatedBy: user.ID}
+	if err := database.DB.Create(&room).Error; err != nil {
+		t.Fatal(err)
+	}
+	message := models.ChatMessage{ChatRoomID: room.ID, UserID: user.ID, Message: largeMessageText()}
+	if err := database.DB.Create(&message).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		database.DB.Delete(&message)
+		database.DB.Delete(&room)
+		database.DB.Delete(&user)
+	})
+
+	hubA, err := NewHubWithBroker(NewPostgresBroker(database.DB, dsn, channel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hubA.Close()
+	hubB, err := NewHubWithBroker(NewPostgresBroker(database.DB, dsn, channel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hubB.Close()
+	go hubA.Run()
+	go hubB.Run()
+
+	clientA := &Client{Hub: hubA, Send: make(chan []byte, 8), UserID: user.ID, RoomID: room.ID}
+	clientB := &Client{Hub: hubB, Send: make(chan []byte, 8), UserID: user.ID, RoomID: room.ID}
+	hubA.Register <- clientA
+	hubB.Register <- clientB
+
+	database.DB.Preload("User").First(&message, message.ID)
+	message.Reactions = []models.ReactionSummary{{Emoji: "👍", Count: 1}}
+	message.UnreadMemberCount = 2
+	hubA.BroadcastMessage(room.ID, "message", user.ID, message)
+
+	for name, client := range map[string]*Client{"local hub": clientA, "remote hub": clientB} {
+		select {
+		case raw := <-client.Send:
+			var received struct {
+				Type string             `json:"type"`
+				Data models.ChatMessage `json:"data"`
+				Ref  *MessageRef        `json:"ref"`
+			}
+			if err := json.Unmarshal(raw, &received); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if received.Type != "message" || received.Data.ID != message.ID || received.Data.Message != message.Message {
+				t.Fatalf("%s: unexpected message type=%s id=%d", name, received.Type, received.Data.ID)
+			}
+			if received.Data.User.ID != user.ID || received.Ref != nil {
+				t.Fatalf("%s: message not reloaded with user", name)
+			}
+			if received.Data.UnreadMemberCount != 2 || len(received.Data.Reactions) != 1 || received.Data.Reactions[0].Count != 1 {
+				t.Fatalf("%s: computed fields lost: reactions=%v unread=%d", name, received.Data.Reactions, received.Data.UnreadMemberCount)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: message not delivered", name)
+		}
+	}
+}
+
+func TestBrokerEnvelopeKeepsInternalFieldsOffTheWire(t *testing.T) {
+	message := models.ChatMessage{
+		ID:                42,
+		ChatRoomID:        7,
+		Message:           "hi",
+		Reactions:         []models.ReactionSummary{{Emoji: "👍", Count: 2}},
+		UnreadMemberCount: 3,
+	}
+	msg := &Message{Type: "message_updated", RoomID: 7, UserID: 3, Data: message, ExcludeUserID: 3, DisconnectUserID: 9}
+
+	// 클라이언트 프레임에는 Hub 내부 필드가 없음
+	frame, _ := json.Marshal(msg)
+	for _, key := range []string{"exclude_user_id", "disconnect_user_id", `"ref"`} {
+		if strings.Contains(string(frame), key) {
+			t.Errorf("client frame contains %s: %s", key, frame)
+		}
+	}
+
+	// 브로커 페이로드는 내부 필드와 계산된 메시지 필드를 유지
+	payload, err := encodeBrokerMessage(withRef(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeBrokerMessage(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Type != msg.Type || decoded.RoomID != 7 || decoded.UserID != 3 || decoded.ExcludeUserID != 3 || decoded.DisconnectUserID != 9 {
+		t.Fatalf("decoded = %+v", decoded)
+	}
+	if decoded.Ref == nil || decoded.Ref.ID != 42 || decoded.Ref.UnreadMemberCount != 3 ||
+		len(decoded.Ref.Reactions) != 1 || decoded.Ref.Reactions[0] != message.Reactions[0] {
+		t.Fatalf("decoded ref = %+v", decoded.Ref)
+	}
+}
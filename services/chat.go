@@ -0,0 +1,386 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrChatRoomNotFound  = errors.New("chat room not found")
+	ErrNotRoomMember     = errors.New("user is not a member of this chat room")
+	ErrEmptyMessage      = errors.New("message is empty")
+	ErrMessageNotFound   = errors.New("message not found")
+	ErrMessageDeleted    = errors.New("message already deleted")
+	ErrNotMessageAuthor  = errors.New("not allowed to modify this message")
+	ErrSystemMessageType = errors.New("system messages are created by the server")
+)
+
+// SendMessageInput 메시지 전송 입력 (HTTP/WebSocket 공통)
+type SendMessageInput struct {
+	Message         string
+	MessageType     string // text, image, file (system은 서버만 생성)
+	FileURL         string
+	AttachmentID    uint   // POST /chat/rooms/:id/attachments로 업로드한 첨부파일
+	ReplyToID       uint   // 답장할 메시지 ID (스레드)
+	ClientMessageID string // 클라이언트 생성 멱등성 키 (재전송 시 중복 방지)
+}
+
+// SendChatMessage 메시지 저장 후 채팅방 정보 갱신 및 브로드캐스트
+// 같은 ClientMessageID로 다시 전송되면 기존 메시지를 반환 (duplicate=true)
+func SendChatMessage(roomID, userID uint, input SendMessageInput) (*models.ChatMessage, bool, error) {
+	if input.Message == "" && input.FileURL == "" && input.AttachmentID == 0 {
+		return nil, false, ErrEmptyMessage
+	}
+	if input.MessageType == MessageTypeSystem {
+		return nil, false, ErrSystemMessageType
+	}
+
+	// 채팅방 존재 확인
+	var chatRoom models.ChatRoom
+	if err := database.DB.First(&chatRoom, roomID).Error; err != nil {
+		return nil, false, ErrChatRoomNotFound
+	}
+	if chatRoom.ArchivedAt != nil {
+		return nil, false, ErrRoomArchived
+	}
+
+	// 사용자가 채팅방 멤버인지 확인
+	var membership models.ChatRoomMember
+	if err := database.DB.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&membership).Error; err != nil {
+		return nil, false, ErrNotRoomMember
+	}
+
+	// 음소거 중인 멤버는 전송 불가
+	if err := checkMuted(&membership); err != nil {
+		return nil, false, err
+	}
+
+	// 이미 처리된 멱등성 키인지 확인
+	if existing := findByClientMessageID(userID, input.ClientMessageID); existing != nil {
+		return existing, true, nil
+	}
+
+	// 첨부파일 확인 (같은 채팅방에 본인이 업로드한 파일만)
+	var attachmentID *uint
+	if input.AttachmentID != 0 {
+		attachment, err := findAttachmentForMessage(roomID, userID, input.AttachmentID)
+		if err != nil {
+			return nil, false, err
+		}
+		attachmentID = &attachment.ID
+
+		if input.MessageType == "" {
+			input.MessageType = "file"
+			if strings.HasPrefix(attachment.ContentType, "image/") {
+				input.MessageType = "image"
+			}
+		}
+	}
+
+	// 속도 제한, 길이 제한, 금칙어 마스킹, 링크 감지
+	content := MessageContent{RoomID: roomID, UserID: userID, Text: input.Message}
+	if err := GlobalContentPipeline.Run(&content); err != nil {
+		return nil, false, err
+	}
+
+	// 답장 대상 확인 (답장에 답장하면 스레드의 첫 메시지에 연결)
+	var replyToID *uint
+	if input.ReplyToID != 0 {
+		root, err := findThreadRoot(roomID, input.ReplyToID)
+		if err != nil {
+			return nil, false, err
+		}
+		replyToID = &root.ID
+	}
+
+	// 기본값 설정
+	if input.MessageType == "" {
+		input.MessageType = "text"
+	}
+
+	var fileURL *string
+	if input.FileURL != "" {
+		fileURL = &input.FileURL
+	}
+
+	var clientMessageID *string
+	if input.ClientMessageID != "" {
+		clientMessageID = &input.ClientMessageID
+	}
+
+	message := models.ChatMessage{
+		ChatRoomID:      chatRoom.ID,
+		UserID:          userID,
+		Message:         content.Text,
+		MessageType:     input.MessageType,
+		Links:           content.Links,
+		FileURL:         fileURL,
+		AttachmentID:    attachmentID,
+		ClientMessageID: clientMessageID,
+		ReplyToID:       replyToID,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+
+		if replyToID != nil {
+			if err := tx.Model(&models.ChatMessage{}).Where("id = ?", *replyToID).
+				UpdateColumn("reply_count", gorm.Expr("reply_count + 1")).Error; err != nil {
+				return err
+			}
+		}
+
+		// 채팅방의 last_message 및 last_message_at 업데이트
+		if err := tx.Model(&chatRoom).Updates(map[string]interface{}{
+			"last_message":    content.Text,
+			"last_message_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		// 보낸 사람은 자신의 메시지까지 읽은 것으로 처리 (다른 멤버의 안 읽은 수는 커서로 계산)
+		return tx.Model(&membership).Updates(map[string]interface{}{
+			"last_read_message_id": message.ID,
+			"last_read_at":         message.CreatedAt,
+		}).Error
+	})
+	if err != nil {
+		// 동시에 같은 키로 전송된 경우 먼저 저장된 메시지 반환
+		if existing := findByClientMessageID(userID, input.ClientMessageID); existing != nil {
+			return existing, true, nil
+		}
+		return nil, false, err
+	}
+
+	// 메시지 정보 조회 (사용자 정보 포함)
+	database.DB.Preload("User").Preload("Attachment").First(&message, message.ID)
+
+	// WebSocket으로 실시간 브로드캐스트
+	if GlobalHub != nil {
+		GlobalHub.BroadcastMessage(chatRoom.ID, "message", userID, message)
+	}
+
+	return &message, false, nil
+}
+
+// findByClientMessageID 멱등성 키로 기존 메시지 조회
+func findByClientMessageID(userID uint, clientMessageID string) *models.ChatMessage {
+	if clientMessageID == "" {
+		return nil
+	}
+
+	var message models.ChatMessage
+	if err := database.DB.Preload("User").Preload("Attachment").
+		Where("user_id = ? AND client_message_id = ?", userID, clientMessageID).
+		First(&message).Error; err != nil {
+		return nil
+	}
+	return &message
+}
+
+// findThreadRoot 답장 대상 메시지의 스레드 첫 메시지 조회 (같은 채팅방, 삭제되지 않은 메시지만)
+func findThreadRoot(roomID, messageID uint) (*models.ChatMessage, error) {
+	var message models.ChatMessage
+	if err := database.DB.Where("id = ? AND chat_room_id = ?", messageID, roomID).First(&message).Error; err != nil {
+		return nil, ErrMessageNotFound
+	}
+	if message.ReplyToID != nil {
+		if err := database.DB.Where("id = ? AND chat_room_id = ?", *message.ReplyToID, roomID).First(&message).Error; err != nil {
+			return nil, ErrMessageNotFound
+		}
+	}
+	if message.DeletedAt != nil {
+		return nil, ErrMessageDeleted
+	}
+	return &message, nil
+}
+
+// EditChatMessage 메시지 수정 (작성자만 가능, 수정 이력 저장)
+func EditChatMessage(roomID, messageID, userID uint, text string) (*models.ChatMessage, error) {
+	if text == "" {
+		return nil, ErrEmptyMessage
+	}
+
+	var message models.ChatMessage
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := requireWritableRoom(tx, roomID); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ? AND chat_room_id = ?", messageID, roomID).First(&message).Error; err != nil {
+			return ErrMessageNotFound
+		}
+
+		if message.DeletedAt != nil {
+			return ErrMessageDeleted
+		}
+		if message.UserID != userID || message.MessageType == MessageTypeSystem {
+			return ErrNotMessageAuthor
+		}
+
+		var membership models.ChatRoomMember
+		if err := tx.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&membership).Error; err != nil {
+			return ErrNotRoomMember
+		}
+
+		// 음소거 중인 멤버는 전송과 마찬가지로 수정도 불가
+		if err := checkMuted(&membership); err != nil {
+			return err
+		}
+
+		// 수정은 속도 제한 없이 길이/금칙어/링크 필터만 적용
+		content := MessageContent{RoomID: roomID, UserID: userID, Text: text}
+		if err := GlobalContentPipeline.RunForEdit(&content); err != nil {
+			return err
+		}
+
+		edit := models.ChatMessageEdit{
+			ChatMessageID:   message.ID,
+			PreviousMessage: message.Message,
+			EditedBy:        userID,
+		}
+		if err := tx.Create(&edit).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&message).Select("message", "links", "edited_at").Updates(models.ChatMessage{
+			Message:  content.Text,
+			Links:    content.Links,
+			EditedAt: &now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return refreshLastMessage(tx, roomID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	database.DB.Preload("User").Preload("Attachment").First(&message, message.ID)
+
+	if GlobalHub != nil {
+		GlobalHub.BroadcastMessage(roomID, "message_updated", userID, message)
+	}
+
+	return &message, nil
+}
+
+// DeleteChatMessage 메시지 삭제 (작성자 또는 채팅방 admin, 시스템 메시지는 admin만)
+// 메시지는 내용을 지운 tombstone으로 남아 히스토리 순서가 유지됨
+// 첨부파일은 다른 메시지에서 쓰지 않으면 함께 삭제되어 이미 발급된 서명 URL로도 받을 수 없음
+func DeleteChatMessage(roomID, messageID, userID uint) (*models.ChatMessage, error) {
+	var message models.ChatMessage
+	var orphaned []models.ChatAttachment
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := requireWritableRoom(tx, roomID); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ? AND chat_room_id = ?", messageID, roomID).First(&message).Error; err != nil {
+			return ErrMessageNotFound
+		}
+
+		if message.DeletedAt != nil {
+			return ErrMessageDeleted
+		}
+
+		// 강퇴/차단된 작성자도 삭제할 수 없도록 현재 멤버인지 먼저 확인
+		var membership models.ChatRoomMember
+		if err := tx.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&membership).Error; err != nil {
+			return ErrNotRoomMember
+		}
+
+		// 시스템 메시지는 대상 사용자가 UserID로 저장되는 경우가 있으므로 admin만 삭제 가능
+		if (message.UserID != userID || message.MessageType == MessageTypeSystem) && membership.Role != "admin" {
+			return ErrNotMessageAuthor
+		}
+
+		now := time.Now()
+		if err := tx.Model(&message).Updates(map[string]interface{}{
+			"deleted_at":    now,
+			"deleted_by":    userID,
+			"attachment_id": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		// 답장이면 스레드의 답장 수 감소
+		if message.ReplyToID != nil {
+			if err := tx.Model(&models.ChatMessage{}).Where("id = ? AND reply_count > 0", *message.ReplyToID).
+				UpdateColumn("reply_count", gorm.Expr("reply_count - 1")).Error; err != nil {
+				return err
+			}
+		}
+
+		if message.AttachmentID != nil {
+			attachment, err := releaseAttachment(tx, *message.AttachmentID)
+			if err != nil {
+				return err
+			}
+			if attachment != nil {
+				orphaned = append(orphaned, *attachment)
+			}
+		}
+
+		return refreshLastMessage(tx, roomID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 커밋된 뒤에 저장소 파일 삭제
+	deleteAttachmentObjects(orphaned)
+
+	database.DB.Preload("User").Preload("Attachment").First(&message, message.ID)
+	RedactDeletedMessage(&message)
+
+	if GlobalHub != nil {
+		GlobalHub.BroadcastMessage(roomID, "message_deleted", userID, message)
+	}
+
+	return &message, nil
+}
+
+// RedactDeletedMessage 삭제된 메시지의 내용 제거 (tombstone)
+func RedactDeletedMessage(message *models.ChatMessage) {
+	if message.DeletedAt == nil {
+		return
+	}
+	message.Message = ""
+	message.FileURL = nil
+	message.Links = nil
+	message.AttachmentID = nil
+	message.Attachment = nil
+}
+
+// refreshLastMessage 채팅방의 last_message / last_message_at을 삭제되지 않은 최신 메시지로 재계산
+func refreshLastMessage(tx *gorm.DB, roomID uint) error {
+	var latest models.ChatMessage
+	err := tx.Where("chat_room_id = ? AND deleted_at IS NULL", roomID).
+		Order("created_at DESC, id DESC").
+		First(&latest).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Model(&models.ChatRoom{}).Where("id = ?", roomID).Updates(map[string]interface{}{
+			"last_message":    nil,
+			"last_message_at": nil,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&models.ChatRoom{}).Where("id = ?", roomID).Updates(map[string]interface{}{
+		"last_message":    latest.Message,
+		"last_message_at": latest.CreatedAt,
+	}).Error
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"ongi-back/database"
+	"ongi-back/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoomTypeClub 클럽 채팅방 타입
+const RoomTypeClub = "club"
+
+var (
+	ErrClubNotFound      = errors.New("club not found")
+	ErrAlreadyClubMember = errors.New("already a member of this club")
+	ErrNotClubMember     = errors.New("not a member of this club")
+)
+
+// CreateClubWithChatRoom 클럽과 클럽 채팅방을 함께 생성
+func CreateClubWithChatRoom(club *models.Club, createdBy uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(club).Error; err != nil {
+			return err
+		}
+		_, err := ensureClubChatRoom(tx, club, createdBy)
+		return err
+	})
+}
+
+// JoinClub 클럽 가입 - 클럽 멤버와 클럽 채팅방 멤버를 같은 트랜잭션에서 추가
+func JoinClub(clubID, userID uint) (*models.ClubMember, error) {
+	var member models.ClubMember
+	var chatRoom *models.ChatRoom
+	var notice *models.ChatMessage
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var club models.Club
+		if err := tx.First(&club, clubID).Error; err != nil {
+			return ErrClubNotFound
+		}
+
+		var existing models.ClubMember
+		if err := tx.Where("club_id = ? AND user_id = ?", clubID, userID).First(&existing).Error; err == nil {
+			return ErrAlreadyClubMember
+		}
+
+		member = models.ClubMember{
+			ClubID:   clubID,
+			UserID:   userID,
+			JoinedAt: time.Now(),
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Club{}).Where("id = ?", clubID).
+			UpdateColumn("member_count", gorm.Expr("member_count + 1")).Error; err != nil {
+			return err
+		}
+
+		room, err := ensureClubChatRoom(tx, &club, userID)
+		if err != nil {
+			return err
+		}
+
+		// 클럽 채팅방에서 차단된 사용자는 클럽에만 가입
+		if banned, err := isBanned(tx, room.ID, userID); err != nil || banned {
+			return err
+		}
+		chatRoom = room
+
+		if _, err := addChatRoomMember(tx, room.ID, userID); err != nil {
+			return err
+		}
+		notice, err = createSystemMessage(tx, room.ID, models.SystemPayload{
+			Event: SystemEventMemberJoin, TargetID: userID,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if chatRoom != nil {
+		broadcastMemberChange(chatRoom.ID, userID, "member_join")
+		broadcastSystemMessages(notice)
+	}
+	return &member, nil
+}
+
+// LeaveClub 클럽 탈퇴 - 클럽 멤버와 클럽 채팅방 멤버를 같은 트랜잭션에서 제거
+func LeaveClub(clubID, userID uint) error {
+	var chatRoomID, newOwnerID uint
+	var notices []*models.ChatMessage
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("club_id = ? AND user_id = ?", clubID, userID).Delete(&models.ClubMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotClubMember
+		}
+
+		if err := tx.Model(&models.Club{}).Where("id = ? AND member_count > 0", clubID).
+			UpdateColumn("member_count", gorm.Expr("member_count - 1")).Error; err != nil {
+			return err
+		}
+
+		var room models.ChatRoom
+		if err := tx.Where("club_id = ? AND room_type = ?", clubID, RoomTypeClub).First(&room).Error; err != nil {
+			// 채팅방이 없는 클럽 (backfill 전)
+			return nil
+		}
+
+		var membership models.ChatRoomMember
+		if err := tx.Where("chat_room_id = ? AND user_id = ?", room.ID, userID).First(&membership).Error; err != nil {
+			// 채팅방에서 이미 나간 경우
+			return nil
+		}
+		chatRoomID = room.ID
+
+		if err := removeChatRoomMember(tx, room.ID, userID); err != nil {
+			return err
+		}
+		notice, err := createSystemMessage(tx, room.ID, models.SystemPayload{
+			Event: SystemEventMemberLeave, TargetID: userID,
+		})
+		if err != nil {
+			return err
+		}
+		notices = append(notices, notice)
+
+		// 채팅방에서 나가는 것과 같은 방장 위임
+		newOwnerID, notice, err = handOverOwnership(tx, &room, userID)
+		notices = append(notices, notice)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if chatRoomID != 0 && GlobalHub != nil {
+		GlobalHub.BroadcastAndDisconnect(chatRoomID, "member_leave", userID, map[string]interface{}{
+			"user_id": userID,
+		}, userID)
+		if newOwnerID != 0 {
+			broadcastRoleChanged(chatRoomID, 0, newOwnerID, RoleAdmin, true)
+		}
+	}
+	broadcastSystemMessages(notices...)
+	return nil
+}
+
+// BackfillClubChatRooms 기존 클럽의 채팅방 생성 및 클럽 멤버를 채팅방 멤버로 추가
+// 여러 번 실행해도 결과가 같음 (멤버가 없는 클럽은 첫 가입 시 채팅방이 생성됨)
+func BackfillClubChatRooms() error {
+	var clubs []models.Club
+	if err := database.DB.Preload("Members").Find(&clubs).Error; err != nil {
+		return err
+	}
+
+	for i := range clubs {
+		club := &clubs[i]
+		if len(club.Members) == 0 {
+			continue
+		}
+
+		added := 0
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			room, err := ensureClubChatRoom(tx, club, club.Members[0].UserID)
+			if err != nil {
+				return err
+			}
+
+			// 이전에 만들어진 채팅방은 방장이 멤버로 추가되지 않았음
+			if banned, err := isBanned(tx, room.ID, room.CreatedBy); err != nil {
+				return err
+			} else if !banned {
+				if err := ensureRoomOwnerMember(tx, room); err != nil {
+					return err
+				}
+			}
+
+			for _, member := range club.Members {
+				ok, err := addChatRoomMember(tx, room.ID, member.UserID)
+				if err != nil {
+					return err
+				}
+				if ok {
+					added++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Club chat room synced: ClubID=%d, added=%d", club.ID, added)
+	}
+
+	return nil
+}
+
+// ensureClubChatRoom 클럽 채팅방 조회, 없으면 생성 (생성자는 admin 멤버로 추가)
+func ensureClubChatRoom(tx *gorm.DB, club *models.Club, createdBy uint) (*models.ChatRoom, error) {
+	var room models.ChatRoom
+	err := tx.Where("club_id = ? AND room_type = ?", club.ID, RoomTypeClub).First(&room).Error
+	if err == nil {
+		return &room, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	clubID := club.ID
+	room = models.ChatRoom{
+		Name:        club.Name,
+		Description: club.Description,
+		ClubID:      &clubID,
+		RoomType:    RoomTypeClub,
+		CreatedBy:   createdBy,
+	}
+	if err := tx.Create(&room).Error; err != nil {
+		return nil, err
+	}
+	if err := ensureRoomOwnerMember(tx, &room); err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// ensureRoomOwnerMember 방장을 admin 멤버로 추가 (이미 멤버면 admin으로 변경)
+func ensureRoomOwnerMember(tx *gorm.DB, room *models.ChatRoom) error {
+	var existing models.ChatRoomMember
+	if err := tx.Where("chat_room_id = ? AND user_id = ?", room.ID, room.CreatedBy).First(&existing).Error; err == nil {
+		if existing.Role == RoleAdmin {
+			return nil
+		}
+		return tx.Model(&existing).Update("role", RoleAdmin).Error
+	}
+
+	_, err := addChatRoomMemberWithRole(tx, room.ID, room.CreatedBy, RoleAdmin)
+	return err
+}
+
+// addChatRoomMember 채팅방 멤버 추가 (이미 멤버면 false)
+func addChatRoomMember(tx *gorm.DB, roomID, userID uint) (bool, error) {
+	return addChatRoomMemberWithRole(tx, roomID, userID, RoleMember)
+}
+
+// addChatRoomMemberWithRole 지정한 역할로 채팅방 멤버 추가 (이미 멤버면 false)
+func addChatRoomMemberWithRole(tx *gorm.DB, roomID, userID uint, role string) (bool, error) {
+	var existing models.ChatRoomMember
+	if err := tx.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&existing).Error; err == nil {
+		return false, nil
+	}
+
+	lastReadID, err := LatestMessageID(tx, roomID)
+	if err != nil {
+		return false, err
+	}
+
+	member := models.ChatRoomMember{
+		ChatRoomID:        roomID,
+		UserID:            userID,
+		Role:              role,
+		JoinedAt:          time.Now(),
+		LastReadMessageID: lastReadID,
+	}
+	if err := tx.Create(&member).Error; err != nil {
+		return false, err
+	}
+
+	if err := tx.Model(&models.ChatRoom{}).Where("id = ?", roomID).
+		UpdateColumn("member_count", gorm.Expr("member_count + 1")).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// removeChatRoomMember 채팅방 멤버 제거
+func removeChatRoomMember(tx *gorm.DB, roomID, userID uint) error {
+	result := tx.Where("chat_room_id = ? AND user_id = ?", roomID, userID).Delete(&models.ChatRoomMember{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	return tx.Model(&models.ChatRoom{}).Where("id = ? AND member_count > 0", roomID).
+		UpdateColumn("member_count", gorm.Expr("member_count - 1")).Error
+}
+
+// broadcastMemberChange 채팅방 멤버 변경 브로드캐스트
+func broadcastMemberChange(roomID, userID uint, eventType string) {
+	if GlobalHub == nil {
+		return
+	}
+	GlobalHub.BroadcastMessage(roomID, eventType, userID, map[string]interface{}{
+		"user_id": userID,
+	})
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"ongi-back/database"
+	"ongi-back/models"
+	"ongi-back/utils"
+	"sort"
+)
+
+// DimensionMatch 성향 차원별 일치도 ("왜 이 클럽인지" 설명용)
+type DimensionMatch struct {
+	Dimension string  `json:"dimension"`  // sociality, activity, intimacy, immersion, flexibility
+	Label     string  `json:"label"`      // 사교성, 활동성, 친밀도, 몰입도, 유연성
+	UserScore float64 `json:"user_score"` // 사용자 점수
+	ClubScore float64 `json:"club_score"` // 클럽 선호 점수
+	Match     float64 `json:"match"`      // 100 - |차이|
+}
+
+// ClubMatch 클럽 추천 결과 (클럽 정보 + 유사도 + 차원별 일치도)
+type ClubMatch struct {
+	models.Club
+	MatchScore     float64          `json:"match_score"`     // 0-100
+	MatchBreakdown []DimensionMatch `json:"match_breakdown"` // 일치도 높은 차원 순
+}
+
+// dimensionLabels 차원 이름 (Vector5D.ToSlice 순서)
+var dimensionLabels = []struct {
+	Key   string
+	Label string
+}{
+	{"sociality", "사교성"},
+	{"activity", "활동성"},
+	{"intimacy", "친밀도"},
+	{"immersion", "몰입도"},
+	{"flexibility", "유연성"},
+}
+
+// vibeVectors PreferredScores가 없는 클럽에 사용할 분위기별 기본 선호 벡터
+var vibeVectors = map[string]utils.Vector5D{
+	"energetic": {Sociality: 80, Activity: 85, Intimacy: 50, Immersion: 45, Flexibility: 70},
+	"cozy":      {Sociality: 35, Activity: 30, Intimacy: 80, Immersion: 75, Flexibility: 45},
+	"deep":      {Sociality: 40, Activity: 40, Intimacy: 70, Immersion: 90, Flexibility: 50},
+	"casual":    {Sociality: 70, Activity: 60, Intimacy: 50, Immersion: 45, Flexibility: 85},
+	"chill":     {Sociality: 45, Activity: 25, Intimacy: 65, Immersion: 55, Flexibility: 75},
+}
+
+// ClubVector 클럽의 선호 성향 벡터 (PreferredScores가 없으면 Vibe 기본값, 그것도 없으면 중립값)
+func ClubVector(club *models.Club) *utils.Vector5D {
+	if club.PreferenceVector != nil {
+		return club.PreferenceVector
+	}
+	if v, ok := vibeVectors[club.Vibe]; ok {
+		return &v
+	}
+	return &utils.Vector5D{Sociality: 50, Activity: 50, Intimacy: 50, Immersion: 50, Flexibility: 50}
+}
+
+// profileVector UserProfile 점수를 벡터로 변환
+func profileVector(profile *models.UserProfile) *utils.Vector5D {
+	return &utils.Vector5D{
+		Sociality:   profile.SocialityScore,
+		Activity:    profile.ActivityScore,
+		Intimacy:    profile.IntimacyScore,
+		Immersion:   profile.ImmersionScore,
+		Flexibility: profile.FlexibilityScore,
+	}
+}
+
+// MatchClub 사용자 벡터와 클럽 선호 벡터의 유사도 및 차원별 일치도 계산
+func MatchClub(userVector *utils.Vector5D, club models.Club) ClubMatch {
+	clubVector := ClubVector(&club)
+	userValues := userVector.ToSlice()
+	clubValues := clubVector.ToSlice()
+
+	breakdown := make([]DimensionMatch, len(dimensionLabels))
+	for i, dim := range dimensionLabels {
+		breakdown[i] = DimensionMatch{
+			Dimension: dim.Key,
+			Label:     dim.Label,
+			UserScore: userValues[i],
+			ClubScore: clubValues[i],
+			Match:     math.Round((100-math.Abs(userValues[i]-clubValues[i]))*10) / 10,
+		}
+	}
+	sort.SliceStable(breakdown, func(i, j int) bool {
+		return breakdown[i].Match > breakdown[j].Match
+	})
+
+	return ClubMatch{
+		Club:           club,
+		MatchScore:     utils.Similarity(userVector, clubVector),
+		MatchBreakdown: breakdown,
+	}
+}
+
+// RankClubs 유사도 높은 순으로 클럽 정렬 (정원이 찬 클럽 제외)
+func RankClubs(userVector *utils.Vector5D, clubs []models.Club, limit int) []ClubMatch {
+	matches := make([]ClubMatch, 0, len(clubs))
+	for _, club := range clubs {
+		if club.MaxMembers > 0 && club.MemberCount >= club.MaxMembers {
+			continue
+		}
+		matches = append(matches, MatchClub(userVector, club))
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].MatchScore != matches[j].MatchScore {
+			return matches[i].MatchScore > matches[j].MatchScore
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// GetClubMatches 회원/게스트의 성향 벡터 기준 클럽 추천 (차원별 일치도 포함)
+func GetClubMatches(subject ProfileSubject, limit int) ([]ClubMatch, error) {
+	v, err := subject.Vector()
+	if errors.Is(err, ErrNoPreferenceVector) {
+		return []ClubMatch{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rankAllClubs(v, limit)
+}
+
+// rankAllClubs 전체 클럽을 조회해 유사도 순으로 정렬
+func rankAllClubs(userVector *utils.Vector5D, limit int) ([]ClubMatch, error) {
+	var clubs []models.Club
+	if err := database.DB.Preload("Members").Find(&clubs).Error; err != nil {
+		return nil, err
+	}
+	return RankClubs(userVector, clubs, limit), nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"encoding/json"
+	"ongi-back/models"
+	"ongi-back/utils"
+	"reflect"
+	"testing"
+)
+
+func TestMatchClubBreakdownOrder(t *testing.T) {
+	user := &utils.Vector5D{Sociality: 80, Activity: 50, Intimacy: 30, Immersion: 90, Flexibility: 60}
+	club := models.Club{PreferenceVector: &utils.Vector5D{Sociality: 60, Activity: 50, Intimacy: 70, Immersion: 80, Flexibility: 50}}
+
+	match := MatchClub(user, club)
+
+	// 일치도 높은 순, 같으면 Vector5D 차원 순서 유지 (immersion, flexibility 모두 90)
+	var order []string
+	var scores []float64
+	for _, d := range match.MatchBreakdown {
+		order = append(order, d.Dimension)
+		scores = append(scores, d.Match)
+	}
+	wantOrder := []string{"activity", "immersion", "flexibility", "sociality", "intimacy"}
+	wantScores := []float64{100, 90, 90, 80, 60}
+	if !reflect.DeepEqual(order, wantOrder) || !reflect.DeepEqual(scores, wantScores) {
+		t.Fatalf("breakdown = %v %v, want %v %v", order, scores, wantOrder, wantScores)
+	}
+
+	first := match.MatchBreakdown[0]
+	if first.Label != "활동성" || first.UserScore != 50 || first.ClubScore != 50 {
+		t.Errorf("unexpected first dimension: %+v", first)
+	}
+	if want := utils.Similarity(user, club.PreferenceVector); match.MatchScore != want {
+		t.Errorf("MatchScore = %v, want %v", match.MatchScore, want)
+	}
+}
+
+func TestClubVectorFallback(t *testing.T) {
+	preferred := &utils.Vector5D{Sociality: 10, Activity: 20, Intimacy: 30, Immersion: 40, Flexibility: 50}
+
+	tests := []struct {
+		name string
+		club models.Club
+		want utils.Vector5D
+	}{
+		{"preferred scores win over vibe", models.Club{PreferenceVector: preferred, Vibe: "cozy"}, *preferred},
+		{"vibe default", models.Club{Vibe: "deep"}, vibeVectors["deep"]},
+		{"neutral when nothing is set", models.Club{Vibe: "unknown"}, utils.Vector5D{Sociality: 50, Activity: 50, Intimacy: 50, Immersion: 50, Flexibility: 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClubVector(&tt.club); *got != tt.want {
+				t.Errorf("ClubVector() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankClubs(t *testing.T) {
+	user := &utils.Vector5D{Sociality: 80, Activity: 80, Intimacy: 50, Immersion: 50, Flexibility: 70}
+	near := &utils.Vector5D{Sociality: 80, Activity: 80, Intimacy: 50, Immersion: 50, Flexibility: 70}
+	mid := &utils.Vector5D{Sociality: 60, Activity: 60, Intimacy: 50, Immersion: 50, Flexibility: 70}
+	far := &utils.Vector5D{Sociality: 20, Activity: 20, Intimacy: 90, Immersion: 90, Flexibility: 20}
+
+	club := func(id uint, v *utils.Vector5D, members, max int) models.Club {
+		c := models.Club{PreferenceVector: v, MemberCount: members, MaxMembers: max}
+		c.ID = id
+		return c
+	}
+	clubs := []models.Club{
+		club(1, far, 0, 0),
+		club(2, mid, 3, 10),
+		club(3, near, 10, 10), // 정원 마감
+		club(4, near, 11, 10), // 정원 초과
+		club(5, mid, 100, 0),  // 정원 제한 없음
+		club(6, near, 9, 10),
+	}
+
+	ids := func(matches []ClubMatch) []uint {
+		var result []uint
+		for _, m := range matches {
+			result = append(result, m.ID)
+		}
+		return result
+	}
+
+	// 정원이 찬 클럽 제외, 점수 높은 순, 동점(2, 5)은 ID 순
+	if got, want := ids(RankClubs(user, clubs, 0)), []uint{6, 2, 5, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RankClubs() = %v, want %v", got, want)
+	}
+	if got, want := ids(RankClubs(user, clubs, 2)), []uint{6, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RankClubs(limit=2) = %v, want %v", got, want)
+	}
+	if got := RankClubs(user, nil, 5); got == nil || len(got) != 0 {
+		t.Errorf("RankClubs(no clubs) = %#v, want empty slice", got)
+	}
+}
+
+// TestPreferenceVectorJSON 클럽 선호 점수는 소문자 키로 저장/파싱하고, 기존 응답의 벡터 키(SimilarProfile.vector)는 그대로 유지
+func TestPreferenceVectorJSON(t *testing.T) {
+	club := models.Club{PreferenceVector: &utils.Vector5D{Sociality: 30, Activity: 40, Intimacy: 80, Immersion: 90, Flexibility: 40}}
+	if err := club.BeforeSave(nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"sociality":30,"activity":40,"intimacy":80,"immersion":90,"flexibility":40}`; club.PreferredScores != want {
+		t.Errorf("PreferredScores = %s, want %s", club.PreferredScores, want)
+	}
+
+	loaded := models.Club{PreferredScores: club.PreferredScores}
+	if err := loaded.AfterFind(nil); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.PreferenceVector == nil || *loaded.PreferenceVector != *club.PreferenceVector {
+		t.Errorf("PreferenceVector = %+v, want %+v", loaded.PreferenceVector, club.PreferenceVector)
+	}
+
+	data, err := json.Marshal(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["preference_vector"]; ok {
+		t.Errorf("club JSON has preference_vector: %s", data)
+	}
+
+	data, err = json.Marshal(SimilarProfile{Vector: &utils.Vector5D{Sociality: 1, Activity: 2, Intimacy: 3, Immersion: 4, Flexibility: 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"similarity":0,"vector":{"Sociality":1,"Activity":2,"Intimacy":3,"Immersion":4,"Flexibility":5}}`; string(data) != want {
+		t.Errorf("SimilarProfile JSON = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"testing"
+)
+
+// createTestClub 테스트용 클럽과 클럽 채팅방 생성 (테스트가 끝나면 클럽 멤버와 함께 삭제)
+func createTestClub(t *testing.T, createdBy uint) (models.Club, models.ChatRoom) {
+	t.Helper()
+
+	club := models.Club{Name: "test club", Vibe: "cozy"}
+	if err := CreateClubWithChatRoom(&club, createdBy); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		database.DB.Where("club_id = ?", club.ID).Delete(&models.ClubMember{})
+		database.DB.Delete(&models.Club{}, club.ID)
+	})
+
+	room := clubRoom(t, club.ID)
+	cleanupRoom(t, room.ID)
+	return club, room
+}
+
+// clubRoom 클럽 채팅방 조회
+func clubRoom(t *testing.T, clubID uint) models.ChatRoom {
+	t.Helper()
+	var room models.ChatRoom
+	if err := database.DB.Where("club_id = ? AND room_type = ?", clubID, RoomTypeClub).First(&room).Error; err != nil {
+		t.Fatal(err)
+	}
+	return room
+}
+
+// isClubMember 클럽 멤버 여부
+func isClubMember(t *testing.T, clubID, userID uint) bool {
+	t.Helper()
+	var count int64
+	if err := database.DB.Model(&models.ClubMember{}).
+		Where("club_id = ? AND user_id = ?", clubID, userID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func TestCreateClubWithChatRoom(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "clubcreate", 1)
+	_, room := createTestClub(t, users[0].ID)
+
+	if room.CreatedBy != users[0].ID {
+		t.Errorf("room owner = %d, want creator %d", room.CreatedBy, users[0].ID)
+	}
+	if role := memberRole(t, room.ID, users[0].ID); role != RoleAdmin {
+		t.Errorf("creator role = %q, want admin", role)
+	}
+}
+
+func TestJoinAndLeaveClubSyncChatRoom(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "clubsync", 2)
+	owner, joiner := users[0].ID, users[1].ID
+	club, room := createTestClub(t, owner)
+
+	if _, err := JoinClub(club.ID, joiner); err != nil {
+		t.Fatal(err)
+	}
+	if !isClubMember(t, club.ID, joiner) {
+		t.Fatal("joiner is not a club member")
+	}
+	if role := memberRole(t, room.ID, joiner); role != RoleMember {
+		t.Fatalf("joiner room role = %q, want member", role)
+	}
+	if got := clubRoom(t, club.ID).MemberCount; got != 2 {
+		t.Errorf("room member_count = %d, want 2", got)
+	}
+
+	if _, err := JoinClub(club.ID, joiner); !errors.Is(err, ErrAlreadyClubMember) {
+		t.Errorf("second join: err = %v, want ErrAlreadyClubMember", err)
+	}
+	if _, err := JoinClub(club.ID+1000000, joiner); !errors.Is(err, ErrClubNotFound) {
+		t.Errorf("unknown club: err = %v, want ErrClubNotFound", err)
+	}
+
+	if err := LeaveClub(club.ID, joiner); err != nil {
+		t.Fatal(err)
+	}
+	if isClubMember(t, club.ID, joiner) {
+		t.Error("joiner is still a club member after leaving")
+	}
+	if role := memberRole(t, room.ID, joiner); role != "" {
+		t.Errorf("joiner is still a room member (%s) after leaving", role)
+	}
+	if got := clubRoom(t, club.ID).MemberCount; got != 1 {
+		t.Errorf("room member_count = %d, want 1", got)
+	}
+
+	if err := LeaveClub(club.ID, joiner); !errors.Is(err, ErrNotClubMember) {
+		t.Errorf("second leave: err = %v, want ErrNotClubMember", err)
+	}
+}
+
+func TestJoinClubSkipsBannedChatMember(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "clubban", 2)
+	owner, banned := users[0].ID, users[1].ID
+	club, room := createTestClub(t, owner)
+
+	if err := database.DB.Create(&models.ChatRoomBan{ChatRoomID: room.ID, UserID: banned, BannedBy: owner}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 채팅방에서 차단된 사용자는 클럽에만 가입
+	if _, err := JoinClub(club.ID, banned); err != nil {
+		t.Fatal(err)
+	}
+	if !isClubMember(t, club.ID, banned) {
+		t.Error("banned user did not join the club")
+	}
+	if role := memberRole(t, room.ID, banned); role != "" {
+		t.Errorf("banned user was added to the club room as %s", role)
+	}
+}
+
+func TestLeaveClubHandsOverOwnership(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "clubowner", 3)
+	owner, first, second := users[0].ID, users[1].ID, users[2].ID
+	club, room := createTestClub(t, owner)
+
+	// 채팅방 가입 순서: owner(방장, admin), first, second
+	for _, id := range []uint{owner, first, second} {
+		if _, err := JoinClub(club.ID, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := LeaveClub(club.ID, owner); err != nil {
+		t.Fatal(err)
+	}
+
+	// 다른 admin이 없으면 가장 먼저 가입한 멤버가 방장
+	if got := roomOwner(t, room.ID); got != first {
+		t.Errorf("owner = %d, want earliest member %d", got, first)
+	}
+	if role := memberRole(t, room.ID, first); role != RoleAdmin {
+		t.Errorf("new owner role = %q, want admin", role)
+	}
+	if role := memberRole(t, room.ID, owner); role != "" {
+		t.Errorf("previous owner is still a room member (%s)", role)
+	}
+}
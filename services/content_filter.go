@@ -0,0 +1,289 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrMessageTooLong = errors.New("message is too long")
+
+// DefaultMaxMessageLength 메시지 최대 길이 기본값 (글자 수)
+const DefaultMaxMessageLength = 2000
+
+// MessageContent 저장 전 필터를 거치는 메시지 내용
+type MessageContent struct {
+	RoomID uint
+	UserID uint
+	Text   string   // 필터가 수정할 수 있음 (마스킹 등)
+	Links  []string // LinkFilter가 감지한 링크
+}
+
+// ContentFilter 메시지 저장 전 실행되는 필터
+// 에러를 반환하면 메시지는 저장되지 않음
+type ContentFilter interface {
+	Filter(content *MessageContent) error
+}
+
+// ContentPipeline 필터를 순서대로 실행
+type ContentPipeline struct {
+	filters []ContentFilter
+}
+
+// NewContentPipeline 필터 파이프라인 생성
+func NewContentPipeline(filters ...ContentFilter) *ContentPipeline {
+	return &ContentPipeline{filters: filters}
+}
+
+// Run 모든 필터 실행 (첫 번째 에러에서 중단)
+func (p *ContentPipeline) Run(content *MessageContent) error {
+	for _, filter := range p.filters {
+		if err := filter.Filter(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunForEdit 속도 제한을 제외한 필터 실행 (메시지 수정은 전송 토큰을 쓰지 않음)
+func (p *ContentPipeline) RunForEdit(content *MessageContent) error {
+	for _, filter := range p.filters {
+		if _, ok := filter.(*RateLimitFilter); ok {
+			continue
+		}
+		if err := filter.Filter(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GlobalContentPipeline HTTP/WebSocket 메시지 전송과 수정에 공통으로 적용되는 파이프라인 (수정은 RunForEdit)
+var GlobalContentPipeline = DefaultContentPipeline(LoadContentFilterConfig(), defaultProfanityWords)
+
+// ContentFilterConfig 콘텐츠 필터 설정
+type ContentFilterConfig struct {
+	MaxLength     int     // 메시지 최대 길이 (글자 수)
+	RoomBurst     float64 // 채팅방별 연속 전송 허용 수
+	RoomRate      float64 // 채팅방별 초당 전송 수
+	UserBurst     float64 // 사용자 전체 연속 전송 허용 수
+	UserRate      float64 // 사용자 전체 초당 전송 수
+	ProfanityFile string  // 금칙어 목록 파일 (한 줄에 하나, 비어 있으면 기본 목록)
+}
+
+// LoadContentFilterConfig 환경 변수로 필터 설정 로드
+// CHAT_MAX_MESSAGE_LENGTH (기본값 2000), CHAT_RATE_ROOM_BURST / CHAT_RATE_ROOM_PER_SEC (5, 1),
+// CHAT_RATE_USER_BURST / CHAT_RATE_USER_PER_SEC (10, 2), CHAT_PROFANITY_FILE
+func LoadContentFilterConfig() ContentFilterConfig {
+	config := ContentFilterConfig{
+		MaxLength: DefaultMaxMessageLength,
+		RoomBurst: 5,
+		RoomRate:  1,
+		UserBurst: 10,
+		UserRate:  2,
+	}
+
+	if n, err := strconv.Atoi(os.Getenv("CHAT_MAX_MESSAGE_LENGTH")); err == nil && n > 0 {
+		config.MaxLength = n
+	}
+	for env, target := range map[string]*float64{
+		"CHAT_RATE_ROOM_BURST":   &config.RoomBurst,
+		"CHAT_RATE_ROOM_PER_SEC": &config.RoomRate,
+		"CHAT_RATE_USER_BURST":   &config.UserBurst,
+		"CHAT_RATE_USER_PER_SEC": &config.UserRate,
+	} {
+		if f, err := strconv.ParseFloat(os.Getenv(env), 64); err == nil && f > 0 {
+			*target = f
+		}
+	}
+	config.ProfanityFile = os.Getenv("CHAT_PROFANITY_FILE")
+
+	return config
+}
+
+// DefaultContentPipeline 속도 제한 → 길이 제한 → 금칙어 마스킹 → 링크 감지
+func DefaultContentPipeline(config ContentFilterConfig, profanityWords []string) *ContentPipeline {
+	return NewContentPipeline(
+		&RateLimitFilter{
+			Room: NewTokenBucketLimiter(config.RoomBurst, config.RoomRate),
+			User: NewTokenBucketLimiter(config.UserBurst, config.UserRate),
+		},
+		&LengthFilter{MaxLength: config.MaxLength},
+		NewProfanityFilter(profanityWords, defaultProfanityAllowList),
+		&LinkFilter{},
+	)
+}
+
+// InitContentPipeline 환경 변수로 콘텐츠 필터 파이프라인 초기화
+func InitContentPipeline() error {
+	config := LoadContentFilterConfig()
+
+	words := defaultProfanityWords
+	if config.ProfanityFile != "" {
+		loaded, err := loadWordList(config.ProfanityFile)
+		if err != nil {
+			return err
+		}
+		words = loaded
+	}
+
+	GlobalContentPipeline = DefaultContentPipeline(config, words)
+	log.Printf("Content filter initialized (max_length=%d, profanity_words=%d)", config.MaxLength, len(words))
+	return nil
+}
+
+// RateLimitFilter 사용자별(채팅방 단위, 전체) 토큰 버킷 속도 제한
+type RateLimitFilter struct {
+	Room *TokenBucketLimiter
+	User *TokenBucketLimiter
+}
+
+// Filter 두 버킷 중 하나라도 비어 있으면 RateLimitError
+func (f *RateLimitFilter) Filter(content *MessageContent) error {
+	if ok, wait := f.Room.Allow(fmt.Sprintf("%d:%d", content.UserID, content.RoomID)); !ok {
+		return &RateLimitError{RetryAfter: wait}
+	}
+	if ok, wait := f.User.Allow(strconv.FormatUint(uint64(content.UserID), 10)); !ok {
+		return &RateLimitError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// LengthFilter 메시지 길이 제한 (바이트가 아닌 글자 수 기준)
+type LengthFilter struct {
+	MaxLength int
+}
+
+// Filter 최대 길이를 넘으면 ErrMessageTooLong
+func (f *LengthFilter) Filter(content *MessageContent) error {
+	if utf8.RuneCountInString(content.Text) > f.MaxLength {
+		return ErrMessageTooLong
+	}
+	return nil
+}
+
+// defaultProfanityWords 기본 금칙어 목록 (CHAT_PROFANITY_FILE로 교체 가능)
+var defaultProfanityWords = []string{
+	"씨발", "시발", "ㅅㅂ", "병신", "ㅂㅅ", "개새끼", "좆", "미친놈", "지랄",
+	"fuck", "shit", "bitch", "asshole", "bastard",
+}
+
+// defaultProfanityAllowList 금칙어를 포함하지만 마스킹하지 않는 일반 단어
+var defaultProfanityAllowList = []string{
+	"시발점", "시발역", "시발택시", "시발자동차",
+}
+
+// ProfanityFilter 금칙어를 같은 길이의 *로 마스킹 (영문은 대소문자 무시)
+// 영문 금칙어는 단어 경계에서만 일치하며 (예: "shitake"는 제외),
+// 한국어는 조사가 붙어 띄어쓰기로 구분할 수 없으므로 허용 단어에 포함된 일치만 제외
+type ProfanityFilter struct {
+	pattern *regexp.Regexp
+	allowed *regexp.Regexp
+}
+
+// NewProfanityFilter 금칙어 목록과 허용 단어 목록으로 필터 생성
+func NewProfanityFilter(words, allowWords []string) *ProfanityFilter {
+	return &ProfanityFilter{
+		pattern: wordListPattern(words, true),
+		allowed: wordListPattern(allowWords, false),
+	}
+}
+
+var asciiWordPattern = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// wordListPattern 단어 목록을 대소문자 무시 정규식으로 변환 (boundaries면 영문 단어에 \b 적용, 목록이 비면 nil)
+func wordListPattern(words []string, boundaries bool) *regexp.Regexp {
+	var quoted []string
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		if boundaries && asciiWordPattern.MatchString(word) {
+			quoted = append(quoted, `\b`+regexp.QuoteMeta(word)+`\b`)
+		} else {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+}
+
+// Filter 금칙어 마스킹 (허용 단어 안의 일치는 유지)
+func (f *ProfanityFilter) Filter(content *MessageContent) error {
+	if f.pattern == nil {
+		return nil
+	}
+
+	var allowed [][]int
+	if f.allowed != nil {
+		allowed = f.allowed.FindAllStringIndex(content.Text, -1)
+	}
+	insideAllowed := func(start, end int) bool {
+		for _, span := range allowed {
+			if span[0] <= start && end <= span[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range f.pattern.FindAllStringIndex(content.Text, -1) {
+		if insideAllowed(match[0], match[1]) {
+			continue
+		}
+		b.WriteString(content.Text[last:match[0]])
+		b.WriteString(strings.Repeat("*", utf8.RuneCountInString(content.Text[match[0]:match[1]])))
+		last = match[1]
+	}
+	b.WriteString(content.Text[last:])
+	content.Text = b.String()
+	return nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+|\bwww\.[^\s<>"]+`)
+
+// maxLinksPerMessage 메시지당 저장하는 링크 수
+const maxLinksPerMessage = 10
+
+// LinkFilter 메시지의 링크 감지 (미리보기 등 클라이언트 처리용)
+type LinkFilter struct{}
+
+// Filter content.Links에 감지한 링크 저장
+func (f *LinkFilter) Filter(content *MessageContent) error {
+	content.Links = nil
+	for _, link := range linkPattern.FindAllString(content.Text, maxLinksPerMessage) {
+		content.Links = append(content.Links, strings.TrimRight(link, ".,!?)"))
+	}
+	return nil
+}
+
+// loadWordList 한 줄에 하나씩 적힌 단어 목록 파일 읽기 (#으로 시작하는 줄 무시)
+func loadWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLengthFilterCountsRunes(t *testing.T) {
+	filter := &LengthFilter{MaxLength: 5}
+
+	tests := []struct {
+		name string
+		text string
+		want error
+	}{
+		{"ascii at limit", "abcde", nil},
+		{"ascii over limit", "abcdef", ErrMessageTooLong},
+		// 한글 5자는 15바이트지만 글자 수로는 제한 이내
+		{"korean at limit", "안녕하세요", nil},
+		{"korean over limit", "안녕하세요!", ErrMessageTooLong},
+		{"emoji at limit", "😀😀😀😀😀", nil},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := filter.Filter(&MessageContent{Text: tt.text}); !errors.Is(err, tt.want) {
+				t.Errorf("Filter(%q) err = %v, want %v", tt.text, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfanityFilterMasking(t *testing.T) {
+	filter := NewProfanityFilter(defaultProfanityWords, defaultProfanityAllowList)
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"korean word", "이런 시발", "이런 **"},
+		{"korean word with particle", "병신아 뭐해", "**아 뭐해"},
+		{"english case-insensitive", "What the FUCK", "What the ****"},
+		{"english word boundary", "shitake mushrooms", "shitake mushrooms"},
+		{"english inside longer word is kept", "classic bitchin", "classic bitchin"},
+		{"english followed by punctuation", "oh shit!", "oh ****!"},
+		{"allow-listed word", "여기가 시발점이야", "여기가 시발점이야"},
+		{"allow-listed station", "시발역에서 만나", "시발역에서 만나"},
+		{"allow-listed and profane together", "시발점에서 시발", "시발점에서 **"},
+		{"several matches", "ㅅㅂ 지랄 ㅂㅅ", "** ** **"},
+		{"clean text", "안녕하세요", "안녕하세요"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := &MessageContent{Text: tt.text}
+			if err := filter.Filter(content); err != nil {
+				t.Fatal(err)
+			}
+			if content.Text != tt.want {
+				t.Errorf("Filter(%q) = %q, want %q", tt.text, content.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfanityFilterEmptyLists(t *testing.T) {
+	content := &MessageContent{Text: "시발"}
+	if err := NewProfanityFilter(nil, nil).Filter(content); err != nil || content.Text != "시발" {
+		t.Fatalf("empty word list: text=%q err=%v", content.Text, err)
+	}
+
+	content = &MessageContent{Text: "시발점"}
+	if err := NewProfanityFilter([]string{"시발", " "}, nil).Filter(content); err != nil || content.Text != "**점" {
+		t.Fatalf("no allow-list: text=%q err=%v", content.Text, err)
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"no links", "그냥 메시지", nil},
+		{"http and https", "http://a.com 그리고 https://b.com/path?q=1", []string{"http://a.com", "https://b.com/path?q=1"}},
+		{"www without scheme", "www.example.com 확인", []string{"www.example.com"}},
+		{"trailing punctuation trimmed", "여기 (https://a.com/x). 보세요!", []string{"https://a.com/x"}},
+		{"case-insensitive scheme", "HTTPS://A.COM", []string{"HTTPS://A.COM"}},
+		{"not a link", "email@example.com, ftp://a.com", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := &MessageContent{Text: tt.text}
+			if err := (&LinkFilter{}).Filter(content); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(content.Links, tt.want) {
+				t.Errorf("Links = %q, want %q", content.Links, tt.want)
+			}
+		})
+	}
+
+	content := &MessageContent{Text: strings.Repeat("https://a.com ", maxLinksPerMessage+5)}
+	(&LinkFilter{}).Filter(content)
+	if len(content.Links) != maxLinksPerMessage {
+		t.Errorf("len(Links) = %d, want %d", len(content.Links), maxLinksPerMessage)
+	}
+}
+
+func TestContentPipeline(t *testing.T) {
+	room, _ := newTestLimiter(1, 1)
+	user, _ := newTestLimiter(10, 1)
+	pipeline := NewContentPipeline(
+		&RateLimitFilter{Room: room, User: user},
+		&LengthFilter{MaxLength: 20},
+		NewProfanityFilter(defaultProfanityWords, defaultProfanityAllowList),
+		&LinkFilter{},
+	)
+
+	content := &MessageContent{RoomID: 1, UserID: 1, Text: "shit www.a.com"}
+	if err := pipeline.Run(content); err != nil {
+		t.Fatal(err)
+	}
+	if content.Text != "**** www.a.com" || !reflect.DeepEqual(content.Links, []string{"www.a.com"}) {
+		t.Fatalf("Run() = %q %q", content.Text, content.Links)
+	}
+
+	if err := pipeline.Run(&MessageContent{RoomID: 1, UserID: 1, Text: "again"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("second send: err=%v, want ErrRateLimited", err)
+	}
+
+	// 수정은 속도 제한에 걸리지 않고 토큰도 쓰지 않지만 나머지 필터는 적용
+	edit := &MessageContent{RoomID: 1, UserID: 1, Text: "edited 시발"}
+	if err := pipeline.RunForEdit(edit); err != nil {
+		t.Fatalf("RunForEdit() err = %v", err)
+	}
+	if edit.Text != "edited **" {
+		t.Errorf("RunForEdit() text = %q, want %q", edit.Text, "edited **")
+	}
+	if err := pipeline.RunForEdit(&MessageContent{RoomID: 1, UserID: 1, Text: strings.Repeat("a", 21)}); !errors.Is(err, ErrMessageTooLong) {
+		t.Errorf("RunForEdit() long text err = %v, want ErrMessageTooLong", err)
+	}
+	if user.buckets["1"].tokens != 9 {
+		t.Errorf("user tokens = %v, want 9 (edits must not consume tokens)", user.buckets["1"].tokens)
+	}
+}
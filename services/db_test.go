@@ -0,0 +1,38 @@
+package services
+
+import (
+	"ongi-back/database"
+	"os"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB TEST_DATABASE_DSN의 Postgres에 연결하고 마이그레이션 (설정되지 않으면 테스트 건너뜀)
+// 예: TEST_DATABASE_DSN="host=localhost user=postgres password=postgres dbname=ongi_test sslmode=disable"
+func openTestDB(t *testing.T) string {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect test database: %v", err)
+	}
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return dsn
+}
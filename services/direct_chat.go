@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"ongi-back/database"
+	"ongi-back/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoomTypeDirect 1:1 채팅방 타입
+const RoomTypeDirect = "direct"
+
+var (
+	ErrDirectWithSelf = errors.New("cannot create a direct chat with yourself")
+	ErrUserNotFound   = errors.New("user not found")
+)
+
+// directKey 사용자 쌍의 고유 키 (순서와 무관)
+func directKey(userA, userB uint) string {
+	if userA > userB {
+		userA, userB = userB, userA
+	}
+	return fmt.Sprintf("%d:%d", userA, userB)
+}
+
+// GetOrCreateDirectRoom 두 사용자의 1:1 채팅방을 조회하거나 생성
+// direct_key 유니크 인덱스로 동시에 요청이 들어와도 채팅방이 하나만 생성됨 (created=false면 기존 채팅방)
+// 요청한 사용자가 나갔던 채팅방이면 요청한 사용자만 다시 추가함 (나간 상대방은 본인이 다시 열 때까지 멤버가 아님)
+func GetOrCreateDirectRoom(userID, otherUserID uint) (*models.ChatRoom, bool, error) {
+	if userID == otherUserID {
+		return nil, false, ErrDirectWithSelf
+	}
+
+	var other models.User
+	if err := database.DB.First(&other, otherUserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, ErrUserNotFound
+		}
+		return nil, false, err
+	}
+
+	key := directKey(userID, otherUserID)
+	if room, err := findDirectRoom(key); err == nil {
+		if roomHasMember(room, userID) {
+			return room, false, nil
+		}
+		if err := rejoinDirectRoom(room, userID); err != nil {
+			return nil, false, err
+		}
+		room, err = findDirectRoom(key)
+		return room, false, err
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	chatRoom := models.ChatRoom{
+		RoomType:    RoomTypeDirect,
+		DirectKey:   &key,
+		CreatedBy:   userID,
+		MemberCount: 2,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&chatRoom).Error; err != nil {
+			return err
+		}
+
+		lastReadID, err := LatestMessageID(tx, chatRoom.ID)
+		if err != nil {
+			return err
+		}
+
+		members := []models.ChatRoomMember{
+			{ChatRoomID: chatRoom.ID, UserID: userID, Role: "member", JoinedAt: now, LastReadMessageID: lastReadID},
+			{ChatRoomID: chatRoom.ID, UserID: otherUserID, Role: "member", JoinedAt: now, LastReadMessageID: lastReadID},
+		}
+		return tx.Create(&members).Error
+	})
+	if err != nil {
+		// 동시에 생성된 경우 먼저 생성된 채팅방 반환
+		if room, findErr := findDirectRoom(key); findErr == nil {
+			return room, false, nil
+		}
+		return nil, false, err
+	}
+
+	room, err := findDirectRoom(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return room, true, nil
+}
+
+// rejoinDirectRoom 나갔던 사용자를 다시 추가하고, 모두 나가 보관된 채팅방이면 보관 해제
+func rejoinDirectRoom(room *models.ChatRoom, userID uint) error {
+	rejoined := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		added, err := addChatRoomMember(tx, room.ID, userID)
+		if err != nil {
+			return err
+		}
+		rejoined = added
+		if room.ArchivedAt == nil {
+			return nil
+		}
+		return tx.Model(&models.ChatRoom{}).Where("id = ?", room.ID).Update("archived_at", nil).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if rejoined {
+		broadcastMemberChange(room.ID, userID, "member_join")
+	}
+	return nil
+}
+
+// roomHasMember 조회한 채팅방의 멤버인지 확인
+func roomHasMember(room *models.ChatRoom, userID uint) bool {
+	for _, member := range room.Members {
+		if member.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// findDirectRoom direct_key로 1:1 채팅방 조회
+func findDirectRoom(key string) (*models.ChatRoom, error) {
+	var room models.ChatRoom
+	if err := database.DB.
+		Preload("Members.User").
+		Preload("Creator").
+		Where("direct_key = ?", key).
+		First(&room).Error; err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// FillDirectRoomNames 1:1 채팅방의 표시 이름을 상대방 이름으로 설정
+func FillDirectRoomNames(userID uint, rooms []models.ChatRoom) error {
+	var roomIDs []uint
+	for _, room := range rooms {
+		if room.RoomType == RoomTypeDirect {
+			roomIDs = append(roomIDs, room.ID)
+		}
+	}
+	if len(roomIDs) == 0 {
+		return nil
+	}
+
+	var counterparts []models.ChatRoomMember
+	if err := database.DB.Preload("User").
+		Where("chat_room_id IN ? AND user_id != ?", roomIDs, userID).
+		Find(&counterparts).Error; err != nil {
+		return err
+	}
+
+	names := make(map[uint]string, len(counterparts))
+	for _, member := range counterparts {
+		names[member.ChatRoomID] = member.User.Name
+	}
+
+	for i := range rooms {
+		if name, ok := names[rooms[i].ID]; ok {
+			rooms[i].DisplayName = name
+		}
+	}
+	return nil
+}
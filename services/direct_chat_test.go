@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"sync"
+	"testing"
+)
+
+// directRoomMembers 1:1 채팅방의 현재 멤버 ID
+func directRoomMembers(t *testing.T, roomID uint) map[uint]bool {
+	t.Helper()
+	var members []models.ChatRoomMember
+	if err := database.DB.Where("chat_room_id = ?", roomID).Find(&members).Error; err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[uint]bool, len(members))
+	for _, member := range members {
+		ids[member.UserID] = true
+	}
+	return ids
+}
+
+func TestGetOrCreateDirectRoomIsIdempotent(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "direct-idempotent", 2)
+	a, b := users[0].ID, users[1].ID
+
+	room, created, err := GetOrCreateDirectRoom(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanupRoom(t, room.ID)
+	if !created || room.RoomType != RoomTypeDirect || len(room.Members) != 2 {
+		t.Fatalf("first call: created=%v room=%+v", created, room)
+	}
+
+	// 순서를 바꿔 요청해도 같은 채팅방
+	again, created, err := GetOrCreateDirectRoom(b, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || again.ID != room.ID {
+		t.Fatalf("second call: created=%v id=%d, want existing room %d", created, again.ID, room.ID)
+	}
+
+	if _, _, err := GetOrCreateDirectRoom(a, a); !errors.Is(err, ErrDirectWithSelf) {
+		t.Errorf("self: err=%v, want ErrDirectWithSelf", err)
+	}
+	if _, _, err := GetOrCreateDirectRoom(a, b+1_000_000); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("unknown user: err=%v, want ErrUserNotFound", err)
+	}
+}
+
+func TestGetOrCreateDirectRoomConcurrently(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "direct-concurrent", 2)
+	a, b := users[0].ID, users[1].ID
+
+	const attempts = 8
+	rooms := make([]*models.ChatRoom, attempts)
+	created := make([]bool, attempts)
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// 양쪽 사용자가 동시에 채팅방을 여는 경우
+			if i%2 == 0 {
+				rooms[i], created[i], errs[i] = GetOrCreateDirectRoom(a, b)
+			} else {
+				rooms[i], created[i], errs[i] = GetOrCreateDirectRoom(b, a)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	createdCount := 0
+	for i := range rooms {
+		if errs[i] != nil {
+			t.Fatalf("attempt %d: %v", i, errs[i])
+		}
+		if created[i] {
+			createdCount++
+		}
+	}
+	cleanupRoom(t, rooms[0].ID)
+	if createdCount != 1 {
+		t.Fatalf("%d calls created a room, want 1", createdCount)
+	}
+	for i := range rooms {
+		if rooms[i].ID != rooms[0].ID {
+			t.Fatalf("attempt %d got room %d, want %d", i, rooms[i].ID, rooms[0].ID)
+		}
+	}
+
+	var count int64
+	database.DB.Model(&models.ChatRoom{}).Where("direct_key = ?", directKey(a, b)).Count(&count)
+	if count != 1 {
+		t.Errorf("%d direct rooms for the pair, want 1", count)
+	}
+	if members := directRoomMembers(t, rooms[0].ID); len(members) != 2 {
+		t.Errorf("members = %v, want both users once", members)
+	}
+}
+
+func TestGetOrCreateDirectRoomDoesNotReaddLeaver(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "direct-leave", 2)
+	a, b := users[0].ID, users[1].ID
+
+	room, _, err := GetOrCreateDirectRoom(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanupRoom(t, room.ID)
+
+	if _, _, err := SendChatMessage(room.ID, a, SendMessageInput{Message: "안녕"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveChatMember(room.ID, b, b); err != nil {
+		t.Fatal(err)
+	}
+
+	// 남아 있는 사용자가 다시 열어도 나간 상대방은 추가되지 않음
+	if _, _, err := GetOrCreateDirectRoom(a, b); err != nil {
+		t.Fatal(err)
+	}
+	if members := directRoomMembers(t, room.ID); !members[a] || members[b] {
+		t.Fatalf("after other user reopened: members = %v, want only %d", members, a)
+	}
+
+	// 나간 사용자가 직접 다시 열면 복구
+	if _, _, err := GetOrCreateDirectRoom(b, a); err != nil {
+		t.Fatal(err)
+	}
+	if members := directRoomMembers(t, room.ID); !members[a] || !members[b] {
+		t.Fatalf("after leaver reopened: members = %v, want both", members)
+	}
+
+	// 모두 나가면 보관되고, 다시 연 사용자만 추가하면서 보관 해제
+	for _, id := range []uint{a, b} {
+		if err := RemoveChatMember(room.ID, id, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var archived models.ChatRoom
+	if err := database.DB.First(&archived, room.ID).Error; err != nil || archived.ArchivedAt == nil {
+		t.Fatalf("room should be archived after both left: err=%v archived_at=%v", err, archived.ArchivedAt)
+	}
+
+	reopened, created, err := GetOrCreateDirectRoom(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || reopened.ID != room.ID || reopened.ArchivedAt != nil {
+		t.Fatalf("reopen archived room: created=%v id=%d archived_at=%v", created, reopened.ID, reopened.ArchivedAt)
+	}
+	if members := directRoomMembers(t, room.ID); !members[a] || members[b] {
+		t.Errorf("after reopening archived room: members = %v, want only %d", members, a)
+	}
+}
+
+func TestDirectKey(t *testing.T) {
+	if directKey(3, 12) != "3:12" || directKey(12, 3) != "3:12" {
+		t.Errorf("directKey should not depend on order: %q %q", directKey(3, 12), directKey(12, 3))
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidMembers 초대할 멤버 중 존재하지 않는 사용자가 있음
+var ErrInvalidMembers = errors.New("member_ids contains unknown users")
+
+// CreateGroupChatInput 그룹 채팅방 생성 입력
+type CreateGroupChatInput struct {
+	Name        string
+	Description string
+	ClubID      *uint
+	RoomType    string
+	MemberIDs   []uint // 초대할 멤버 (생성자와 중복 ID는 무시)
+}
+
+// CreateGroupChatRoom 채팅방, 생성자(admin)와 초대 멤버, 생성 시스템 메시지를 한 트랜잭션으로 생성
+func CreateGroupChatRoom(creatorID uint, input CreateGroupChatInput) (*models.ChatRoom, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxRoomNameLength {
+		return nil, ErrInvalidRoomName
+	}
+
+	memberIDs := uniqueMemberIDs(creatorID, input.MemberIDs)
+
+	chatRoom := models.ChatRoom{
+		Name:        name,
+		Description: input.Description,
+		ClubID:      input.ClubID,
+		RoomType:    input.RoomType,
+		CreatedBy:   creatorID,
+		MemberCount: len(memberIDs) + 1, // 생성자 포함
+	}
+
+	var notice *models.ChatMessage
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(memberIDs) > 0 {
+			var found int64
+			if err := tx.Model(&models.User{}).Where("id IN ?", memberIDs).Count(&found).Error; err != nil {
+				return err
+			}
+			if int(found) != len(memberIDs) {
+				return ErrInvalidMembers
+			}
+		}
+
+		if err := tx.Create(&chatRoom).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		members := []models.ChatRoomMember{
+			{ChatRoomID: chatRoom.ID, UserID: creatorID, Role: RoleAdmin, JoinedAt: now},
+		}
+		for _, id := range memberIDs {
+			members = append(members, models.ChatRoomMember{ChatRoomID: chatRoom.ID, UserID: id, Role: RoleMember, JoinedAt: now})
+		}
+		if err := tx.Create(&members).Error; err != nil {
+			return err
+		}
+
+		var err error
+		notice, err = createSystemMessage(tx, chatRoom.ID, models.SystemPayload{
+			Event:   SystemEventRoomCreated,
+			ActorID: creatorID,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastSystemMessages(notice)
+
+	if err := database.DB.Preload("Members.User").Preload("Creator").Preload("Club").First(&chatRoom, chatRoom.ID).Error; err != nil {
+		return nil, err
+	}
+	return &chatRoom, nil
+}
+
+// uniqueMemberIDs 0, 생성자, 중복을 제외한 멤버 ID (요청 순서 유지)
+func uniqueMemberIDs(creatorID uint, ids []uint) []uint {
+	seen := map[uint]bool{0: true, creatorID: true}
+	var unique []uint
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"ongi-back/database"
+	"ongi-back/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUniqueMemberIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []uint
+		want []uint
+	}{
+		{"empty", nil, nil},
+		{"keeps order", []uint{3, 2, 4}, []uint{3, 2, 4}},
+		{"drops duplicates", []uint{3, 3, 2, 3}, []uint{3, 2}},
+		{"drops creator and zero", []uint{1, 0, 2, 1}, []uint{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uniqueMemberIDs(1, tt.ids); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("uniqueMemberIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// createTestUsers 테스트용 사용자 생성 (테스트가 끝나면 삭제)
+func createTestUsers(t *testing.T, prefix string, n int) []models.User {
+	t.Helper()
+	suffix := time.Now().UnixNano()
+	users := make([]models.User, n)
+	for i := range users {
+		users[i] = models.User{Email: fmt.Sprintf("%s-%d-%d@example.com", prefix, i, suffix), Name: fmt.Sprintf("%s%d", prefix, i)}
+		if err := database.DB.Create(&users[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		for i := range users {
+			database.DB.Delete(&users[i])
+		}
+	})
+	return users
+}
+
+// cleanupRoom 테스트가 끝나면 채팅방과 멤버, 메시지, 관리 기록 삭제
+func cleanupRoom(t *testing.T, roomID uint) {
+	t.Helper()
+	t.Cleanup(func() {
+		messages := database.DB.Model(&models.ChatMessage{}).Select("id").Where("chat_room_id = ?", roomID)
+		database.DB.Where("chat_message_id IN (?)", messages).Delete(&models.ChatMessageReaction{})
+		database.DB.Where("chat_message_id IN (?)", messages).Delete(&models.ChatMessageEdit{})
+		database.DB.Where("chat_room_id = ?", roomID).Delete(&models.ChatMessage{})
+		database.DB.Where("chat_room_id = ?", roomID).Delete(&models.ChatRoomBan{})
+		database.DB.Where("chat_room_id = ?", roomID).Delete(&models.ChatModerationLog{})
+		database.DB.Where("chat_room_id = ?", roomID).Delete(&models.ChatRoomMember{})
+		database.DB.Delete(&models.ChatRoom{}, roomID)
+	})
+}
+
+func TestCreateGroupChatRoom(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "group", 3)
+	creator, a, b := users[0], users[1], users[2]
+
+	room, err := CreateGroupChatRoom(creator.ID, CreateGroupChatInput{
+		Name:      " 모임 ",
+		RoomType:  "group",
+		MemberIDs: []uint{a.ID, b.ID, a.ID, creator.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanupRoom(t, room.ID)
+
+	if room.Name != "모임" || room.MemberCount != 3 || len(room.Members) != 3 {
+		t.Fatalf("unexpected room: name=%q member_count=%d members=%d", room.Name, room.MemberCount, len(room.Members))
+	}
+	roles := map[uint]string{}
+	for _, member := range room.Members {
+		roles[member.UserID] = member.Role
+	}
+	if want := map[uint]string{creator.ID: RoleAdmin, a.ID: RoleMember, b.ID: RoleMember}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("roles = %v, want %v", roles, want)
+	}
+
+	var notices []models.ChatMessage
+	database.DB.Where("chat_room_id = ? AND message_type = ?", room.ID, "system").Find(&notices)
+	if len(notices) != 1 {
+		t.Errorf("system messages = %d, want 1", len(notices))
+	}
+}
+
+func TestCreateGroupChatRoomRejectsInvalidInput(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "group-invalid", 2)
+	creator := users[0]
+
+	var before int64
+	database.DB.Model(&models.ChatRoom{}).Where("created_by = ?", creator.ID).Count(&before)
+
+	missing := users[1].ID + 1_000_000
+	if _, err := CreateGroupChatRoom(creator.ID, CreateGroupChatInput{Name: "모임", MemberIDs: []uint{users[1].ID, missing}}); !errors.Is(err, ErrInvalidMembers) {
+		t.Fatalf("unknown member: err=%v, want ErrInvalidMembers", err)
+	}
+	if _, err := CreateGroupChatRoom(creator.ID, CreateGroupChatInput{Name: "  "}); !errors.Is(err, ErrInvalidRoomName) {
+		t.Fatalf("blank name: err=%v, want ErrInvalidRoomName", err)
+	}
+
+	var after int64
+	database.DB.Model(&models.ChatRoom{}).Where("created_by = ?", creator.ID).Count(&after)
+	if after != before {
+		t.Errorf("failed requests created %d chat rooms", after-before)
+	}
+}
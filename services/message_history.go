@@ -0,0 +1,287 @@
+package services
+
+import (
+	"ongi-back/database"
+	"ongi-back/models"
+)
+
+// 메시지 조회 개수 제한
+const (
+	DefaultMessageLimit = 50
+	MaxMessageLimit     = 100
+	MaxSyncLimit        = 500
+)
+
+// MessageQuery 메시지 커서 조회 조건 (BeforeID, AfterID, AroundID 중 하나만 사용)
+type MessageQuery struct {
+	BeforeID uint // 이 ID보다 이전 메시지
+	AfterID  uint // 이 ID보다 이후 메시지
+	AroundID uint // 이 ID를 포함한 앞뒤 메시지
+	Limit    int
+}
+
+// MessagePage 메시지 조회 결과 (최신순 정렬)
+type MessagePage struct {
+	Messages      []models.ChatMessage `json:"messages"`
+	HasMoreBefore bool                 `json:"has_more_before"`
+	HasMoreAfter  bool                 `json:"has_more_after"`
+}
+
+// SyncResult 재연결 동기화 결과 (오래된 순 정렬)
+type SyncResult struct {
+	Messages    []models.ChatMessage `json:"messages"`
+	HasMore     bool                 `json:"has_more"`
+	NextSinceID uint                 `json:"next_since_id"` // 다음 요청의 since_id
+}
+
+// ThreadPage 스레드 조회 결과 (답장은 오래된 순 정렬)
+type ThreadPage struct {
+	Parent  models.ChatMessage   `json:"parent"`
+	Replies []models.ChatMessage `json:"replies"`
+	HasMore bool                 `json:"has_more"` // AfterID 이후 답장이 더 있는지
+}
+
+// ListChatMessages 메시지 ID 기준 커서 페이지네이션
+// offset 대신 ID를 기준으로 조회하므로 새 메시지가 도착해도 페이지가 밀리지 않음
+func ListChatMessages(roomID, userID uint, query MessageQuery) (*MessagePage, error) {
+	limit := normalizeLimit(query.Limit, DefaultMessageLimit, MaxMessageLimit)
+	page := &MessagePage{Messages: []models.ChatMessage{}}
+
+	switch {
+	case query.AfterID != 0:
+		newer, hasMore, err := fetchNewer(roomID, query.AfterID, false, limit)
+		if err != nil {
+			return nil, err
+		}
+		page.Messages = reverseMessages(newer)
+		page.HasMoreAfter = hasMore
+		page.HasMoreBefore = hasMessage(roomID, "id <= ?", query.AfterID)
+
+	case query.AroundID != 0:
+		// 기준 메시지를 포함해 뒤쪽 절반, 앞쪽 절반
+		newer, hasMoreAfter, err := fetchNewer(roomID, query.AroundID, true, limit-limit/2)
+		if err != nil {
+			return nil, err
+		}
+		older, hasMoreBefore, err := fetchOlder(roomID, query.AroundID, limit/2)
+		if err != nil {
+			return nil, err
+		}
+		page.Messages = append(reverseMessages(newer), older...)
+		page.HasMoreAfter = hasMoreAfter
+		page.HasMoreBefore = hasMoreBefore
+
+	default:
+		older, hasMore, err := fetchOlder(roomID, query.BeforeID, limit)
+		if err != nil {
+			return nil, err
+		}
+		page.Messages = older
+		page.HasMoreBefore = hasMore
+		page.HasMoreAfter = query.BeforeID != 0 && hasMessage(roomID, "id >= ?", query.BeforeID)
+	}
+
+	for i := range page.Messages {
+		RedactDeletedMessage(&page.Messages[i])
+	}
+	if err := FillUnreadMemberCounts(roomID, page.Messages); err != nil {
+		return nil, err
+	}
+	if err := FillReactions(userID, page.Messages); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// ListThreadMessages 스레드 첫 메시지와 답장 조회 (afterID 이후 답장을 오래된 순으로)
+func ListThreadMessages(roomID, userID, messageID, afterID uint, limit int) (*ThreadPage, error) {
+	limit = normalizeLimit(limit, DefaultMessageLimit, MaxMessageLimit)
+
+	var parent models.ChatMessage
+	if err := database.DB.Preload("User").Preload("Attachment").
+		Where("id = ? AND chat_room_id = ?", messageID, roomID).First(&parent).Error; err != nil {
+		return nil, ErrMessageNotFound
+	}
+	// 답장의 스레드를 요청하면 첫 메시지 기준으로 조회
+	if parent.ReplyToID != nil {
+		return ListThreadMessages(roomID, userID, *parent.ReplyToID, afterID, limit)
+	}
+
+	var replies []models.ChatMessage
+	if err := database.DB.Preload("User").Preload("Attachment").
+		Where("chat_room_id = ? AND reply_to_id = ? AND id > ?", roomID, parent.ID, afterID).
+		Order("id ASC").
+		Limit(limit + 1).
+		Find(&replies).Error; err != nil {
+		return nil, err
+	}
+
+	page := &ThreadPage{Replies: []models.ChatMessage{}}
+	if len(replies) > limit {
+		replies = replies[:limit]
+		page.HasMore = true
+	}
+
+	messages := append([]models.ChatMessage{parent}, replies...)
+	for i := range messages {
+		RedactDeletedMessage(&messages[i])
+	}
+	if err := FillUnreadMemberCounts(roomID, messages); err != nil {
+		return nil, err
+	}
+	if err := FillReactions(userID, messages); err != nil {
+		return nil, err
+	}
+
+	page.Parent = messages[0]
+	page.Replies = append(page.Replies, messages[1:]...)
+	return page, nil
+}
+
+// SyncChatMessages 사용자가 속한 모든 채팅방에서 sinceID 이후 메시지 조회 (재연결 시 누락분 동기화)
+func SyncChatMessages(userID, sinceID uint, limit int) (*SyncResult, error) {
+	limit = normalizeLimit(limit, MaxSyncLimit, MaxSyncLimit)
+
+	var messages []models.ChatMessage
+	if err := database.DB.
+		Preload("User").
+		Preload("Attachment").
+		Where("id > ? AND chat_room_id IN (?)", sinceID,
+			database.DB.Model(&models.ChatRoomMember{}).Select("chat_room_id").Where("user_id = ?", userID)).
+		Order("id ASC").
+		Limit(limit + 1).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{NextSinceID: sinceID}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		result.HasMore = true
+	}
+	for i := range messages {
+		RedactDeletedMessage(&messages[i])
+	}
+	if err := fillSyncUnreadMemberCounts(messages); err != nil {
+		return nil, err
+	}
+	if err := FillReactions(userID, messages); err != nil {
+		return nil, err
+	}
+	if len(messages) > 0 {
+		result.NextSinceID = messages[len(messages)-1].ID
+	}
+	result.Messages = messages
+	if result.Messages == nil {
+		result.Messages = []models.ChatMessage{}
+	}
+
+	return result, nil
+}
+
+// fillSyncUnreadMemberCounts 여러 채팅방의 메시지를 채팅방별로 나눠 읽지 않은 멤버 수 계산
+func fillSyncUnreadMemberCounts(messages []models.ChatMessage) error {
+	indexes := make(map[uint][]int)
+	var roomIDs []uint
+	for i, message := range messages {
+		if _, ok := indexes[message.ChatRoomID]; !ok {
+			roomIDs = append(roomIDs, message.ChatRoomID)
+		}
+		indexes[message.ChatRoomID] = append(indexes[message.ChatRoomID], i)
+	}
+
+	for _, roomID := range roomIDs {
+		roomMessages := make([]models.ChatMessage, len(indexes[roomID]))
+		for j, i := range indexes[roomID] {
+			roomMessages[j] = messages[i]
+		}
+		if err := FillUnreadMemberCounts(roomID, roomMessages); err != nil {
+			return err
+		}
+		for j, i := range indexes[roomID] {
+			messages[i].UnreadMemberCount = roomMessages[j].UnreadMemberCount
+		}
+	}
+	return nil
+}
+
+// fetchOlder beforeID 이전 메시지를 최신순으로 조회 (beforeID가 0이면 최신 메시지부터)
+func fetchOlder(roomID, beforeID uint, limit int) ([]models.ChatMessage, bool, error) {
+	if limit <= 0 {
+		return nil, false, nil
+	}
+
+	query := database.DB.Preload("User").Preload("Attachment").Where("chat_room_id = ?", roomID)
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var messages []models.ChatMessage
+	if err := query.Order("id DESC").Limit(limit + 1).Find(&messages).Error; err != nil {
+		return nil, false, err
+	}
+
+	if len(messages) > limit {
+		return messages[:limit], true, nil
+	}
+	return messages, false, nil
+}
+
+// fetchNewer afterID 이후 메시지를 오래된 순으로 조회 (inclusive면 afterID 포함)
+func fetchNewer(roomID, afterID uint, inclusive bool, limit int) ([]models.ChatMessage, bool, error) {
+	if limit <= 0 {
+		return nil, false, nil
+	}
+
+	condition := "id > ?"
+	if inclusive {
+		condition = "id >= ?"
+	}
+
+	var messages []models.ChatMessage
+	if err := database.DB.Preload("User").Preload("Attachment").
+		Where("chat_room_id = ?", roomID).
+		Where(condition, afterID).
+		Order("id ASC").
+		Limit(limit + 1).
+		Find(&messages).Error; err != nil {
+		return nil, false, err
+	}
+
+	if len(messages) > limit {
+		return messages[:limit], true, nil
+	}
+	return messages, false, nil
+}
+
+// hasMessage 조건에 맞는 메시지가 하나라도 있는지 확인
+func hasMessage(roomID uint, condition string, id uint) bool {
+	var ids []uint
+	database.DB.Model(&models.ChatMessage{}).
+		Where("chat_room_id = ?", roomID).
+		Where(condition, id).
+		Limit(1).
+		Pluck("id", &ids)
+	return len(ids) > 0
+}
+
+// reverseMessages 정렬 순서 뒤집기
+func reverseMessages(messages []models.ChatMessage) []models.ChatMessage {
+	reversed := make([]models.ChatMessage, len(messages))
+	for i, message := range messages {
+		reversed[len(messages)-1-i] = message
+	}
+	return reversed
+}
+
+// normalizeLimit 조회 개수 보정
+func normalizeLimit(limit, defaultLimit, maxLimit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
+}
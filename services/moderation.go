@@ -0,0 +1,591 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"ongi-back/database"
+	"ongi-back/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 채팅방 역할
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+var (
+	ErrNotRoomAdmin         = errors.New("only chat room admins can do this")
+	ErrNotRoomOwner         = errors.New("only the chat room owner can do this")
+	ErrCannotModerateTarget = errors.New("not allowed to moderate this member")
+	ErrTargetNotMember      = errors.New("target user is not a member of this chat room")
+	ErrAlreadyRoomMember    = errors.New("user is already a member")
+	ErrUserBanned           = errors.New("user is banned from this chat room")
+	ErrMemberMuted          = errors.New("user is muted in this chat room")
+	ErrInvalidRole          = errors.New("invalid role")
+	ErrDirectRoomMembers    = errors.New("direct chat room members cannot be changed")
+)
+
+// AddMemberToRoom 채팅방에 멤버 추가 (admin만 가능, 차단된 사용자는 추가 불가)
+func AddMemberToRoom(roomID, actorID, targetID uint) (*models.ChatRoomMember, error) {
+	var member models.ChatRoomMember
+	var notice *models.ChatMessage
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		room, _, err := requireAdmin(tx, roomID, actorID)
+		if err != nil {
+			return err
+		}
+		if room.RoomType == RoomTypeDirect {
+			return ErrDirectRoomMembers
+		}
+		if room.ArchivedAt != nil {
+			return ErrRoomArchived
+		}
+
+		if banned, err := isBanned(tx, roomID, targetID); err != nil {
+			return err
+		} else if banned {
+			return ErrUserBanned
+		}
+
+		var existing models.ChatRoomMember
+		if err := tx.Where("chat_room_id = ? AND user_id = ?", roomID, targetID).First(&existing).Error; err == nil {
+			return ErrAlreadyRoomMember
+		}
+
+		if _, err := addChatRoomMember(tx, roomID, targetID); err != nil {
+			return err
+		}
+		notice, err = createSystemMessage(tx, roomID, models.SystemPayload{
+			Event: SystemEventMemberJoin, ActorID: actorID, TargetID: targetID,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Preload("User").Where("chat_room_id = ? AND user_id = ?", roomID, targetID).First(&member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if GlobalHub != nil {
+		GlobalHub.BroadcastMessage(roomID, "member_join", targetID, member)
+	}
+	broadcastSystemMessages(notice)
+	return &member, nil
+}
+
+// RemoveChatMember 채팅방에서 멤버 제거
+// 본인이면 나가기, 다른 사용자면 강퇴(admin 권한 필요). 방장이 나가면 방장 권한이 다른 멤버에게 넘어감
+// 1:1 채팅방은 나가기만 가능 (나간 사용자가 다시 열면 GetOrCreateDirectRoom이 그 사용자를 복구)
+// 마지막 멤버가 나가면 사용자 메시지가 있는 채팅방은 보관, 없는 채팅방은 삭제
+func RemoveChatMember(roomID, actorID, targetID uint) error {
+	kicked := actorID != targetID
+	var newOwnerID uint
+	var notices []*models.ChatMessage
+	var orphaned []models.ChatAttachment // 삭제된 채팅방의 첨부파일
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var room models.ChatRoom
+		if err := tx.First(&room, roomID).Error; err != nil {
+			return ErrChatRoomNotFound
+		}
+		if kicked && room.RoomType == RoomTypeDirect {
+			return ErrDirectRoomMembers
+		}
+		// 보관된 채팅방은 읽기 전용 (나가기는 가능)
+		if kicked && room.ArchivedAt != nil {
+			return ErrRoomArchived
+		}
+
+		target, err := findRoomMember(tx, roomID, targetID)
+		if err != nil {
+			return ErrTargetNotMember
+		}
+
+		if kicked {
+			actor, err := findRoomMember(tx, roomID, actorID)
+			if err != nil {
+				return ErrNotRoomMember
+			}
+			if err := checkModeration(&room, actor, target); err != nil {
+				return err
+			}
+			if err := writeModerationLog(tx, roomID, actorID, targetID, "kick", ""); err != nil {
+				return err
+			}
+		}
+
+		if err := removeChatRoomMember(tx, roomID, targetID); err != nil {
+			return err
+		}
+
+		// 마지막 멤버가 나가면 채팅방 보관 또는 삭제 (클럽 채팅방은 클럽 가입 시 다시 사용되므로 유지)
+		if !kicked && room.RoomType != RoomTypeClub {
+			var remaining int64
+			if err := tx.Model(&models.ChatRoomMember{}).Where("chat_room_id = ?", roomID).Count(&remaining).Error; err != nil {
+				return err
+			}
+			if remaining == 0 {
+				deleted, attachments, err := closeEmptyRoom(tx, &room)
+				if err != nil || deleted {
+					orphaned = attachments
+					return err
+				}
+			}
+		}
+
+		event := SystemEventMemberLeave
+		if kicked {
+			event = SystemEventMemberKicked
+		}
+		notice, err := createSystemMessage(tx, roomID, models.SystemPayload{
+			Event: event, ActorID: actorID, TargetID: targetID,
+		})
+		if err != nil {
+			return err
+		}
+		notices = append(notices, notice)
+
+		// 1:1 채팅방에는 방장 개념이 없음
+		if room.RoomType == RoomTypeDirect {
+			return nil
+		}
+		newOwnerID, notice, err = handOverOwnership(tx, &room, targetID)
+		notices = append(notices, notice)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// 커밋된 뒤에 저장소 파일 삭제 (롤백되면 파일이 남아 있어야 함)
+	deleteAttachmentObjects(orphaned)
+
+	if GlobalHub != nil {
+		if kicked {
+			GlobalHub.BroadcastAndDisconnect(roomID, "member_kicked", actorID, map[string]interface{}{
+				"user_id":   targetID,
+				"kicked_by": actorID,
+			}, targetID)
+		} else {
+			GlobalHub.BroadcastAndDisconnect(roomID, "member_leave", targetID, map[string]interface{}{
+				"user_id": targetID,
+			}, targetID)
+		}
+		if newOwnerID != 0 {
+			broadcastRoleChanged(roomID, 0, newOwnerID, RoleAdmin, true)
+		}
+	}
+	broadcastSystemMessages(notices...)
+	return nil
+}
+
+// ChangeMemberRole 멤버 역할 변경 (방장만 가능)
+func ChangeMemberRole(roomID, actorID, targetID uint, role string) (*models.ChatRoomMember, error) {
+	if role != RoleAdmin && role != RoleMember {
+		return nil, ErrInvalidRole
+	}
+
+	var target *models.ChatRoomMember
+	var notice *models.ChatMessage
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		room, err := requireOwner(tx, roomID, actorID)
+		if err != nil {
+			return err
+		}
+		if targetID == room.CreatedBy {
+			return ErrCannotModerateTarget
+		}
+
+		target, err = findRoomMember(tx, roomID, targetID)
+		if err != nil {
+			return ErrTargetNotMember
+		}
+
+		// 같은 역할로 변경하면 시스템 메시지 생략
+		if target.Role == role {
+			return nil
+		}
+		if err := tx.Model(target).Update("role", role).Error; err != nil {
+			return err
+		}
+		notice, err = createSystemMessage(tx, roomID, models.SystemPayload{
+			Event: SystemEventRoleChanged, ActorID: actorID, TargetID: targetID,
+			Params: map[string]string{"role": role},
+		})
+		if err != nil {
+			return err
+		}
+		return writeModerationLog(tx, roomID, actorID, targetID, "role_change", role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if notice != nil {
+		broadcastRoleChanged(roomID, actorID, targetID, role, false)
+		broadcastSystemMessages(notice)
+	}
+	return target, nil
+}
+
+// TransferOwnership 방장 위임 (방장만 가능, 새 방장은 admin이 됨)
+func TransferOwnership(roomID, actorID, targetID uint) error {
+	var notice *models.ChatMessage
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := requireOwner(tx, roomID, actorID); err != nil {
+			return err
+		}
+		if actorID == targetID {
+			return nil
+		}
+
+		target, err := findRoomMember(tx, roomID, targetID)
+		if err != nil {
+			return ErrTargetNotMember
+		}
+
+		if err := tx.Model(&models.ChatRoom{}).Where("id = ?", roomID).Update("created_by", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(target).Update("role", RoleAdmin).Error; err != nil {
+			return err
+		}
+		notice, err = createSystemMessage(tx, roomID, models.SystemPayload{
+			Event: SystemEventOwnerChanged, ActorID: actorID, TargetID: targetID,
+		})
+		if err != nil {
+			return err
+		}
+		return writeModerationLog(tx, roomID, actorID, targetID, "transfer_ownership", "")
+	})
+	if err != nil {
+		return err
+	}
+
+	if notice != nil {
+		broadcastRoleChanged(roomID, actorID, targetID, RoleAdmin, true)
+		broadcastSystemMessages(notice)
+	}
+	return nil
+}
+
+// MuteMember 멤버 음소거 (duration이 0이면 해제)
+func MuteMember(roomID, actorID, targetID uint, duration time.Duration) (*models.ChatRoomMember, error) {
+	var target *models.ChatRoomMember
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		room, actor, err := requireAdmin(tx, roomID, actorID)
+		if err != nil {
+			return err
+		}
+
+		target, err = findRoomMember(tx, roomID, targetID)
+		if err != nil {
+			return ErrTargetNotMember
+		}
+		if err := checkModeration(room, actor, target); err != nil {
+			return err
+		}
+
+		var mutedUntil *time.Time
+		action, detail := "unmute", ""
+		if duration > 0 {
+			until := time.Now().Add(duration)
+			mutedUntil = &until
+			action, detail = "mute", until.Format(time.RFC3339)
+		}
+
+		if err := tx.Model(target).Update("muted_until", mutedUntil).Error; err != nil {
+			return err
+		}
+		target.MutedUntil = mutedUntil
+		return writeModerationLog(tx, roomID, actorID, targetID, action, detail)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if GlobalHub != nil {
+		GlobalHub.BroadcastMessage(roomID, "member_muted", actorID, map[string]interface{}{
+			"user_id":     targetID,
+			"muted_until": target.MutedUntil,
+			"muted_by":    actorID,
+		})
+	}
+	return target, nil
+}
+
+// BanMember 사용자 차단 (멤버면 함께 강퇴, duration이 0이면 영구 차단)
+func BanMember(roomID, actorID, targetID uint, reason string, duration time.Duration) (*models.ChatRoomBan, error) {
+	var ban models.ChatRoomBan
+	var notice *models.ChatMessage
+	wasMember := false
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		room, actor, err := requireAdmin(tx, roomID, actorID)
+		if err != nil {
+			return err
+		}
+		if room.RoomType == RoomTypeDirect {
+			return ErrDirectRoomMembers
+		}
+		if actorID == targetID {
+			return ErrCannotModerateTarget
+		}
+
+		// 보관된 채팅방에서는 멤버를 강퇴할 수 없으므로 차단도 불가
+		if room.ArchivedAt != nil {
+			return ErrRoomArchived
+		}
+
+		if target, err := findRoomMember(tx, roomID, targetID); err == nil {
+			if err := checkModeration(room, actor, target); err != nil {
+				return err
+			}
+			if err := removeChatRoomMember(tx, roomID, targetID); err != nil {
+				return err
+			}
+			wasMember = true
+
+			notice, err = createSystemMessage(tx, roomID, models.SystemPayload{
+				Event: SystemEventMemberBanned, ActorID: actorID, TargetID: targetID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		var expiresAt *time.Time
+		if duration > 0 {
+			t := time.Now().Add(duration)
+			expiresAt = &t
+		}
+
+		// 기존 차단 기록이 있으면 갱신
+		if err := tx.Where("chat_room_id = ? AND user_id = ?", roomID, targetID).Delete(&models.ChatRoomBan{}).Error; err != nil {
+			return err
+		}
+		ban = models.ChatRoomBan{
+			ChatRoomID: roomID,
+			UserID:     targetID,
+			BannedBy:   actorID,
+			Reason:     reason,
+			ExpiresAt:  expiresAt,
+		}
+		if err := tx.Create(&ban).Error; err != nil {
+			return err
+		}
+
+		return writeModerationLog(tx, roomID, actorID, targetID, "ban", reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if GlobalHub != nil && wasMember {
+		GlobalHub.BroadcastAndDisconnect(roomID, "member_kicked", actorID, map[string]interface{}{
+			"user_id":   targetID,
+			"kicked_by": actorID,
+			"banned":    true,
+		}, targetID)
+	}
+	broadcastSystemMessages(notice)
+	return &ban, nil
+}
+
+// UnbanMember 차단 해제
+func UnbanMember(roomID, actorID, targetID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, _, err := requireAdmin(tx, roomID, actorID); err != nil {
+			return err
+		}
+
+		result := tx.Where("chat_room_id = ? AND user_id = ?", roomID, targetID).Delete(&models.ChatRoomBan{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTargetNotMember
+		}
+
+		return writeModerationLog(tx, roomID, actorID, targetID, "unban", "")
+	})
+}
+
+// ListBans 채팅방 차단 목록 (admin만 조회 가능)
+func ListBans(roomID, actorID uint) ([]models.ChatRoomBan, error) {
+	if _, _, err := requireAdmin(database.DB, roomID, actorID); err != nil {
+		return nil, err
+	}
+
+	var bans []models.ChatRoomBan
+	err := database.DB.Preload("User").
+		Where("chat_room_id = ? AND (expires_at IS NULL OR expires_at > ?)", roomID, time.Now()).
+		Order("created_at DESC").
+		Find(&bans).Error
+	return bans, err
+}
+
+// ListModerationLogs 채팅방 관리 기록 (admin만 조회 가능)
+func ListModerationLogs(roomID, actorID uint, limit int) ([]models.ChatModerationLog, error) {
+	if _, _, err := requireAdmin(database.DB, roomID, actorID); err != nil {
+		return nil, err
+	}
+
+	var logs []models.ChatModerationLog
+	err := database.DB.Where("chat_room_id = ?", roomID).
+		Order("id DESC").
+		Limit(normalizeLimit(limit, DefaultMessageLimit, MaxMessageLimit)).
+		Find(&logs).Error
+	return logs, err
+}
+
+// checkMuted 음소거 중이면 ErrMemberMuted
+func checkMuted(member *models.ChatRoomMember) error {
+	if member.MutedUntil != nil && member.MutedUntil.After(time.Now()) {
+		return fmt.Errorf("%w until %s", ErrMemberMuted, member.MutedUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// requireAdmin 요청자가 채팅방 admin인지 확인 (방장은 항상 admin)
+func requireAdmin(tx *gorm.DB, roomID, actorID uint) (*models.ChatRoom, *models.ChatRoomMember, error) {
+	var room models.ChatRoom
+	if err := tx.First(&room, roomID).Error; err != nil {
+		return nil, nil, ErrChatRoomNotFound
+	}
+
+	actor, err := findRoomMember(tx, roomID, actorID)
+	if err != nil {
+		return nil, nil, ErrNotRoomMember
+	}
+	if actor.Role != RoleAdmin && room.CreatedBy != actorID {
+		return nil, nil, ErrNotRoomAdmin
+	}
+	return &room, actor, nil
+}
+
+// requireOwner 요청자가 방장인지 확인
+func requireOwner(tx *gorm.DB, roomID, actorID uint) (*models.ChatRoom, error) {
+	var room models.ChatRoom
+	if err := tx.First(&room, roomID).Error; err != nil {
+		return nil, ErrChatRoomNotFound
+	}
+	// 나간 방장은 created_by가 남아 있어도 권한 없음
+	if _, err := findRoomMember(tx, roomID, actorID); err != nil {
+		return nil, ErrNotRoomMember
+	}
+	if room.CreatedBy != actorID {
+		return nil, ErrNotRoomOwner
+	}
+	return &room, nil
+}
+
+// checkModeration 관리 권한 확인
+// admin은 일반 멤버만, 방장은 모든 멤버를 관리할 수 있으며 방장은 누구도 관리할 수 없음
+func checkModeration(room *models.ChatRoom, actor, target *models.ChatRoomMember) error {
+	if actor.UserID == target.UserID || target.UserID == room.CreatedBy {
+		return ErrCannotModerateTarget
+	}
+	if actor.Role != RoleAdmin && room.CreatedBy != actor.UserID {
+		return ErrNotRoomAdmin
+	}
+	if target.Role == RoleAdmin && room.CreatedBy != actor.UserID {
+		return ErrCannotModerateTarget
+	}
+	return nil
+}
+
+// handOverOwnership 나간 사용자가 방장이면 방장을 위임하고 owner_changed 시스템 메시지 생성
+// 방장이 아니거나 남은 멤버가 없으면 0과 nil 반환
+func handOverOwnership(tx *gorm.DB, room *models.ChatRoom, leftUserID uint) (uint, *models.ChatMessage, error) {
+	if room.CreatedBy != leftUserID {
+		return 0, nil, nil
+	}
+
+	newOwnerID, err := transferOwnershipOnLeave(tx, room)
+	if err != nil || newOwnerID == 0 {
+		return 0, nil, err
+	}
+	notice, err := createSystemMessage(tx, room.ID, models.SystemPayload{
+		Event: SystemEventOwnerChanged, TargetID: newOwnerID,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return newOwnerID, notice, nil
+}
+
+// transferOwnershipOnLeave 방장이 나간 경우 가장 오래된 admin(없으면 가장 오래된 멤버)에게 방장 위임
+func transferOwnershipOnLeave(tx *gorm.DB, room *models.ChatRoom) (uint, error) {
+	var next models.ChatRoomMember
+	err := tx.Where("chat_room_id = ?", room.ID).
+		Order(fmt.Sprintf("CASE WHEN role = '%s' THEN 0 ELSE 1 END, joined_at ASC, id ASC", RoleAdmin)).
+		First(&next).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 남은 멤버 없음
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	previousOwner := room.CreatedBy
+	if err := tx.Model(&models.ChatRoom{}).Where("id = ?", room.ID).Update("created_by", next.UserID).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&next).Update("role", RoleAdmin).Error; err != nil {
+		return 0, err
+	}
+	if err := writeModerationLog(tx, room.ID, previousOwner, next.UserID, "transfer_ownership", "owner left"); err != nil {
+		return 0, err
+	}
+	return next.UserID, nil
+}
+
+// isBanned 차단 여부 (만료된 차단 제외)
+func isBanned(tx *gorm.DB, roomID, userID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.ChatRoomBan{}).
+		Where("chat_room_id = ? AND user_id = ? AND (expires_at IS NULL OR expires_at > ?)", roomID, userID, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// findRoomMember 채팅방 멤버 조회
+func findRoomMember(tx *gorm.DB, roomID, userID uint) (*models.ChatRoomMember, error) {
+	var member models.ChatRoomMember
+	if err := tx.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// writeModerationLog 관리 기록 저장
+func writeModerationLog(tx *gorm.DB, roomID, actorID, targetID uint, action, detail string) error {
+	return tx.Create(&models.ChatModerationLog{
+		ChatRoomID:   roomID,
+		ActorID:      actorID,
+		TargetUserID: targetID,
+		Action:       action,
+		Detail:       detail,
+	}).Error
+}
+
+// broadcastRoleChanged role_changed 이벤트 전송
+func broadcastRoleChanged(roomID, actorID, targetID uint, role string, owner bool) {
+	if GlobalHub == nil {
+		return
+	}
+	GlobalHub.BroadcastMessage(roomID, "role_changed", actorID, map[string]interface{}{
+		"user_id":    targetID,
+		"role":       role,
+		"is_owner":   owner,
+		"changed_by": actorID,
+	})
+}
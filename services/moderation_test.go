@@ -0,0 +1,344 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"testing"
+	"time"
+)
+
+// createTestRoom 테스트용 그룹 채팅방 생성 (users[0]이 방장, roles[i]는 users[i]의 역할)
+// 가입 순서가 방장 위임 순서에 영향을 주므로 JoinedAt을 목록 순서대로 1초씩 늘림
+func createTestRoom(t *testing.T, users []models.User, roles []string) models.ChatRoom {
+	t.Helper()
+
+	room := models.ChatRoom{Name: "test room", RoomType: "group", CreatedBy: users[0].ID, MemberCount: len(users)}
+	if err := database.DB.Create(&room).Error; err != nil {
+		t.Fatal(err)
+	}
+	cleanupRoom(t, room.ID)
+
+	joined := time.Now().Add(-time.Hour)
+	for i, user := range users {
+		member := models.ChatRoomMember{
+			ChatRoomID: room.ID,
+			UserID:     user.ID,
+			Role:       roles[i],
+			JoinedAt:   joined.Add(time.Duration(i) * time.Second),
+		}
+		if err := database.DB.Create(&member).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return room
+}
+
+// memberRole 채팅방 멤버의 현재 역할 (멤버가 아니면 빈 문자열)
+func memberRole(t *testing.T, roomID, userID uint) string {
+	t.Helper()
+	member, err := findRoomMember(database.DB, roomID, userID)
+	if err != nil {
+		return ""
+	}
+	return member.Role
+}
+
+// roomOwner 채팅방의 현재 방장
+func roomOwner(t *testing.T, roomID uint) uint {
+	t.Helper()
+	var room models.ChatRoom
+	if err := database.DB.First(&room, roomID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return room.CreatedBy
+}
+
+func TestModerationPermissionMatrix(t *testing.T) {
+	openTestDB(t)
+
+	// owner, admin, admin2, member, member2
+	users := createTestUsers(t, "moderation", 5)
+	roles := []string{RoleAdmin, RoleAdmin, RoleAdmin, RoleMember, RoleMember}
+	owner, admin, admin2, member, member2 := users[0].ID, users[1].ID, users[2].ID, users[3].ID, users[4].ID
+
+	// 행위자 역할 → 대상 역할 (행위자와 대상은 서로 다른 사용자)
+	pairs := []struct {
+		name          string
+		actor, target uint
+	}{
+		{"owner->admin", owner, admin},
+		{"owner->member", owner, member},
+		{"admin->owner", admin, owner},
+		{"admin->admin", admin, admin2},
+		{"admin->member", admin, member},
+		{"member->owner", member, owner},
+		{"member->admin", member, admin},
+		{"member->member", member, member2},
+	}
+
+	actions := []struct {
+		name string
+		run  func(roomID, actor, target uint) error
+		want map[string]error
+	}{
+		{
+			name: "kick",
+			run:  func(roomID, actor, target uint) error { return RemoveChatMember(roomID, actor, target) },
+			want: map[string]error{
+				"admin->owner": ErrCannotModerateTarget, "admin->admin": ErrCannotModerateTarget,
+				"member->owner": ErrCannotModerateTarget, "member->admin": ErrNotRoomAdmin, "member->member": ErrNotRoomAdmin,
+			},
+		},
+		{
+			name: "mute",
+			run: func(roomID, actor, target uint) error {
+				_, err := MuteMember(roomID, actor, target, time.Hour)
+				return err
+			},
+			want: map[string]error{
+				"admin->owner": ErrCannotModerateTarget, "admin->admin": ErrCannotModerateTarget,
+				"member->owner": ErrNotRoomAdmin, "member->admin": ErrNotRoomAdmin, "member->member": ErrNotRoomAdmin,
+			},
+		},
+		{
+			name: "ban",
+			run: func(roomID, actor, target uint) error {
+				_, err := BanMember(roomID, actor, target, "test", 0)
+				return err
+			},
+			want: map[string]error{
+				"admin->owner": ErrCannotModerateTarget, "admin->admin": ErrCannotModerateTarget,
+				"member->owner": ErrNotRoomAdmin, "member->admin": ErrNotRoomAdmin, "member->member": ErrNotRoomAdmin,
+			},
+		},
+		{
+			name: "change role",
+			// 같은 역할로 변경하면 아무 일도 없으므로 현재 역할과 반대로 변경
+			run: func(roomID, actor, target uint) error {
+				role := RoleAdmin
+				if current, err := findRoomMember(database.DB, roomID, target); err == nil && current.Role == RoleAdmin {
+					role = RoleMember
+				}
+				_, err := ChangeMemberRole(roomID, actor, target, role)
+				return err
+			},
+			want: map[string]error{
+				"admin->owner": ErrNotRoomOwner, "admin->admin": ErrNotRoomOwner, "admin->member": ErrNotRoomOwner,
+				"member->owner": ErrNotRoomOwner, "member->admin": ErrNotRoomOwner, "member->member": ErrNotRoomOwner,
+			},
+		},
+		{
+			name: "transfer ownership",
+			run:  func(roomID, actor, target uint) error { return TransferOwnership(roomID, actor, target) },
+			want: map[string]error{
+				"admin->owner": ErrNotRoomOwner, "admin->admin": ErrNotRoomOwner, "admin->member": ErrNotRoomOwner,
+				"member->owner": ErrNotRoomOwner, "member->admin": ErrNotRoomOwner, "member->member": ErrNotRoomOwner,
+			},
+		},
+	}
+
+	for _, action := range actions {
+		for _, pair := range pairs {
+			t.Run(action.name+"/"+pair.name, func(t *testing.T) {
+				room := createTestRoom(t, users, roles)
+				want := action.want[pair.name]
+
+				err := action.run(room.ID, pair.actor, pair.target)
+				if !errors.Is(err, want) {
+					t.Fatalf("err = %v, want %v", err, want)
+				}
+
+				var logs int64
+				database.DB.Model(&models.ChatModerationLog{}).Where("chat_room_id = ?", room.ID).Count(&logs)
+				if want != nil {
+					if logs != 0 {
+						t.Errorf("rejected action wrote %d moderation logs", logs)
+					}
+					if memberRole(t, room.ID, pair.target) == "" {
+						t.Error("rejected action removed the target")
+					}
+					return
+				}
+				if logs == 0 {
+					t.Error("allowed action wrote no moderation log")
+				}
+			})
+		}
+	}
+}
+
+func TestModerationOutcomes(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "moderation-outcome", 3)
+	roles := []string{RoleAdmin, RoleAdmin, RoleMember}
+	owner, admin, member := users[0].ID, users[1].ID, users[2].ID
+
+	t.Run("kick removes the member", func(t *testing.T) {
+		room := createTestRoom(t, users, roles)
+		if err := RemoveChatMember(room.ID, admin, member); err != nil {
+			t.Fatal(err)
+		}
+		if memberRole(t, room.ID, member) != "" {
+			t.Error("kicked user is still a member")
+		}
+	})
+
+	t.Run("ban removes the member and blocks re-adding", func(t *testing.T) {
+		room := createTestRoom(t, users, roles)
+		if _, err := BanMember(room.ID, admin, member, "spam", 0); err != nil {
+			t.Fatal(err)
+		}
+		if memberRole(t, room.ID, member) != "" {
+			t.Error("banned user is still a member")
+		}
+		if _, err := AddMemberToRoom(room.ID, owner, member); !errors.Is(err, ErrUserBanned) {
+			t.Errorf("re-add banned user: err=%v, want ErrUserBanned", err)
+		}
+	})
+
+	t.Run("mute sets muted_until", func(t *testing.T) {
+		room := createTestRoom(t, users, roles)
+		target, err := MuteMember(room.ID, admin, member, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if checkMuted(target) == nil {
+			t.Error("muted member can still send")
+		}
+		if target, err = MuteMember(room.ID, admin, member, 0); err != nil || checkMuted(target) != nil {
+			t.Errorf("unmute: err=%v muted_until=%v", err, target.MutedUntil)
+		}
+	})
+
+	t.Run("role change", func(t *testing.T) {
+		room := createTestRoom(t, users, roles)
+		if _, err := ChangeMemberRole(room.ID, owner, member, "owner"); !errors.Is(err, ErrInvalidRole) {
+			t.Errorf("invalid role: err=%v, want ErrInvalidRole", err)
+		}
+		if _, err := ChangeMemberRole(room.ID, owner, owner, RoleMember); !errors.Is(err, ErrCannotModerateTarget) {
+			t.Errorf("demote owner: err=%v, want ErrCannotModerateTarget", err)
+		}
+		if _, err := ChangeMemberRole(room.ID, owner, admin, RoleMember); err != nil {
+			t.Fatal(err)
+		}
+		if role := memberRole(t, room.ID, admin); role != RoleMember {
+			t.Errorf("role = %q, want member", role)
+		}
+		// 일반 멤버가 된 뒤에는 관리 권한 없음
+		if err := RemoveChatMember(room.ID, admin, member); !errors.Is(err, ErrNotRoomAdmin) {
+			t.Errorf("kick after demotion: err=%v, want ErrNotRoomAdmin", err)
+		}
+	})
+
+	t.Run("transfer ownership", func(t *testing.T) {
+		room := createTestRoom(t, users, roles)
+		if err := TransferOwnership(room.ID, owner, member); err != nil {
+			t.Fatal(err)
+		}
+		if got := roomOwner(t, room.ID); got != member {
+			t.Fatalf("owner = %d, want %d", got, member)
+		}
+		if role := memberRole(t, room.ID, member); role != RoleAdmin {
+			t.Errorf("new owner role = %q, want admin", role)
+		}
+		// 이전 방장은 admin으로 남지만 방장 권한은 없음
+		if _, err := ChangeMemberRole(room.ID, owner, admin, RoleMember); !errors.Is(err, ErrNotRoomOwner) {
+			t.Errorf("previous owner changing roles: err=%v, want ErrNotRoomOwner", err)
+		}
+		if err := RemoveChatMember(room.ID, owner, member); !errors.Is(err, ErrCannotModerateTarget) {
+			t.Errorf("kick new owner: err=%v, want ErrCannotModerateTarget", err)
+		}
+		if err := TransferOwnership(room.ID, member, owner); err != nil {
+			t.Errorf("transfer back: %v", err)
+		}
+	})
+
+	t.Run("non-member and missing target", func(t *testing.T) {
+		room := createTestRoom(t, users[:2], roles[:2])
+		if err := RemoveChatMember(room.ID, member, owner); !errors.Is(err, ErrNotRoomMember) {
+			t.Errorf("kick by non-member: err=%v, want ErrNotRoomMember", err)
+		}
+		if err := RemoveChatMember(room.ID, owner, member); !errors.Is(err, ErrTargetNotMember) {
+			t.Errorf("kick non-member: err=%v, want ErrTargetNotMember", err)
+		}
+		if _, err := MuteMember(room.ID, member, admin, time.Hour); !errors.Is(err, ErrNotRoomMember) {
+			t.Errorf("mute by non-member: err=%v, want ErrNotRoomMember", err)
+		}
+		if err := TransferOwnership(room.ID, owner, member); !errors.Is(err, ErrTargetNotMember) {
+			t.Errorf("transfer to non-member: err=%v, want ErrTargetNotMember", err)
+		}
+	})
+}
+
+func TestOwnershipHandoverOnLeave(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "moderation-handover", 4)
+	owner, firstMember, admin, lastMember := users[0].ID, users[1].ID, users[2].ID, users[3].ID
+
+	t.Run("oldest admin becomes owner", func(t *testing.T) {
+		room := createTestRoom(t, users, []string{RoleAdmin, RoleMember, RoleAdmin, RoleMember})
+		if err := RemoveChatMember(room.ID, owner, owner); err != nil {
+			t.Fatal(err)
+		}
+		if got := roomOwner(t, room.ID); got != admin {
+			t.Fatalf("owner = %d, want admin %d", got, admin)
+		}
+
+		var notice models.ChatMessage
+		err := database.DB.Where("chat_room_id = ? AND message_type = ?", room.ID, MessageTypeSystem).
+			Order("id DESC").First(&notice).Error
+		if err != nil || notice.System == nil || notice.System.Event != SystemEventOwnerChanged || notice.System.TargetID != admin {
+			t.Errorf("last system message = %+v (err=%v), want owner_changed to %d", notice.System, err, admin)
+		}
+	})
+
+	t.Run("oldest member becomes owner when there is no admin", func(t *testing.T) {
+		room := createTestRoom(t, users, []string{RoleAdmin, RoleMember, RoleMember, RoleMember})
+		if err := RemoveChatMember(room.ID, owner, owner); err != nil {
+			t.Fatal(err)
+		}
+		if got := roomOwner(t, room.ID); got != firstMember {
+			t.Fatalf("owner = %d, want %d", got, firstMember)
+		}
+		if role := memberRole(t, room.ID, firstMember); role != RoleAdmin {
+			t.Errorf("new owner role = %q, want admin", role)
+		}
+	})
+
+	t.Run("leaving member does not hand over", func(t *testing.T) {
+		room := createTestRoom(t, users, []string{RoleAdmin, RoleMember, RoleAdmin, RoleMember})
+		if err := RemoveChatMember(room.ID, lastMember, lastMember); err != nil {
+			t.Fatal(err)
+		}
+		if got := roomOwner(t, room.ID); got != owner {
+			t.Errorf("owner = %d, want %d", got, owner)
+		}
+	})
+}
+
+func TestKickBlockedInArchivedRoom(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "moderation-archived", 3)
+	owner, member, other := users[0].ID, users[1].ID, users[2].ID
+
+	room := createTestRoom(t, users, []string{RoleAdmin, RoleMember, RoleMember})
+	if err := database.DB.Model(&room).Update("archived_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RemoveChatMember(room.ID, owner, member); !errors.Is(err, ErrRoomArchived) {
+		t.Errorf("kick: err=%v, want ErrRoomArchived", err)
+	}
+	if _, err := BanMember(room.ID, owner, member, "", 0); !errors.Is(err, ErrRoomArchived) {
+		t.Errorf("ban: err=%v, want ErrRoomArchived", err)
+	}
+	if memberRole(t, room.ID, member) == "" {
+		t.Fatal("member removed from archived room")
+	}
+
+	// 나가기는 보관된 채팅방에서도 가능
+	if err := RemoveChatMember(room.ID, other, other); err != nil {
+		t.Errorf("leave archived room: %v", err)
+	}
+}
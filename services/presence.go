@@ -0,0 +1,257 @@
+package services
+
+import (
+	"log"
+	"ongi-back/database"
+	"ongi-back/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultPresenceDebounce 마지막 연결이 끊긴 후 오프라인 처리까지 대기 시간
+// 그 사이에 다시 연결되면 (새로고침, 네트워크 전환) offline/online 이벤트를 보내지 않음
+const DefaultPresenceDebounce = 5 * time.Second
+
+// PresenceTracker 채팅방별 접속 상태 관리
+// (room, user) 단위로 연결 수를 세므로 여러 탭/기기로 접속해도 하나가 끊겼을 때 오프라인이 되지 않음
+// 연결 수는 인스턴스별로 세고, 인스턴스 간 접속 여부는 PresenceStore로 공유
+type PresenceTracker struct {
+	mu       sync.Mutex
+	rooms    map[uint]map[uint]*presenceEntry // roomID -> userID -> 이 인스턴스의 상태
+	debounce time.Duration
+	store    PresenceStore
+
+	// 상태 변경 시 호출 (online=true: 접속, false: 접속 종료)
+	onChange func(roomID, userID uint, online bool)
+}
+
+// presenceEntry (room, user) 접속 상태
+type presenceEntry struct {
+	connections  int
+	offlineTimer *time.Timer
+	generation   int // 오래된 타이머 무시용
+}
+
+// PresenceSnapshot 채팅방 멤버 접속 상태
+type PresenceSnapshot struct {
+	UserID     uint       `json:"user_id"`
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+}
+
+// NewPresenceTracker PresenceTracker 생성
+func NewPresenceTracker(debounce time.Duration, onChange func(roomID, userID uint, online bool)) *PresenceTracker {
+	return &PresenceTracker{
+		rooms:    make(map[uint]map[uint]*presenceEntry),
+		debounce: debounce,
+		store:    NewMemoryPresenceStore(),
+		onChange: onChange,
+	}
+}
+
+// UseStore 접속 상태 저장소 변경 (연결을 받기 전에 호출)
+func (p *PresenceTracker) UseStore(store PresenceStore) {
+	p.store = store
+}
+
+// StartHeartbeat 주기적으로 저장소의 접속 기록을 갱신하고,
+// 갱신이 끊긴 인스턴스(종료/장애)의 사용자를 오프라인 처리
+func (p *PresenceTracker) StartHeartbeat(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			offline, err := p.store.Heartbeat(p.localKeys())
+			if err != nil {
+				log.Printf("Presence heartbeat failed: %v", err)
+			}
+			for _, key := range offline {
+				touchLastSeen(key.UserID)
+				if p.onChange != nil {
+					p.onChange(key.RoomID, key.UserID, false)
+				}
+			}
+		}
+	}()
+}
+
+// Connect 연결 추가 - 처음 접속한 경우에만 온라인 이벤트 발생
+func (p *PresenceTracker) Connect(roomID, userID uint) {
+	p.mu.Lock()
+
+	users, ok := p.rooms[roomID]
+	if !ok {
+		users = make(map[uint]*presenceEntry)
+		p.rooms[roomID] = users
+	}
+
+	entry, ok := users[userID]
+	if !ok {
+		entry = &presenceEntry{}
+		users[userID] = entry
+	}
+
+	entry.connections++
+
+	// 오프라인 대기 중에 다시 연결된 경우 (flapping) - 이벤트 없이 유지
+	wasPending := entry.offlineTimer != nil
+	if wasPending {
+		entry.offlineTimer.Stop()
+		entry.offlineTimer = nil
+	}
+
+	connected := entry.connections == 1 && !wasPending
+	p.mu.Unlock()
+
+	if !connected {
+		return
+	}
+
+	// 다른 인스턴스에서 이미 접속 중이면 이벤트 없음
+	first, err := p.store.Add(roomID, userID)
+	if err != nil {
+		log.Printf("Failed to store presence: RoomID=%d, UserID=%d, err=%v", roomID, userID, err)
+		first = true
+	}
+
+	if first && p.onChange != nil {
+		p.onChange(roomID, userID, true)
+	}
+}
+
+// Disconnect 연결 제거 - 마지막 연결이 끊기면 debounce 후 오프라인 처리
+// 이 인스턴스에서 사용자의 마지막 연결이 끊긴 경우 true
+func (p *PresenceTracker) Disconnect(roomID, userID uint) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.rooms[roomID][userID]
+	if !ok || entry.connections == 0 {
+		return false
+	}
+
+	entry.connections--
+	if entry.connections > 0 {
+		return false
+	}
+
+	entry.generation++
+	generation := entry.generation
+	entry.offlineTimer = time.AfterFunc(p.debounce, func() {
+		p.expire(roomID, userID, entry, generation)
+	})
+	return true
+}
+
+// expire debounce 이후에도 연결이 없으면 오프라인 처리
+func (p *PresenceTracker) expire(roomID, userID uint, entry *presenceEntry, generation int) {
+	p.mu.Lock()
+	current, ok := p.rooms[roomID][userID]
+	if !ok || current != entry || entry.connections > 0 || entry.generation != generation {
+		p.mu.Unlock()
+		return
+	}
+
+	delete(p.rooms[roomID], userID)
+	if len(p.rooms[roomID]) == 0 {
+		delete(p.rooms, roomID)
+	}
+	p.mu.Unlock()
+
+	// 다른 인스턴스에 연결이 남아 있으면 이벤트 없음
+	last, err := p.store.Remove(roomID, userID)
+	if err != nil {
+		log.Printf("Failed to remove presence: RoomID=%d, UserID=%d, err=%v", roomID, userID, err)
+		last = true
+	}
+	if !last {
+		return
+	}
+
+	touchLastSeen(userID)
+
+	if p.onChange != nil {
+		p.onChange(roomID, userID, false)
+	}
+}
+
+// localKeys 이 인스턴스에 연결이 있는 (room, user) 목록 (오프라인 대기 중 포함)
+func (p *PresenceTracker) localKeys() []PresenceKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var keys []PresenceKey
+	for roomID, users := range p.rooms {
+		for userID := range users {
+			keys = append(keys, PresenceKey{RoomID: roomID, UserID: userID})
+		}
+	}
+	return keys
+}
+
+// IsOnline 접속 여부 (오프라인 대기 중인 경우도 온라인으로 간주)
+func (p *PresenceTracker) IsOnline(roomID, userID uint) bool {
+	for _, id := range p.OnlineUsers(roomID) {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// OnlineUsers 채팅방의 접속 중인 사용자 ID 목록 (모든 인스턴스 기준)
+// 저장소 조회에 실패하면 이 인스턴스의 접속 상태만 반환
+func (p *PresenceTracker) OnlineUsers(roomID uint) []uint {
+	userIDs, err := p.store.OnlineUsers(roomID)
+	if err == nil {
+		return userIDs
+	}
+	log.Printf("Failed to load presence: RoomID=%d, err=%v", roomID, err)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	userIDs = make([]uint, 0, len(p.rooms[roomID]))
+	for userID := range p.rooms[roomID] {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+	return userIDs
+}
+
+// GetRoomPresence 채팅방 전체 멤버의 접속 상태 (오프라인 멤버는 last_seen_at 포함)
+func (p *PresenceTracker) GetRoomPresence(roomID uint) ([]PresenceSnapshot, error) {
+	var members []models.ChatRoomMember
+	if err := database.DB.Preload("User").Where("chat_room_id = ?", roomID).Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	online := make(map[uint]bool)
+	for _, userID := range p.OnlineUsers(roomID) {
+		online[userID] = true
+	}
+
+	snapshots := make([]PresenceSnapshot, 0, len(members))
+	for _, member := range members {
+		snapshots = append(snapshots, PresenceSnapshot{
+			UserID:     member.UserID,
+			Online:     online[member.UserID],
+			LastSeenAt: member.User.LastSeenAt,
+		})
+	}
+
+	return snapshots, nil
+}
+
+// touchLastSeen 사용자의 마지막 접속 시간 갱신
+func touchLastSeen(userID uint) {
+	if database.DB == nil {
+		return
+	}
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).
+		UpdateColumn("last_seen_at", time.Now()).Error; err != nil {
+		log.Printf("Failed to update last_seen_at: UserID=%d, err=%v", userID, err)
+	}
+}
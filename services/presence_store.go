@@ -0,0 +1,212 @@
+package services
+
+import (
+	"fmt"
+	"ongi-back/models"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultPresenceHeartbeat 인스턴스가 자신의 접속 기록을 갱신하는 주기
+	DefaultPresenceHeartbeat = 15 * time.Second
+
+	// DefaultPresenceTTL 이 시간 동안 갱신되지 않은 접속 기록은 오프라인 (인스턴스 종료/장애)
+	DefaultPresenceTTL = 45 * time.Second
+)
+
+// PresenceKey 접속 상태 키
+type PresenceKey struct {
+	RoomID uint
+	UserID uint
+}
+
+// PresenceStore 인스턴스 간에 공유하는 접속 상태 저장소
+// PresenceTracker가 인스턴스 안의 연결 수를 세고, (room, user)가 이 인스턴스에서
+// 처음 접속하거나 마지막 연결이 끊겼을 때만 저장소를 갱신
+type PresenceStore interface {
+	// Add 이 인스턴스에서 접속 중으로 기록 (다른 인스턴스에서 접속 중이 아니었으면 true)
+	Add(roomID, userID uint) (bool, error)
+	// Remove 이 인스턴스의 접속 기록 삭제 (다른 인스턴스에서도 접속 중이 아니면 true)
+	Remove(roomID, userID uint) (bool, error)
+	// OnlineUsers 어느 인스턴스에서든 접속 중인 사용자 ID 목록 (오름차순)
+	OnlineUsers(roomID uint) ([]uint, error)
+	// Heartbeat 이 인스턴스의 접속 기록(local) 갱신, 갱신이 끊긴 기록을 정리하고 그로 인해 오프라인이 된 목록 반환
+	Heartbeat(local []PresenceKey) ([]PresenceKey, error)
+}
+
+// MemoryPresenceStore 단일 인스턴스용 저장소 (기본값)
+type MemoryPresenceStore struct {
+	mu    sync.Mutex
+	rooms map[uint]map[uint]bool
+}
+
+// NewMemoryPresenceStore MemoryPresenceStore 생성
+func NewMemoryPresenceStore() *MemoryPresenceStore {
+	return &MemoryPresenceStore{rooms: make(map[uint]map[uint]bool)}
+}
+
+// Add 접속 기록 추가
+func (s *MemoryPresenceStore) Add(roomID, userID uint) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, ok := s.rooms[roomID]
+	if !ok {
+		users = make(map[uint]bool)
+		s.rooms[roomID] = users
+	}
+	first := !users[userID]
+	users[userID] = true
+	return first, nil
+}
+
+// Remove 접속 기록 삭제
+func (s *MemoryPresenceStore) Remove(roomID, userID uint) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.rooms[roomID], userID)
+	if len(s.rooms[roomID]) == 0 {
+		delete(s.rooms, roomID)
+	}
+	return true, nil
+}
+
+// OnlineUsers 접속 중인 사용자 ID 목록
+func (s *MemoryPresenceStore) OnlineUsers(roomID uint) ([]uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userIDs := make([]uint, 0, len(s.rooms[roomID]))
+	for userID := range s.rooms[roomID] {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+	return userIDs, nil
+}
+
+// Heartbeat 단일 인스턴스에서는 할 일 없음
+func (s *MemoryPresenceStore) Heartbeat(local []PresenceKey) ([]PresenceKey, error) {
+	return nil, nil
+}
+
+// PostgresPresenceStore chat_presences 테이블 기반 저장소 (다중 인스턴스용)
+// 인스턴스마다 (room, user, instance) 행을 두고 heartbeat_at을 주기적으로 갱신
+type PostgresPresenceStore struct {
+	db         *gorm.DB
+	instanceID string
+	ttl        time.Duration
+}
+
+// NewPostgresPresenceStore PostgresPresenceStore 생성
+func NewPostgresPresenceStore(db *gorm.DB, instanceID string, ttl time.Duration) *PostgresPresenceStore {
+	return &PostgresPresenceStore{db: db, instanceID: instanceID, ttl: ttl}
+}
+
+// newInstanceID 서버 인스턴스 식별자 (호스트 이름 + 랜덤 값, 재시작하면 바뀜)
+func newInstanceID() string {
+	host, _ := os.Hostname()
+	suffix, err := randomHex(4)
+	if err != nil {
+		suffix = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return host + "-" + suffix
+}
+
+// Add 이 인스턴스의 접속 기록 저장
+func (s *PostgresPresenceStore) Add(roomID, userID uint) (bool, error) {
+	now := time.Now()
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_room_id"}, {Name: "user_id"}, {Name: "instance_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"heartbeat_at": now}),
+	}).Create(&models.ChatPresence{
+		ChatRoomID:  roomID,
+		UserID:      userID,
+		InstanceID:  s.instanceID,
+		HeartbeatAt: now,
+	}).Error; err != nil {
+		return false, err
+	}
+
+	others, err := s.countLive(s.db.Where("instance_id <> ?", s.instanceID), roomID, userID)
+	return others == 0, err
+}
+
+// Remove 이 인스턴스의 접속 기록 삭제
+func (s *PostgresPresenceStore) Remove(roomID, userID uint) (bool, error) {
+	if err := s.db.Where("chat_room_id = ? AND user_id = ? AND instance_id = ?", roomID, userID, s.instanceID).
+		Delete(&models.ChatPresence{}).Error; err != nil {
+		return false, err
+	}
+
+	remaining, err := s.countLive(s.db, roomID, userID)
+	return remaining == 0, err
+}
+
+// OnlineUsers heartbeat가 살아있는 기록의 사용자 ID 목록
+func (s *PostgresPresenceStore) OnlineUsers(roomID uint) ([]uint, error) {
+	var userIDs []uint
+	err := s.db.Model(&models.ChatPresence{}).
+		Where("chat_room_id = ? AND heartbeat_at > ?", roomID, time.Now().Add(-s.ttl)).
+		Distinct("user_id").Order("user_id").Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// Heartbeat 이 인스턴스의 기록 갱신(지워진 기록은 다시 생성) 후 TTL이 지난 기록 삭제
+// 삭제는 한 인스턴스에서만 성공하므로 오프라인 이벤트는 한 번만 발생
+func (s *PostgresPresenceStore) Heartbeat(local []PresenceKey) ([]PresenceKey, error) {
+	now := time.Now()
+	if len(local) > 0 {
+		rows := make([]models.ChatPresence, len(local))
+		for i, key := range local {
+			rows[i] = models.ChatPresence{ChatRoomID: key.RoomID, UserID: key.UserID, InstanceID: s.instanceID, HeartbeatAt: now}
+		}
+		if err := s.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chat_room_id"}, {Name: "user_id"}, {Name: "instance_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"heartbeat_at": now}),
+		}).Create(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var stale []models.ChatPresence
+	if err := s.db.Clauses(clause.Returning{}).
+		Where("heartbeat_at < ?", now.Add(-s.ttl)).
+		Delete(&stale).Error; err != nil {
+		return nil, err
+	}
+
+	var offline []PresenceKey
+	seen := make(map[PresenceKey]bool)
+	for _, p := range stale {
+		key := PresenceKey{RoomID: p.ChatRoomID, UserID: p.UserID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		remaining, err := s.countLive(s.db, p.ChatRoomID, p.UserID)
+		if err != nil {
+			return offline, err
+		}
+		if remaining == 0 {
+			offline = append(offline, key)
+		}
+	}
+	return offline, nil
+}
+
+// countLive heartbeat가 살아있는 (room, user) 기록 수
+func (s *PostgresPresenceStore) countLive(query *gorm.DB, roomID, userID uint) (int64, error) {
+	var count int64
+	err := query.Model(&models.ChatPresence{}).
+		Where("chat_room_id = ? AND user_id = ? AND heartbeat_at > ?", roomID, userID, time.Now().Add(-s.ttl)).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"ongi-back/database"
+	"ongi-back/models"
+	"testing"
+	"time"
+)
+
+// TestPostgresPresenceStoreAcrossInstances 두 인스턴스에 나뉘어 접속해도 사용자 단위로 온라인/오프라인 판단
+func TestPostgresPresenceStoreAcrossInstances(t *testing.T) {
+	openTestDB(t)
+
+	const roomID, userID = 900001, 900001
+	t.Cleanup(func() {
+		database.DB.Where("chat_room_id = ?", roomID).Delete(&models.ChatPresence{})
+	})
+
+	storeA := NewPostgresPresenceStore(database.DB, "test-a", DefaultPresenceTTL)
+	storeB := NewPostgresPresenceStore(database.DB, "test-b", DefaultPresenceTTL)
+
+	if first, err := storeA.Add(roomID, userID); err != nil || !first {
+		t.Fatalf("first connection: first=%v err=%v", first, err)
+	}
+	if first, err := storeB.Add(roomID, userID); err != nil || first {
+		t.Fatalf("second instance should not be first: first=%v err=%v", first, err)
+	}
+
+	online, err := storeB.OnlineUsers(roomID)
+	if err != nil || len(online) != 1 || online[0] != userID {
+		t.Fatalf("online users = %v, err=%v", online, err)
+	}
+
+	if last, err := storeA.Remove(roomID, userID); err != nil || last {
+		t.Fatalf("user still connected to instance b: last=%v err=%v", last, err)
+	}
+	if last, err := storeB.Remove(roomID, userID); err != nil || !last {
+		t.Fatalf("last connection: last=%v err=%v", last, err)
+	}
+}
+
+// TestPostgresPresenceStoreExpiresStaleInstance heartbeat가 끊긴 인스턴스의 사용자는 한 번만 오프라인 처리
+func TestPostgresPresenceStoreExpiresStaleInstance(t *testing.T) {
+	openTestDB(t)
+
+	const roomID, userID = 900002, 900002
+	t.Cleanup(func() {
+		database.DB.Where("chat_room_id = ?", roomID).Delete(&models.ChatPresence{})
+	})
+
+	stale := models.ChatPresence{
+		ChatRoomID:  roomID,
+		UserID:      userID,
+		InstanceID:  "test-crashed",
+		HeartbeatAt: time.Now().Add(-2 * DefaultPresenceTTL),
+	}
+	if err := database.DB.Create(&stale).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewPostgresPresenceStore(database.DB, "test-alive", DefaultPresenceTTL)
+	offline, err := store.Heartbeat(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, key := range offline {
+		if key == (PresenceKey{RoomID: roomID, UserID: userID}) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("stale presence not expired: %v", offline)
+	}
+
+	if offline, _ := store.Heartbeat(nil); len(offline) != 0 {
+		for _, key := range offline {
+			if key.RoomID == roomID {
+				t.Fatalf("stale presence expired twice")
+			}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"ongi-back/database"
+	"ongi-back/models"
+	"ongi-back/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoPreferenceVector 성향 벡터가 없어 추천할 수 없음 (설문 미완료)
+var ErrNoPreferenceVector = errors.New("preference vector not found")
+
+// ProfileSubject 성향 분석 대상 (회원 또는 게스트 세션)
+// 점수 계산, 벡터 저장, 유사 프로필 검색, 추천 함수가 모두 이 타입을 받음
+type ProfileSubject struct {
+	UserID    uint   // 회원 ID (게스트면 0)
+	SessionID string // 게스트 세션 ID (회원이면 빈 값)
+}
+
+// MemberSubject 회원 분석 대상
+func MemberSubject(userID uint) ProfileSubject {
+	return ProfileSubject{UserID: userID}
+}
+
+// GuestSubject 게스트 세션 분석 대상
+func GuestSubject(sessionID string) ProfileSubject {
+	return ProfileSubject{SessionID: sessionID}
+}
+
+// IsGuest 게스트 세션이면 true
+func (s ProfileSubject) IsGuest() bool {
+	return s.UserID == 0
+}
+
+// ProfileResult 성향 분석 결과
+type ProfileResult struct {
+	Scores       *ScoreResult `json:"scores"`
+	ProfileType  string       `json:"profile_type"`
+	Descriptions []string     `json:"descriptions"`
+}
+
+// answerOptions 대상이 고른 선택지 목록
+func (s ProfileSubject) answerOptions() ([]models.Option, error) {
+	var options []models.Option
+	if s.IsGuest() {
+		var answers []models.GuestAnswer
+		if err := database.DB.Preload("Option").Where("session_id = ?", s.SessionID).Find(&answers).Error; err != nil {
+			return nil, err
+		}
+		for _, answer := range answers {
+			options = append(options, answer.Option)
+		}
+	} else {
+		var answers []models.UserAnswer
+		if err := database.DB.Preload("Option").Where("user_id = ?", s.UserID).Find(&answers).Error; err != nil {
+			return nil, err
+		}
+		for _, answer := range answers {
+			options = append(options, answer.Option)
+		}
+	}
+	return options, nil
+}
+
+// CalculateScores 대상의 답변으로 성향 점수 계산
+func CalculateScores(s ProfileSubject) (*ScoreResult, error) {
+	options, err := s.answerOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(options) == 0 {
+		if s.IsGuest() {
+			return nil, fmt.Errorf("no answers found for session")
+		}
+		return nil, fmt.Errorf("no answers found for user")
+	}
+
+	// 각 답변의 점수를 카테고리별로 분류
+	categoryScores := make(map[string][]int)
+	for _, option := range options {
+		if option.Weight != "" {
+			categoryScores[option.Weight] = append(categoryScores[option.Weight], option.Score)
+		}
+	}
+
+	// 카테고리별 평균 계산 (0-100 스케일로 변환)
+	return &ScoreResult{
+		SocialityScore:   calculateAverage(categoryScores["sociality"]),
+		ActivityScore:    calculateAverage(categoryScores["activity"]),
+		IntimacyScore:    calculateAverage(categoryScores["intimacy"]),
+		ImmersionScore:   calculateAverage(categoryScores["immersion"]),
+		FlexibilityScore: calculateAverage(categoryScores["flexibility"]),
+	}, nil
+}
+
+// AnalyzeProfile 점수 계산 → 프로필 타입/설명 생성 → 결과와 벡터 저장
+func AnalyzeProfile(s ProfileSubject) (*ProfileResult, error) {
+	scores, err := CalculateScores(s)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProfileResult{
+		Scores:       scores,
+		ProfileType:  DetermineProfileType(scores),
+		Descriptions: GenerateDescriptions(scores),
+	}
+	if err := SaveProfileResult(s, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SaveProfileResult 분석 결과 저장 (회원: UserProfile, 게스트: GuestSession + SessionVector)
+func SaveProfileResult(s ProfileSubject, result *ProfileResult) error {
+	summary := strings.Join(result.Descriptions, " ")
+
+	if s.IsGuest() {
+		if err := SaveGuestResult(s.SessionID, result.Scores, result.ProfileType, summary); err != nil {
+			return err
+		}
+		return CreateSessionVector(s.SessionID, nil, result.Scores)
+	}
+
+	profile := models.UserProfile{
+		UserID:           s.UserID,
+		SocialityScore:   result.Scores.SocialityScore,
+		ActivityScore:    result.Scores.ActivityScore,
+		IntimacyScore:    result.Scores.IntimacyScore,
+		ImmersionScore:   result.Scores.ImmersionScore,
+		FlexibilityScore: result.Scores.FlexibilityScore,
+		ProfileType:      result.ProfileType,
+		ResultSummary:    summary,
+	}
+
+	// upsert (존재하면 업데이트, 없으면 생성)
+	var existing models.UserProfile
+	var err error
+	if database.DB.Where("user_id = ?", s.UserID).First(&existing).Error == nil {
+		err = database.DB.Model(&existing).Updates(profile).Error
+	} else {
+		err = database.DB.Create(&profile).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	IndexMemberProfile(&profile)
+	return nil
+}
+
+// Vector 대상의 저장된 성향 벡터 (회원: UserProfile, 게스트: SessionVector)
+// 설문을 완료하지 않아 저장된 결과가 없으면 회원/게스트 모두 ErrNoPreferenceVector
+func (s ProfileSubject) Vector() (*utils.Vector5D, error) {
+	if !s.IsGuest() {
+		var profile models.UserProfile
+		if err := database.DB.Where("user_id = ?", s.UserID).First(&profile).Error; err != nil {
+			return nil, noPreferenceVector(err)
+		}
+		return profileVector(&profile), nil
+	}
+
+	var sessionVector models.SessionVector
+	if err := database.DB.Where("session_id = ?", s.SessionID).First(&sessionVector).Error; err != nil {
+		return nil, noPreferenceVector(err)
+	}
+	v := utils.FromSlice(sessionVector.Vector)
+	if v == nil {
+		return nil, ErrNoPreferenceVector
+	}
+	return v, nil
+}
+
+// noPreferenceVector 조회 결과가 없으면 ErrNoPreferenceVector로 변환
+func noPreferenceVector(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNoPreferenceVector
+	}
+	return err
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"log"
+	"ongi-back/database"
+	"ongi-back/models"
+	"ongi-back/utils"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 유사 프로필 검색 인덱스 (InitProfileIndex 전에는 nil이며, 이때는 요청마다 DB에서 읽어 전체 비교)
+var (
+	MemberProfileIndex utils.VectorIndex // 회원 프로필 (키: user_id)
+	SessionVectorIndex utils.VectorIndex // 게스트 세션 벡터 (키: session_id)
+)
+
+// DefaultProfileIndexRefresh 메모리 인덱스를 DB와 맞추는 기본 주기 (PROFILE_INDEX_REFRESH로 변경)
+const DefaultProfileIndexRefresh = 30 * time.Second
+
+// newVectorIndex 메모리 인덱스 생성 (kdtree 기본, bruteforce)
+func newVectorIndex(indexType string) utils.VectorIndex {
+	if indexType == "bruteforce" {
+		return utils.NewBruteForceIndex()
+	}
+	return utils.NewKDTree()
+}
+
+// InitProfileIndex 유사 프로필 검색 인덱스 초기화 (kdtree, bruteforce, pgvector)
+// 메모리 인덱스는 회원 프로필과 세션 벡터를 적재하고, 이 인스턴스의 변경은 IndexMemberProfile / IndexSessionVector로 즉시,
+// 다른 인스턴스의 변경은 주기적인 DB 동기화(PROFILE_INDEX_REFRESH, 기본 30초)로 반영
+// pgvector는 DB에서 검색하며 (회원은 PGVECTOR_METRIC: l2 기본, cosine / 게스트는 항상 cosine), 확장을 쓸 수 없으면 kdtree로 대체
+func InitProfileIndex(indexType string) error {
+	StopProfileIndex()
+
+	if indexType == "pgvector" {
+		metric := os.Getenv("PGVECTOR_METRIC")
+		if err := database.EnablePgvector(metric); err != nil {
+			log.Printf("Failed to enable pgvector, falling back to kdtree: %v", err)
+			indexType = "kdtree"
+		} else {
+			// 게스트는 metric과 관계없이 코사인 유사도 기준
+			sessions := NewPgvectorIndex("session_vectors", "session_id", "cosine")
+			sessions.SoftDelete = true
+			members := NewPgvectorIndex("user_profiles", "user_id", metric)
+			MemberProfileIndex = members
+			SessionVectorIndex = sessions
+			log.Printf("Profile index initialized (pgvector, metric=%s)", members.Metric)
+			return nil
+		}
+	}
+
+	refresher := &profileIndexRefresher{
+		members:  newVectorIndex(indexType),
+		sessions: newVectorIndex(indexType),
+	}
+	if err := refresher.refresh(); err != nil {
+		return err
+	}
+
+	MemberProfileIndex = refresher.members
+	SessionVectorIndex = refresher.sessions
+	log.Printf("Profile index initialized (%s, members=%d, sessions=%d)",
+		indexType, refresher.members.Len(), refresher.sessions.Len())
+
+	stopProfileIndexRefresh = refresher.start(profileIndexRefreshInterval())
+	return nil
+}
+
+// stopProfileIndexRefresh 실행 중인 메모리 인덱스 동기화 중지 (pgvector거나 초기화 전이면 nil)
+var stopProfileIndexRefresh func()
+
+// StopProfileIndex 메모리 인덱스의 주기적인 DB 동기화 중지 (다시 InitProfileIndex를 호출하기 전이나 종료 시)
+func StopProfileIndex() {
+	if stopProfileIndexRefresh != nil {
+		stopProfileIndexRefresh()
+		stopProfileIndexRefresh = nil
+	}
+}
+
+// profileIndexRefreshInterval PROFILE_INDEX_REFRESH (예: 30s)
+func profileIndexRefreshInterval() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("PROFILE_INDEX_REFRESH")); err == nil && d > 0 {
+		return d
+	}
+	return DefaultProfileIndexRefresh
+}
+
+// profileIndexRefresher 다른 인스턴스가 저장/삭제한 프로필을 메모리 인덱스에 반영
+// updated_at 기준 마지막으로 읽은 시각(high-water mark) 이후 변경분만 다시 읽고,
+// 삭제는 소프트 삭제된 세션 벡터의 deleted_at 기준으로 같은 방식으로 반영 (회원 프로필은 삭제되지 않고 갱신만 됨)
+type profileIndexRefresher struct {
+	members     utils.VectorIndex
+	sessions    utils.VectorIndex
+	memberMark  time.Time
+	sessionMark time.Time
+	deleteMark  time.Time
+}
+
+// profileIndexRefreshOverlap 커밋이 늦게 보이는 행을 놓치지 않도록 mark보다 앞당겨 읽는 구간
+const profileIndexRefreshOverlap = 5 * time.Second
+
+// start 주기적으로 refresh 실행 (반환한 함수로 중지하며, 진행 중인 refresh가 끝날 때까지 기다림)
+func (r *profileIndexRefresher) start(interval time.Duration) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := r.refresh(); err != nil {
+					log.Printf("Profile index refresh failed: %v", err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+		})
+	}
+}
+
+// refresh mark 이후 변경된 프로필/세션 벡터를 반영하고 삭제된 세션 벡터 제거 (첫 호출은 전체 적재)
+func (r *profileIndexRefresher) refresh() error {
+	mark, err := loadMemberIndex(r.members, refreshSince(r.memberMark))
+	if err != nil {
+		return err
+	}
+	if mark.After(r.memberMark) {
+		r.memberMark = mark
+	}
+
+	mark, err = loadSessionIndex(r.sessions, refreshSince(r.sessionMark))
+	if err != nil {
+		return err
+	}
+	if mark.After(r.sessionMark) {
+		r.sessionMark = mark
+	}
+
+	mark, err = unloadDeletedSessions(r.sessions, refreshSince(r.deleteMark))
+	if err != nil {
+		return err
+	}
+	if mark.After(r.deleteMark) {
+		r.deleteMark = mark
+	}
+	return nil
+}
+
+// refreshSince mark에서 overlap만큼 앞당긴 조회 시작 시각 (mark가 없으면 전체)
+func refreshSince(mark time.Time) time.Time {
+	if mark.IsZero() {
+		return mark
+	}
+	return mark.Add(-profileIndexRefreshOverlap)
+}
+
+// unloadDeletedSessions since 이후 소프트 삭제된 세션 벡터를 인덱스에서 제거 (가장 늦은 deleted_at 반환)
+// 세션 ID는 다시 사용되지 않으므로 삭제된 키가 이후에 다시 저장되는 경우는 없음
+func unloadDeletedSessions(index utils.VectorIndex, since time.Time) (time.Time, error) {
+	var vectors []models.SessionVector
+	query := database.DB.Unscoped().Select("session_id", "deleted_at").Where("deleted_at IS NOT NULL")
+	if !since.IsZero() {
+		query = query.Where("deleted_at > ?", since)
+	}
+	if err := query.Find(&vectors).Error; err != nil {
+		return since, err
+	}
+
+	mark := since
+	for _, sv := range vectors {
+		index.Remove(sv.SessionID)
+		if sv.DeletedAt.Time.After(mark) {
+			mark = sv.DeletedAt.Time
+		}
+	}
+	return mark, nil
+}
+
+// loadMemberIndex since 이후 변경된 회원 프로필을 인덱스에 추가 (가장 늦은 updated_at 반환)
+func loadMemberIndex(index utils.VectorIndex, since time.Time) (time.Time, error) {
+	var profiles []models.UserProfile
+	query := database.DB
+	if !since.IsZero() {
+		query = query.Where("updated_at > ?", since)
+	}
+	if err := query.Find(&profiles).Error; err != nil {
+		return since, err
+	}
+
+	mark := since
+	for i := range profiles {
+		index.Upsert(memberIndexKey(profiles[i].UserID), *profileVector(&profiles[i]))
+		if profiles[i].UpdatedAt.After(mark) {
+			mark = profiles[i].UpdatedAt
+		}
+	}
+	return mark, nil
+}
+
+// loadSessionIndex since 이후 변경된 세션 벡터를 인덱스에 추가 (가장 늦은 updated_at 반환)
+func loadSessionIndex(index utils.VectorIndex, since time.Time) (time.Time, error) {
+	var vectors []models.SessionVector
+	query := database.DB.Select("session_id", "vector", "updated_at")
+	if !since.IsZero() {
+		query = query.Where("updated_at > ?", since)
+	}
+	if err := query.Find(&vectors).Error; err != nil {
+		return since, err
+	}
+
+	mark := since
+	for _, sv := range vectors {
+		if v := utils.FromSlice(sv.Vector); v != nil {
+			index.Upsert(sv.SessionID, sessionIndexVector(v))
+		}
+		if sv.UpdatedAt.After(mark) {
+			mark = sv.UpdatedAt
+		}
+	}
+	return mark, nil
+}
+
+func memberIndexKey(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
+
+// IndexMemberProfile 회원 프로필 저장 후 인덱스 갱신
+func IndexMemberProfile(profile *models.UserProfile) {
+	if MemberProfileIndex != nil {
+		MemberProfileIndex.Upsert(memberIndexKey(profile.UserID), *profileVector(profile))
+	}
+}
+
+// IndexSessionVector 세션 벡터 저장 후 인덱스 갱신
+func IndexSessionVector(sessionID string, v *utils.Vector5D) {
+	if SessionVectorIndex != nil && v != nil {
+		SessionVectorIndex.Upsert(sessionID, sessionIndexVector(v))
+	}
+}
+
+// sessionIndexVector 게스트 메모리 인덱스에 넣는 벡터 (단위 벡터)
+// 게스트는 코사인 유사도 순으로 비교하며, 단위 벡터 사이의 유클리드 거리는 코사인 유사도가 클수록 작아지므로
+// 정규화해 넣으면 k-d 트리의 거리 순서가 코사인 유사도 순서와 같음 (응답 벡터와 점수는 resolveNeighbors/GetSimilarProfiles에서 원래 벡터로 계산)
+func sessionIndexVector(v *utils.Vector5D) utils.Vector5D {
+	return *v.Normalize()
+}
+
+// unindexSessions 삭제된 세션을 인덱스에서 제거
+func unindexSessions(sessionIDs []string) {
+	if SessionVectorIndex == nil {
+		return
+	}
+	for _, id := range sessionIDs {
+		SessionVectorIndex.Remove(id)
+	}
+}
+
+// profileIndex 대상이 속한 검색 인덱스 (초기화 전이면 DB에서 읽어 임시 전체 비교 인덱스 생성)
+func (s ProfileSubject) profileIndex() (utils.VectorIndex, error) {
+	if s.IsGuest() {
+		if SessionVectorIndex != nil {
+			return SessionVectorIndex, nil
+		}
+		index := utils.NewBruteForceIndex()
+		_, err := loadSessionIndex(index, time.Time{})
+		return index, err
+	}
+
+	if MemberProfileIndex != nil {
+		return MemberProfileIndex, nil
+	}
+	index := utils.NewBruteForceIndex()
+	_, err := loadMemberIndex(index, time.Time{})
+	return index, err
+}
+
+// indexKey 인덱스에서 대상 자신을 가리키는 키
+func (s ProfileSubject) indexKey() string {
+	if s.IsGuest() {
+		return s.SessionID
+	}
+	return memberIndexKey(s.UserID)
+}
+
+// resolveNeighbors 검색 결과에 회원/세션 정보를 붙여 SimilarProfile로 변환 (조회는 종류별 한 번)
+func (s ProfileSubject) resolveNeighbors(neighbors []utils.Neighbor) ([]SimilarProfile, error) {
+	profiles := make([]SimilarProfile, len(neighbors))
+	userIDs := make([]uint, 0, len(neighbors))
+
+	if s.IsGuest() {
+		sessionIDs := make([]string, len(neighbors))
+		for i, n := range neighbors {
+			sessionIDs[i] = n.ID
+		}
+
+		var vectors []models.SessionVector
+		if len(sessionIDs) > 0 {
+			if err := database.DB.Select("session_id", "user_id", "vector").
+				Where("session_id IN ?", sessionIDs).Find(&vectors).Error; err != nil {
+				return nil, err
+			}
+		}
+		owners := make(map[string]*uint, len(vectors))
+		originals := make(map[string]*utils.Vector5D, len(vectors))
+		for _, sv := range vectors {
+			owners[sv.SessionID] = sv.UserID
+			originals[sv.SessionID] = utils.FromSlice(sv.Vector)
+			if sv.UserID != nil {
+				userIDs = append(userIDs, *sv.UserID)
+			}
+		}
+
+		for i, n := range neighbors {
+			// 메모리 인덱스의 벡터는 정규화된 값이므로 저장된 원래 벡터로 응답
+			profiles[i] = SimilarProfile{SessionID: n.ID, UserID: owners[n.ID], Vector: originals[n.ID]}
+		}
+	} else {
+		for i, n := range neighbors {
+			id, err := strconv.ParseUint(n.ID, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			userID := uint(id)
+			profiles[i] = SimilarProfile{UserID: &userID}
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	users := make(map[uint]*models.User, len(userIDs))
+	if len(userIDs) > 0 {
+		var found []models.User
+		if err := database.DB.Where("id IN ?", userIDs).Find(&found).Error; err != nil {
+			return nil, err
+		}
+		for i := range found {
+			users[found[i].ID] = &found[i]
+		}
+	}
+
+	for i, n := range neighbors {
+		if profiles[i].Vector == nil {
+			v := n.Vector
+			profiles[i].Vector = &v
+		}
+		profiles[i].Similarity = n.Similarity
+		if profiles[i].UserID != nil {
+			profiles[i].User = users[*profiles[i].UserID]
+		}
+	}
+	return profiles, nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"ongi-back/database"
+	"ongi-back/utils"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// PgvectorIndex pgvector embedding 컬럼으로 DB에서 최근접 이웃 검색
+// embedding은 생성 컬럼이라 저장 시 DB가 갱신하므로 Upsert/Remove는 아무 것도 하지 않음
+type PgvectorIndex struct {
+	Table      string // session_vectors, user_profiles
+	KeyColumn  string // session_id, user_id
+	Metric     string // l2 (DistanceToSimilarity 기준) 또는 cosine (SimilarityScore 기준, 50-100)
+	SoftDelete bool   // deleted_at이 있는 행 제외 (session_vectors)
+}
+
+// NewPgvectorIndex 테이블별 pgvector 인덱스 생성
+func NewPgvectorIndex(table, keyColumn, metric string) *PgvectorIndex {
+	if metric != "cosine" {
+		metric = "l2"
+	}
+	return &PgvectorIndex{Table: table, KeyColumn: keyColumn, Metric: metric}
+}
+
+func (p *PgvectorIndex) Upsert(id string, v utils.Vector5D) {}
+
+func (p *PgvectorIndex) Remove(id string) {}
+
+// IDs DB에서 직접 검색하므로 메모리 인덱스 동기화 대상이 아님
+func (p *PgvectorIndex) IDs() []string { return nil }
+
+// condition 검색 대상 행 조건
+func (p *PgvectorIndex) condition() string {
+	if p.SoftDelete {
+		return "embedding IS NOT NULL AND deleted_at IS NULL"
+	}
+	return "embedding IS NOT NULL"
+}
+
+// Len 검색 대상 행 수
+func (p *PgvectorIndex) Len() int {
+	var count int64
+	if err := database.DB.Table(p.Table).Where(p.condition()).Count(&count).Error; err != nil {
+		log.Printf("pgvector count failed (%s): %v", p.Table, err)
+	}
+	return int(count)
+}
+
+// pgvectorMaxEfSearch hnsw.ef_search 최댓값 (이보다 많이 조회하면 인덱스 대신 정확한 전체 스캔)
+const pgvectorMaxEfSearch = 1000
+
+// Search SQL에서 거리 순 상위 k개 조회
+// 정렬은 거리만 사용하고 (HNSW 인덱스 사용), 같은 거리는 Go에서 키 순으로 정렬
+// skip으로 제외되는 행이 있거나 k번째와 같은 거리의 행이 잘렸을 수 있으면 조회 범위를 늘려 다시 조회
+func (p *PgvectorIndex) Search(target utils.Vector5D, k int, skip func(id string) bool) []utils.Neighbor {
+	if k <= 0 {
+		return []utils.Neighbor{}
+	}
+
+	operator := "<->"
+	if p.Metric == "cosine" {
+		operator = "<=>"
+	}
+	query := fmt.Sprintf(`SELECT %[1]s::text AS id, embedding::text AS embedding, embedding %[3]s ?::vector AS distance
+		FROM %[2]s WHERE %[4]s
+		ORDER BY embedding %[3]s ?::vector
+		LIMIT ?`, p.KeyColumn, p.Table, operator, p.condition())
+	literal := vectorLiteral(target)
+
+	for limit := k + 1; ; limit *= 2 {
+		rows, err := p.query(query, literal, limit)
+		if err != nil {
+			log.Printf("pgvector search failed (%s): %v", p.Table, err)
+			return []utils.Neighbor{}
+		}
+
+		sort.SliceStable(rows, func(i, j int) bool {
+			if rows[i].Distance != rows[j].Distance {
+				return rows[i].Distance < rows[j].Distance
+			}
+			return rows[i].ID < rows[j].ID
+		})
+
+		neighbors := make([]utils.Neighbor, 0, k)
+		var kth float64
+		for _, row := range rows {
+			if skip != nil && skip(row.ID) {
+				continue
+			}
+			var values []float64
+			if err := json.Unmarshal([]byte(row.Embedding), &values); err != nil {
+				continue
+			}
+			v := utils.FromSlice(values)
+			if v == nil {
+				continue
+			}
+			neighbors = append(neighbors, p.neighbor(row.ID, &target, v))
+			if len(neighbors) == k {
+				kth = row.Distance
+				break
+			}
+		}
+
+		// 더 조회할 행이 없거나, k개를 채웠고 k번째보다 먼 행까지 읽었으면 (동점이 잘리지 않음) 종료
+		exhausted := len(rows) < limit
+		if exhausted || (len(neighbors) == k && rows[len(rows)-1].Distance > kth) {
+			return neighbors
+		}
+	}
+}
+
+type pgvectorRow struct {
+	ID        string
+	Embedding string
+	Distance  float64
+}
+
+// query 트랜잭션 안에서 hnsw.ef_search를 조회 개수 이상으로 올려 실행
+// HNSW는 ef_search개까지만 후보를 돌려주므로, 그대로 두면 행이 남아 있어도 limit보다 적게 조회되어 검색이 일찍 끝남
+func (p *PgvectorIndex) query(query, literal string, limit int) ([]pgvectorRow, error) {
+	var rows []pgvectorRow
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if limit <= pgvectorMaxEfSearch {
+			if err := tx.Exec(fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", limit)).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Exec("SET LOCAL enable_indexscan = off").Error; err != nil {
+			return err
+		}
+		return tx.Raw(query, literal, literal, limit).Scan(&rows).Error
+	})
+	return rows, err
+}
+
+// neighbor 검색 결과 변환 (cosine 모드는 SimilarityScore로 점수 계산)
+func (p *PgvectorIndex) neighbor(id string, target, v *utils.Vector5D) utils.Neighbor {
+	distance := utils.EuclideanDistance(target, v)
+	similarity := utils.DistanceToSimilarity(distance)
+	if p.Metric == "cosine" {
+		similarity = utils.SimilarityScore(target, v)
+	}
+	return utils.Neighbor{ID: id, Vector: *v, Distance: distance, Similarity: similarity}
+}
+
+// vectorLiteral pgvector 입력 형식 ([1,2,3,4,5])
+func vectorLiteral(v utils.Vector5D) string {
+	values := v.ToSlice()
+	parts := make([]string, len(values))
+	for i, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			value = 0
+		}
+		parts[i] = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"ongi-back/database"
+	"ongi-back/models"
+	"ongi-back/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProfileIndexRefreshFollowsOtherInstance 다른 인스턴스가 저장/수정/삭제한 세션 벡터가 refresh로 반영
+func TestProfileIndexRefreshFollowsOtherInstance(t *testing.T) {
+	openTestDB(t)
+
+	const sessionID = "test-profile-index-refresh"
+	t.Cleanup(func() {
+		database.DB.Unscoped().Where("session_id = ?", sessionID).Delete(&models.SessionVector{})
+	})
+
+	refresher := &profileIndexRefresher{
+		members:  utils.NewKDTree(),
+		sessions: utils.NewKDTree(),
+	}
+	if err := refresher.refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	found := func(want []float64) bool {
+		for _, n := range refresher.sessions.Search(*utils.FromSlice(want), 1, nil) {
+			if n.ID == sessionID {
+				return true
+			}
+		}
+		return false
+	}
+
+	// 다른 인스턴스의 저장 (이 인스턴스의 IndexSessionVector는 호출되지 않음)
+	sv := models.SessionVector{SessionID: sessionID, Vector: []float64{1, 2, 3, 4, 5}}
+	if err := database.DB.Create(&sv).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := refresher.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if !found(sv.Vector) {
+		t.Fatal("session saved by another instance not indexed after refresh")
+	}
+
+	// 수정
+	updated := []float64{95, 96, 97, 98, 99}
+	if err := database.DB.Model(&sv).Update("vector", updated).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := refresher.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if !found(updated) {
+		t.Fatal("updated session vector not reflected after refresh")
+	}
+
+	// 삭제
+	if err := database.DB.Delete(&sv).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := refresher.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range refresher.sessions.IDs() {
+		if id == sessionID {
+			t.Fatal("deleted session still indexed after refresh")
+		}
+	}
+}
+
+// TestProfileIndexRefreshKeepsUncommittedKeys 인덱스에만 있는 키(저장 직후 IndexSessionVector로 추가되었지만
+// refresh 시점에 아직 DB에서 보이지 않는 행)는 삭제로 간주하지 않음
+func TestProfileIndexRefreshKeepsUncommittedKeys(t *testing.T) {
+	openTestDB(t)
+
+	refresher := &profileIndexRefresher{
+		members:  utils.NewKDTree(),
+		sessions: utils.NewKDTree(),
+	}
+	if err := refresher.refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	const localOnly = "test-profile-index-local-only"
+	refresher.sessions.Upsert(localOnly, utils.Vector5D{Sociality: 1, Activity: 2, Intimacy: 3, Immersion: 4, Flexibility: 5})
+	if err := refresher.refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range refresher.sessions.IDs() {
+		if id == localOnly {
+			return
+		}
+	}
+	t.Fatal("key missing from the database query was removed by refresh")
+}
+
+func TestProfileIndexRefresherStop(t *testing.T) {
+	refresher := &profileIndexRefresher{
+		members:  utils.NewBruteForceIndex(),
+		sessions: utils.NewBruteForceIndex(),
+	}
+
+	// 주기가 길어 refresh는 실행되지 않으며, 중지는 여러 번 호출해도 안전해야 함
+	stop := refresher.start(time.Hour)
+	done := make(chan struct{})
+	go func() {
+		stop()
+		stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stop did not return")
+	}
+}
+
+// TestPgvectorSearchMatchesBruteForce 동점이 많고 skip이 있어도 pgvector(cosine) 결과가 정규화된 전체 비교와 같은지
+func TestPgvectorSearchMatchesBruteForce(t *testing.T) {
+	openTestDB(t)
+	if err := database.EnablePgvector("cosine"); err != nil {
+		t.Skipf("pgvector unavailable: %v", err)
+	}
+
+	t.Cleanup(func() {
+		database.DB.Unscoped().Where("session_id LIKE ?", "test-pgvector-%").Delete(&models.SessionVector{})
+	})
+	for i := 0; i < 60; i++ {
+		sv := models.SessionVector{
+			SessionID: fmt.Sprintf("test-pgvector-%02d", i),
+			Vector:    []float64{float64(20 * (i % 3)), 40, 40, 40, float64(20 * (i % 2))},
+		}
+		if err := database.DB.Create(&sv).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 삭제된 세션 벡터는 대상과 같은 벡터여도 검색되지 않아야 함
+	deleted := models.SessionVector{SessionID: "test-pgvector-deleted", Vector: []float64{0, 40, 40, 40, 0}}
+	if err := database.DB.Create(&deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DB.Delete(&deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	brute := utils.NewBruteForceIndex()
+	if _, err := loadSessionIndex(brute, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	index := NewPgvectorIndex("session_vectors", "session_id", "cosine")
+	index.SoftDelete = true
+
+	target := sessionIndexVector(&utils.Vector5D{Activity: 40, Intimacy: 40, Immersion: 40})
+	skip := func(id string) bool { return strings.HasSuffix(id, "3") }
+	for _, k := range []int{1, 5, 17, 40} {
+		got := index.Search(target, k, skip)
+		want := brute.Search(target, k, skip)
+		if len(got) != len(want) {
+			t.Fatalf("k=%d: pgvector returned %d neighbors, brute force %d", k, len(got), len(want))
+		}
+		for i := range got {
+			if got[i].ID != want[i].ID {
+				t.Fatalf("k=%d: neighbor %d = %s, brute force %s", k, i, got[i].ID, want[i].ID)
+			}
+		}
+	}
+}
+
+// TestSessionIndexVectorKeepsCosineOrder 정규화한 세션 벡터의 k-d 트리 검색이 코사인 유사도 순서와 같은지
+func TestSessionIndexVectorKeepsCosineOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() utils.Vector5D {
+		return utils.Vector5D{
+			Sociality:   rng.Float64() * 100,
+			Activity:    rng.Float64() * 100,
+			Intimacy:    rng.Float64() * 100,
+			Immersion:   rng.Float64() * 100,
+			Flexibility: rng.Float64() * 100,
+		}
+	}
+
+	vectors := make(map[string]utils.Vector5D)
+	index := utils.NewKDTree()
+	for i := 0; i < 500; i++ {
+		id := fmt.Sprintf("s%03d", i)
+		v := random()
+		// 크기만 다른 벡터는 코사인 유사도가 같으므로 유클리드 거리가 멀어도 같은 점수여야 함
+		if i%50 == 0 {
+			v = utils.Vector5D{Sociality: 10, Activity: 20, Intimacy: 30, Immersion: 40, Flexibility: 50}
+			if i%100 == 0 {
+				v = utils.Vector5D{Sociality: 2, Activity: 4, Intimacy: 6, Immersion: 8, Flexibility: 10}
+			}
+		}
+		vectors[id] = v
+		index.Upsert(id, sessionIndexVector(&v))
+	}
+
+	for trial := 0; trial < 20; trial++ {
+		target := random()
+		neighbors := index.Search(sessionIndexVector(&target), 50, nil)
+		if len(neighbors) != 50 {
+			t.Fatalf("got %d neighbors, want 50", len(neighbors))
+		}
+
+		prev := 2.0
+		for _, n := range neighbors {
+			v := vectors[n.ID]
+			cos := utils.CosineSimilarity(&target, &v)
+			if cos > prev+1e-9 {
+				t.Fatalf("trial %d: %s has cosine %.6f after %.6f", trial, n.ID, cos, prev)
+			}
+			prev = cos
+		}
+
+		// 반환되지 않은 벡터 중 마지막 이웃보다 코사인 유사도가 큰 것이 없어야 함
+		returned := make(map[string]bool, len(neighbors))
+		for _, n := range neighbors {
+			returned[n.ID] = true
+		}
+		for id, v := range vectors {
+			if !returned[id] && utils.CosineSimilarity(&target, &v) > prev+1e-9 {
+				t.Fatalf("trial %d: %s (cosine %.6f) missing, last neighbor %.6f", trial, id, utils.CosineSimilarity(&target, &v), prev)
+			}
+		}
+	}
+}
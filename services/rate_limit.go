@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited 전송 속도 제한 초과
+var ErrRateLimited = errors.New("too many messages")
+
+// RateLimitError 속도 제한 초과 (RetryAfter 후 다시 시도 가능)
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many messages, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// Is errors.Is(err, ErrRateLimited) 지원
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// tokenBucket 토큰 버킷 상태
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// TokenBucketLimiter 키별 토큰 버킷 속도 제한 (인스턴스 메모리에 저장)
+type TokenBucketLimiter struct {
+	Capacity float64 // 최대 버스트
+	Rate     float64 // 초당 충전되는 토큰 수
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	calls   int
+	now     func() time.Time
+}
+
+// NewTokenBucketLimiter 토큰 버킷 생성 (capacity: 버스트, rate: 초당 허용 수)
+func NewTokenBucketLimiter(capacity, rate float64) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		Capacity: capacity,
+		Rate:     rate,
+		buckets:  make(map[string]*tokenBucket),
+		now:      time.Now,
+	}
+}
+
+// Allow key의 토큰 하나 사용, 부족하면 다시 시도할 수 있을 때까지의 시간 반환
+func (l *TokenBucketLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.calls++
+	if l.calls%1000 == 0 {
+		l.sweep(now)
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.Capacity, last: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(l.Capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*l.Rate)
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - bucket.tokens) / l.Rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep 가득 찬 버킷 정리 (다시 만들어도 상태가 같음)
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	full := time.Duration(l.Capacity / l.Rate * float64(time.Second))
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock 테스트에서 시간을 직접 진행시키는 시계
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestLimiter 가짜 시계를 쓰는 토큰 버킷
+func newTestLimiter(capacity, rate float64) (*TokenBucketLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewTokenBucketLimiter(capacity, rate)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func TestTokenBucketRefillAndRetryAfter(t *testing.T) {
+	limiter, clock := newTestLimiter(3, 2)
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		allowed   bool
+		wantRetry time.Duration
+	}{
+		{"burst 1", 0, true, 0},
+		{"burst 2", 0, true, 0},
+		{"burst 3", 0, true, 0},
+		{"empty bucket waits for one token", 0, false, 500 * time.Millisecond},
+		{"partially refilled", 250 * time.Millisecond, false, 250 * time.Millisecond},
+		{"one token refilled", 250 * time.Millisecond, true, 0},
+		{"empty again", 0, false, 500 * time.Millisecond},
+		{"refill is capped at capacity", time.Hour, true, 0},
+		{"capacity 2", 0, true, 0},
+		{"capacity 3", 0, true, 0},
+		{"capacity exhausted", 0, false, 500 * time.Millisecond},
+	}
+
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		allowed, retry := limiter.Allow("user")
+		if allowed != step.allowed || retry != step.wantRetry {
+			t.Fatalf("%s: Allow() = %v, %v, want %v, %v", step.name, allowed, retry, step.allowed, step.wantRetry)
+		}
+	}
+}
+
+func TestTokenBucketKeysAreIndependent(t *testing.T) {
+	limiter, _ := newTestLimiter(1, 1)
+
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Fatal("first message for a should be allowed")
+	}
+	if ok, _ := limiter.Allow("a"); ok {
+		t.Fatal("second message for a should be limited")
+	}
+	if ok, _ := limiter.Allow("b"); !ok {
+		t.Fatal("b should have its own bucket")
+	}
+}
+
+func TestRateLimitFilter(t *testing.T) {
+	room, _ := newTestLimiter(2, 1)
+	user, _ := newTestLimiter(3, 1)
+	filter := &RateLimitFilter{Room: room, User: user}
+
+	send := func(roomID uint) error {
+		return filter.Filter(&MessageContent{RoomID: roomID, UserID: 7, Text: "hi"})
+	}
+
+	// 채팅방 버킷(2)이 먼저 비고, 다른 채팅방은 사용자 버킷(3)이 빌 때까지 전송 가능
+	for i, want := range []bool{true, true, false} {
+		if err := send(1); (err == nil) != want {
+			t.Fatalf("room 1 message %d: err=%v, want allowed=%v", i+1, err, want)
+		}
+	}
+	if err := send(2); err != nil {
+		t.Fatalf("room 2 first message: err=%v", err)
+	}
+
+	err := send(3)
+	var rateErr *RateLimitError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &rateErr) {
+		t.Fatalf("user bucket exhausted: err=%v, want RateLimitError", err)
+	}
+	if rateErr.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s", rateErr.RetryAfter)
+	}
+}
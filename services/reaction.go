@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidEmoji 허용되지 않는 이모지 값
+var ErrInvalidEmoji = errors.New("invalid emoji")
+
+// maxEmojiRunes 이모지 최대 길이 (ZWJ 조합 이모지 포함)
+const maxEmojiRunes = 16
+
+// ReactionResult 반응 토글 결과
+type ReactionResult struct {
+	MessageID uint   `json:"message_id"`
+	Emoji     string `json:"emoji"`
+	Added     bool   `json:"added"` // false면 반응 취소
+	Count     int    `json:"count"` // 토글 후 해당 이모지 반응 수
+}
+
+// ToggleReaction 메시지 반응 토글 (없으면 추가, 있으면 취소)
+func ToggleReaction(roomID, messageID, userID uint, emoji string) (*ReactionResult, error) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || utf8.RuneCountInString(emoji) > maxEmojiRunes || strings.ContainsAny(emoji, " \t\r\n") {
+		return nil, ErrInvalidEmoji
+	}
+
+	result := &ReactionResult{MessageID: messageID, Emoji: emoji}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := requireWritableRoom(tx, roomID); err != nil {
+			return err
+		}
+		if _, err := findRoomMember(tx, roomID, userID); err != nil {
+			return ErrNotRoomMember
+		}
+
+		var message models.ChatMessage
+		if err := tx.Select("id", "deleted_at").
+			Where("id = ? AND chat_room_id = ?", messageID, roomID).First(&message).Error; err != nil {
+			return ErrMessageNotFound
+		}
+		if message.DeletedAt != nil {
+			return ErrMessageDeleted
+		}
+
+		deleted := tx.Where("chat_message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+			Delete(&models.ChatMessageReaction{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		if deleted.RowsAffected == 0 {
+			// 같은 반응이 동시에 추가되면 먼저 저장된 반응을 그대로 사용
+			reaction := models.ChatMessageReaction{ChatMessageID: messageID, UserID: userID, Emoji: emoji}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction).Error; err != nil {
+				return err
+			}
+			result.Added = true
+		}
+
+		var count int64
+		if err := tx.Model(&models.ChatMessageReaction{}).
+			Where("chat_message_id = ? AND emoji = ?", messageID, emoji).Count(&count).Error; err != nil {
+			return err
+		}
+		result.Count = int(count)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if GlobalHub != nil {
+		GlobalHub.BroadcastMessage(roomID, "reaction_updated", userID, map[string]interface{}{
+			"message_id": result.MessageID,
+			"emoji":      result.Emoji,
+			"user_id":    userID,
+			"added":      result.Added,
+			"count":      result.Count,
+		})
+	}
+
+	return result, nil
+}
+
+// FillReactions 메시지 목록의 이모지별 반응 수를 한 번의 쿼리로 채움
+// userID가 반응한 이모지는 Reacted=true
+func FillReactions(userID uint, messages []models.ChatMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(messages))
+	for i := range messages {
+		ids[i] = messages[i].ID
+		messages[i].Reactions = []models.ReactionSummary{}
+	}
+
+	var rows []struct {
+		ChatMessageID uint
+		Emoji         string
+		Count         int
+		Reacted       bool
+	}
+	if err := database.DB.Model(&models.ChatMessageReaction{}).
+		Select("chat_message_id, emoji, COUNT(*) AS count, BOOL_OR(user_id = ?) AS reacted", userID).
+		Where("chat_message_id IN ?", ids).
+		Group("chat_message_id, emoji").
+		Order("MIN(created_at)").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	index := make(map[uint]int, len(messages))
+	for i := range messages {
+		index[messages[i].ID] = i
+	}
+	for _, row := range rows {
+		i, ok := index[row.ChatMessageID]
+		if !ok {
+			continue
+		}
+		messages[i].Reactions = append(messages[i].Reactions, models.ReactionSummary{
+			Emoji:   row.Emoji,
+			Count:   row.Count,
+			Reacted: row.Reacted,
+		})
+	}
+
+	return nil
+}
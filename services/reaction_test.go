@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reactionRows 메시지에 저장된 사용자/이모지 반응 행 수
+func reactionRows(t *testing.T, messageID, userID uint, emoji string) int64 {
+	t.Helper()
+	var count int64
+	if err := database.DB.Model(&models.ChatMessageReaction{}).
+		Where("chat_message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+		Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestToggleReactionCounts(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "reaction", 2)
+	room := createTestRoom(t, users, []string{RoleAdmin, RoleMember})
+	a, b := users[0].ID, users[1].ID
+	message := createTestMessage(t, room.ID, a, "")
+
+	steps := []struct {
+		user      uint
+		emoji     string
+		wantAdded bool
+		wantCount int
+	}{
+		{a, "👍", true, 1},
+		{b, "👍", true, 2},
+		{b, "❤️", true, 1},
+		{a, "👍", false, 1},
+		{b, "👍", false, 0},
+		{a, " 👍 ", true, 1}, // 앞뒤 공백은 제거
+	}
+	for i, step := range steps {
+		result, err := ToggleReaction(room.ID, message.ID, step.user, step.emoji)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if result.Added != step.wantAdded || result.Count != step.wantCount {
+			t.Errorf("step %d: added=%v count=%d, want added=%v count=%d",
+				i, result.Added, result.Count, step.wantAdded, step.wantCount)
+		}
+	}
+
+	messages := []models.ChatMessage{message}
+	if err := FillReactions(b, messages); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]models.ReactionSummary{}
+	for _, r := range messages[0].Reactions {
+		got[r.Emoji] = r
+	}
+	if len(got) != 2 || got["👍"].Count != 1 || got["👍"].Reacted || got["❤️"].Count != 1 || !got["❤️"].Reacted {
+		t.Errorf("reactions = %+v, want 👍 1 (not reacted by b), ❤️ 1 (reacted by b)", messages[0].Reactions)
+	}
+}
+
+// TestToggleReactionConcurrent 같은 반응을 동시에 토글해도 사용자당 한 행만 남고 에러가 나지 않는지
+func TestToggleReactionConcurrent(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "reactionrace", 1)
+	room := createTestRoom(t, users, []string{RoleAdmin})
+	message := createTestMessage(t, room.ID, users[0].ID, "")
+
+	const workers = 8
+	var wg sync.WaitGroup
+	results := make([]*ReactionResult, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := ToggleReaction(room.ID, message.ID, users[0].ID, "🎉")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+
+	rows := reactionRows(t, message.ID, users[0].ID, "🎉")
+	if rows > 1 {
+		t.Fatalf("%d reaction rows for one user, want at most 1", rows)
+	}
+	for i, result := range results {
+		if result != nil && (result.Count < 0 || result.Count > 1) {
+			t.Errorf("worker %d: count = %d, want 0 or 1", i, result.Count)
+		}
+	}
+
+	// 동시 요청 후에도 토글은 저장된 상태를 기준으로 동작
+	result, err := ToggleReaction(room.ID, message.ID, users[0].ID, "🎉")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != (rows == 0) || reactionRows(t, message.ID, users[0].ID, "🎉") == rows {
+		t.Errorf("toggle after race: added=%v with %d rows before", result.Added, rows)
+	}
+}
+
+func TestToggleReactionErrors(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "reactionerr", 2)
+	room := createTestRoom(t, users[:1], []string{RoleAdmin})
+	member, outsider := users[0].ID, users[1].ID
+	message := createTestMessage(t, room.ID, member, "")
+
+	deleted := createTestMessage(t, room.ID, member, "")
+	now := time.Now()
+	if err := database.DB.Model(&deleted).Update("deleted_at", now).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		user      uint
+		messageID uint
+		emoji     string
+		want      error
+	}{
+		{"empty emoji", member, message.ID, "  ", ErrInvalidEmoji},
+		{"emoji with space", member, message.ID, "👍 👍", ErrInvalidEmoji},
+		{"too long emoji", member, message.ID, "abcdefghijklmnopq", ErrInvalidEmoji},
+		{"non-member", outsider, message.ID, "👍", ErrNotRoomMember},
+		{"unknown message", member, message.ID + 1000000, "👍", ErrMessageNotFound},
+		{"deleted message", member, deleted.ID, "👍", ErrMessageDeleted},
+	}
+	for _, tc := range cases {
+		if _, err := ToggleReaction(room.ID, tc.messageID, tc.user, tc.emoji); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	if err := database.DB.Model(&models.ChatRoom{}).Where("id = ?", room.ID).Update("archived_at", now).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ToggleReaction(room.ID, message.ID, member, "👍"); !errors.Is(err, ErrRoomArchived) {
+		t.Errorf("archived room: err = %v, want ErrRoomArchived", err)
+	}
+}
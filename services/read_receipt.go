@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReadReceipt 메시지를 읽은 멤버
+type ReadReceipt struct {
+	UserID     uint       `json:"user_id"`
+	LastReadAt *time.Time `json:"last_read_at"`
+}
+
+// MessageReadStatus 메시지 읽음 현황 ("읽음 3" 표시용)
+type MessageReadStatus struct {
+	MessageID   uint          `json:"message_id"`
+	ReadCount   int           `json:"read_count"`   // 작성자를 제외하고 읽은 멤버 수
+	UnreadCount int           `json:"unread_count"` // 작성자를 제외하고 아직 읽지 않은 멤버 수
+	ReadBy      []ReadReceipt `json:"read_by"`
+}
+
+// LatestMessageID 채팅방의 최신 메시지 ID (메시지가 없으면 nil)
+// 새 멤버의 읽음 커서 시작점으로 사용 - 가입 전 메시지는 안 읽은 수나 다른 메시지의 읽음 수에 포함되지 않음
+func LatestMessageID(tx *gorm.DB, roomID uint) (*uint, error) {
+	var id *uint
+	err := tx.Model(&models.ChatMessage{}).Where("chat_room_id = ?", roomID).Select("MAX(id)").Row().Scan(&id)
+	return id, err
+}
+
+// MarkChatRead 읽음 커서를 messageID까지 이동 (messageID가 0이면 채팅방의 최신 메시지)
+// 커서는 앞으로만 이동하므로 늦게 도착한 요청이 읽음 상태를 되돌리지 않음
+func MarkChatRead(roomID, userID, messageID uint) (*models.ChatRoomMember, error) {
+	var membership models.ChatRoomMember
+	if err := database.DB.Where("chat_room_id = ? AND user_id = ?", roomID, userID).First(&membership).Error; err != nil {
+		return nil, ErrNotRoomMember
+	}
+
+	var latest models.ChatMessage
+	query := database.DB.Where("chat_room_id = ?", roomID)
+	if messageID != 0 {
+		query = query.Where("id = ?", messageID)
+	}
+	if err := query.Order("id DESC").First(&latest).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) && messageID == 0 {
+			// 메시지가 없는 채팅방
+			return &membership, nil
+		}
+		return nil, ErrMessageNotFound
+	}
+
+	now := time.Now()
+	result := database.DB.Model(&models.ChatRoomMember{}).
+		Where("id = ? AND (last_read_message_id IS NULL OR last_read_message_id < ?)", membership.ID, latest.ID).
+		Updates(map[string]interface{}{
+			"last_read_message_id": latest.ID,
+			"last_read_at":         now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// 이미 더 뒤의 메시지까지 읽은 경우 변경 없음
+	if result.RowsAffected == 0 {
+		return &membership, nil
+	}
+
+	membership.LastReadMessageID = &latest.ID
+	membership.LastReadAt = &now
+
+	if GlobalHub != nil {
+		GlobalHub.BroadcastMessage(roomID, "read", userID, map[string]interface{}{
+			"user_id":              userID,
+			"last_read_message_id": latest.ID,
+			"last_read_at":         now,
+		})
+	}
+
+	return &membership, nil
+}
+
+// GetMessageReadStatus 메시지를 읽은 멤버 목록 조회
+func GetMessageReadStatus(roomID, messageID uint) (*MessageReadStatus, error) {
+	var message models.ChatMessage
+	if err := database.DB.Where("id = ? AND chat_room_id = ?", messageID, roomID).First(&message).Error; err != nil {
+		return nil, ErrMessageNotFound
+	}
+
+	var members []models.ChatRoomMember
+	if err := database.DB.Where("chat_room_id = ? AND user_id != ?", roomID, message.UserID).Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	status := &MessageReadStatus{
+		MessageID: message.ID,
+		ReadBy:    []ReadReceipt{},
+	}
+	for _, member := range members {
+		if member.LastReadMessageID != nil && *member.LastReadMessageID >= message.ID {
+			status.ReadBy = append(status.ReadBy, ReadReceipt{
+				UserID:     member.UserID,
+				LastReadAt: member.LastReadAt,
+			})
+			continue
+		}
+		status.UnreadCount++
+	}
+	status.ReadCount = len(status.ReadBy)
+
+	return status, nil
+}
+
+// FillUnreadMemberCounts 메시지별로 아직 읽지 않은 멤버 수 계산 (작성자 제외)
+func FillUnreadMemberCounts(roomID uint, messages []models.ChatMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	var members []models.ChatRoomMember
+	if err := database.DB.Select("user_id", "last_read_message_id").
+		Where("chat_room_id = ?", roomID).Find(&members).Error; err != nil {
+		return err
+	}
+
+	for i := range messages {
+		// 시스템 메시지는 읽음 표시 대상이 아님
+		if messages[i].MessageType == MessageTypeSystem {
+			messages[i].UnreadMemberCount = 0
+			continue
+		}
+		count := 0
+		for _, member := range members {
+			if member.UserID == messages[i].UserID {
+				continue
+			}
+			if member.LastReadMessageID == nil || *member.LastReadMessageID < messages[i].ID {
+				count++
+			}
+		}
+		messages[i].UnreadMemberCount = count
+	}
+
+	return nil
+}
+
+// GetUnreadCounts 사용자의 채팅방별 읽지 않은 메시지 수 (읽음 커서 이후, 본인/삭제 메시지 제외)
+func GetUnreadCounts(userID uint, roomIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int, len(roomIDs))
+	if len(roomIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ChatRoomID uint
+		Unread     int
+	}
+	if err := database.DB.Table("chat_room_members AS m").
+		Select("m.chat_room_id, COUNT(msg.id) AS unread").
+		Joins(`LEFT JOIN chat_messages msg ON msg.chat_room_id = m.chat_room_id
+			AND msg.id > COALESCE(m.last_read_message_id, 0)
+			AND msg.user_id <> m.user_id
+			AND msg.message_type <> 'system'
+			AND msg.deleted_at IS NULL`).
+		Where("m.user_id = ? AND m.chat_room_id IN ?", userID, roomIDs).
+		Group("m.chat_room_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.ChatRoomID] = row.Unread
+	}
+	return counts, nil
+}
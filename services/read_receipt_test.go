@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"sync"
+	"testing"
+)
+
+// createTestMessage 테스트용 메시지 저장 (messageType이 비어 있으면 text)
+func createTestMessage(t *testing.T, roomID, userID uint, messageType string) models.ChatMessage {
+	t.Helper()
+	if messageType == "" {
+		messageType = "text"
+	}
+	message := models.ChatMessage{ChatRoomID: roomID, UserID: userID, Message: "test message", MessageType: messageType}
+	if err := database.DB.Create(&message).Error; err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+// readCursor 멤버의 현재 읽음 커서 (없으면 0)
+func readCursor(t *testing.T, roomID, userID uint) uint {
+	t.Helper()
+	member, err := findRoomMember(database.DB, roomID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.LastReadMessageID == nil {
+		return 0
+	}
+	return *member.LastReadMessageID
+}
+
+func TestMarkChatReadMovesForwardOnly(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "read", 2)
+	room := createTestRoom(t, users, []string{RoleAdmin, RoleMember})
+	author, reader := users[0].ID, users[1].ID
+
+	m1 := createTestMessage(t, room.ID, author, "")
+	m2 := createTestMessage(t, room.ID, author, "")
+	m3 := createTestMessage(t, room.ID, author, "")
+
+	if _, err := MarkChatRead(room.ID, reader, m2.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := readCursor(t, room.ID, reader); got != m2.ID {
+		t.Fatalf("cursor = %d, want %d", got, m2.ID)
+	}
+
+	// 늦게 도착한 이전 메시지 읽음은 커서를 되돌리지 않음
+	membership, err := MarkChatRead(room.ID, reader, m1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readCursor(t, room.ID, reader); got != m2.ID {
+		t.Fatalf("cursor moved back to %d, want %d", got, m2.ID)
+	}
+	if membership.LastReadMessageID == nil || *membership.LastReadMessageID != m2.ID {
+		t.Errorf("returned cursor = %v, want %d", membership.LastReadMessageID, m2.ID)
+	}
+
+	// messageID 0은 최신 메시지까지
+	if _, err := MarkChatRead(room.ID, reader, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := readCursor(t, room.ID, reader); got != m3.ID {
+		t.Fatalf("cursor = %d, want latest %d", got, m3.ID)
+	}
+}
+
+func TestMarkChatReadErrors(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "readerr", 3)
+	room := createTestRoom(t, users[:2], []string{RoleAdmin, RoleMember})
+	other := createTestRoom(t, []models.User{users[2]}, []string{RoleAdmin})
+	foreign := createTestMessage(t, other.ID, users[2].ID, "")
+
+	if _, err := MarkChatRead(room.ID, users[2].ID, 0); !errors.Is(err, ErrNotRoomMember) {
+		t.Errorf("non-member: err = %v, want ErrNotRoomMember", err)
+	}
+	if _, err := MarkChatRead(room.ID, users[1].ID, foreign.ID); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("message of another room: err = %v, want ErrMessageNotFound", err)
+	}
+
+	// 메시지가 없는 채팅방은 커서 없이 성공
+	membership, err := MarkChatRead(room.ID, users[1].ID, 0)
+	if err != nil {
+		t.Fatalf("empty room: %v", err)
+	}
+	if membership.LastReadMessageID != nil {
+		t.Errorf("empty room cursor = %d, want nil", *membership.LastReadMessageID)
+	}
+}
+
+func TestMarkChatReadConcurrentKeepsLatest(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "readrace", 2)
+	room := createTestRoom(t, users, []string{RoleAdmin, RoleMember})
+
+	var messages []models.ChatMessage
+	for i := 0; i < 10; i++ {
+		messages = append(messages, createTestMessage(t, room.ID, users[0].ID, ""))
+	}
+
+	// 순서와 관계없이 가장 뒤의 메시지가 커서로 남아야 함
+	var wg sync.WaitGroup
+	for i := len(messages) - 1; i >= 0; i-- {
+		wg.Add(1)
+		go func(id uint) {
+			defer wg.Done()
+			if _, err := MarkChatRead(room.ID, users[1].ID, id); err != nil {
+				t.Error(err)
+			}
+		}(messages[i].ID)
+	}
+	wg.Wait()
+
+	if got, want := readCursor(t, room.ID, users[1].ID), messages[len(messages)-1].ID; got != want {
+		t.Errorf("cursor = %d, want %d", got, want)
+	}
+}
+
+func TestReadStatusAndUnreadCounts(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "readstatus", 3)
+	room := createTestRoom(t, users, []string{RoleAdmin, RoleMember, RoleMember})
+	author, reader, idle := users[0].ID, users[1].ID, users[2].ID
+
+	m1 := createTestMessage(t, room.ID, author, "")
+	m2 := createTestMessage(t, room.ID, author, "")
+	own := createTestMessage(t, room.ID, reader, "")
+	if _, err := MarkChatRead(room.ID, reader, m1.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// 작성자는 읽음 현황에서 제외
+	status, err := GetMessageReadStatus(room.ID, m1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ReadCount != 1 || status.UnreadCount != 1 || status.ReadBy[0].UserID != reader {
+		t.Errorf("m1 status = %+v, want read by %d and 1 unread", status, reader)
+	}
+	if status, err = GetMessageReadStatus(room.ID, m2.ID); err != nil {
+		t.Fatal(err)
+	}
+	if status.ReadCount != 0 || status.UnreadCount != 2 {
+		t.Errorf("m2 status = %+v, want 0 read and 2 unread", status)
+	}
+
+	messages := []models.ChatMessage{m1, m2, own}
+	if err := FillUnreadMemberCounts(room.ID, messages); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{1, 2, 2} {
+		if messages[i].UnreadMemberCount != want {
+			t.Errorf("message %d unread members = %d, want %d", messages[i].ID, messages[i].UnreadMemberCount, want)
+		}
+	}
+
+	// 본인 메시지는 안 읽은 수에 포함되지 않음
+	counts, err := GetUnreadCounts(reader, []uint{room.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[room.ID] != 1 {
+		t.Errorf("reader unread = %d, want 1", counts[room.ID])
+	}
+	if counts, err = GetUnreadCounts(idle, []uint{room.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if counts[room.ID] != 3 {
+		t.Errorf("idle unread = %d, want 3", counts[room.ID])
+	}
+}
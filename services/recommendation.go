@@ -1,17 +1,14 @@
 package services
 
 import (
+	"errors"
+	"log"
 	"math"
 	"ongi-back/database"
 	"ongi-back/models"
-	"sort"
+	"ongi-back/utils"
 )
 
-type UserSimilarity struct {
-	User       models.User `json:"user"`
-	Similarity float64     `json:"similarity"`
-}
-
 // 유클리드 거리 기반 유사도 계산
 func calculateSimilarity(profile1, profile2 *models.UserProfile) float64 {
 	diff1 := profile1.SocialityScore - profile2.SocialityScore
@@ -31,132 +28,98 @@ func calculateSimilarity(profile1, profile2 *models.UserProfile) float64 {
 	return similarity
 }
 
-func GetSimilarUsers(userID uint, limit int) ([]UserSimilarity, error) {
-	var userProfile models.UserProfile
-	err := database.DB.Where("user_id = ?", userID).First(&userProfile).Error
-	if err != nil {
-		return nil, err
-	}
-
-	var allProfiles []models.UserProfile
-	err = database.DB.Preload("User").
-		Where("user_id != ?", userID).
-		Find(&allProfiles).Error
-	if err != nil {
-		return nil, err
-	}
-
-	// 유사도 계산
-	similarities := []UserSimilarity{}
-	for _, profile := range allProfiles {
-		similarity := calculateSimilarity(&userProfile, &profile)
-		similarities = append(similarities, UserSimilarity{
-			User:       profile.User,
-			Similarity: similarity,
-		})
-	}
-
-	// 70% 이상 유사도만 필터링
-	var filteredSimilarities []UserSimilarity
-	for _, sim := range similarities {
-		if sim.Similarity >= 70.0 {
-			filteredSimilarities = append(filteredSimilarities, sim)
-		}
-	}
-
-	// 유사도 높은 순으로 정렬
-	sort.Slice(filteredSimilarities, func(i, j int) bool {
-		return filteredSimilarities[i].Similarity > filteredSimilarities[j].Similarity
-	})
-
-	// 상위 N명 반환
-	maxResults := limit
-	if len(filteredSimilarities) < maxResults {
-		maxResults = len(filteredSimilarities)
-	}
+// 회원 유사 사용자로 판단하는 최소 유사도
+// 코사인 점수(SimilarityScore)는 점수가 모두 양수라 50 이상이므로 pgvector cosine 모드는 별도 기준 사용
+const (
+	memberSimilarityThreshold       = 70.0 // 유클리드 거리 기준 (DistanceToSimilarity)
+	memberCosineSimilarityThreshold = 97.5 // 코사인 유사도 0.95 이상
+)
 
-	return filteredSimilarities[:maxResults], nil
+// UserSimilarity 회원 유사 사용자 응답 항목
+type UserSimilarity struct {
+	User       models.User `json:"user"`
+	Similarity float64     `json:"similarity"`
 }
 
-func GetRecommendedClubs(userID uint, limit int) ([]models.Club, error) {
-	var userProfile models.UserProfile
-	err := database.DB.Where("user_id = ?", userID).First(&userProfile).Error
-	if err != nil {
-		return nil, err
+// similarityThreshold 대상/인덱스별 최소 유사도 (회원 70%, cosine 모드 97.5%, 게스트는 기준 없이 상위 N개)
+func (s ProfileSubject) similarityThreshold(index utils.VectorIndex) float64 {
+	if s.IsGuest() {
+		return 0
 	}
-
-	var clubs []models.Club
-	query := database.DB.Preload("Members")
-
-	// 사교성이 높은 사람에게는 멤버가 많은 클럽 추천
-	if userProfile.SocialityScore >= 70 {
-		query = query.Order("member_count DESC")
-	} else {
-		// 친밀도가 높은 사람에게는 적당한 규모의 클럽 추천
-		query = query.Order("member_count ASC")
+	if p, ok := index.(*PgvectorIndex); ok && p.Metric == "cosine" {
+		return memberCosineSimilarityThreshold
 	}
+	return memberSimilarityThreshold
+}
 
-	err = query.Limit(limit).Find(&clubs).Error
+// GetSimilarProfiles 성향이 비슷한 프로필 검색 (유사도 높은 순, 회원은 70% 이상만)
+// 회원은 다른 회원 프로필과, 게스트는 다른 세션 벡터와 비교 (메모리 인덱스의 최근접 이웃 검색)
+// 회원은 유클리드 거리 기반 점수 (pgvector cosine 모드에서는 코사인 유사도 점수), 게스트는 항상 코사인 유사도 점수(SimilarityScore) 순
+func GetSimilarProfiles(subject ProfileSubject, limit int) ([]SimilarProfile, error) {
+	v, err := subject.Vector()
 	if err != nil {
 		return nil, err
 	}
 
-	return clubs, nil
-}
-
-func GetClubsWithSimilarMembers(userID uint, limit int) ([]models.Club, error) {
-	// 유사한 사용자들이 많이 가입한 클럽 찾기
-	similarUsers, err := GetSimilarUsers(userID, 20)
+	index, err := subject.profileIndex()
 	if err != nil {
 		return nil, err
 	}
 
-	if len(similarUsers) == 0 {
-		return GetRecommendedClubs(userID, limit)
+	k := limit
+	if k <= 0 {
+		k = index.Len()
 	}
-
-	var userIDs []uint
-	for _, userSim := range similarUsers {
-		userIDs = append(userIDs, userSim.User.ID)
+	target := *v
+	if subject.IsGuest() {
+		target = sessionIndexVector(v)
 	}
-
-	type ClubCount struct {
-		ClubID uint
-		Count  int64
+	self := subject.indexKey()
+	neighbors := index.Search(target, k, func(id string) bool { return id == self })
+
+	// 가까운 순이므로 기준 미만이 나오면 이후는 모두 제외
+	threshold := subject.similarityThreshold(index)
+	for i, n := range neighbors {
+		if n.Similarity < threshold {
+			neighbors = neighbors[:i]
+			break
+		}
 	}
 
-	var clubCounts []ClubCount
-	err = database.DB.Model(&models.ClubMember{}).
-		Select("club_id, COUNT(*) as count").
-		Where("user_id IN ?", userIDs).
-		Group("club_id").
-		Order("count DESC").
-		Limit(limit).
-		Scan(&clubCounts).Error
-
+	profiles, err := subject.resolveNeighbors(neighbors)
 	if err != nil {
 		return nil, err
 	}
+	if subject.IsGuest() {
+		for i := range profiles {
+			profiles[i].Similarity = utils.SimilarityScore(v, profiles[i].Vector)
+		}
+	}
+	return profiles, nil
+}
 
-	var clubIDs []uint
-	for _, cc := range clubCounts {
-		clubIDs = append(clubIDs, cc.ClubID)
+// GetSimilarUsers 회원 유사 사용자 검색 ({user, similarity} 형식)
+func GetSimilarUsers(userID uint, limit int) ([]UserSimilarity, error) {
+	profiles, err := GetSimilarProfiles(MemberSubject(userID), limit)
+	if err != nil {
+		return nil, err
 	}
 
-	var clubs []models.Club
-	if len(clubIDs) > 0 {
-		err = database.DB.Where("id IN ?", clubIDs).Find(&clubs).Error
-		if err != nil {
-			return nil, err
+	users := make([]UserSimilarity, 0, len(profiles))
+	for _, profile := range profiles {
+		if profile.User != nil {
+			users = append(users, UserSimilarity{User: *profile.User, Similarity: profile.Similarity})
 		}
 	}
-
-	return clubs, nil
+	return users, nil
 }
 
-func GetRecommendedMeetings(userID uint, limit int) ([]models.Meeting, error) {
-	var userProfile models.UserProfile
-	err := database.DB.Where("user_id = ?", userID).First(&userProfile).Error
+// GetRecommendedMeetings 성향 기반 모임 추천
+func GetRecommendedMeetings(subject ProfileSubject, limit int) ([]models.Meeting, error) {
+	v, err := subject.Vector()
+	if errors.Is(err, ErrNoPreferenceVector) {
+		return []models.Meeting{}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -165,10 +128,13 @@ func GetRecommendedMeetings(userID uint, limit int) ([]models.Meeting, error) {
 	query := database.DB.Preload("Club")
 
 	// 활동성이 높은 사람에게는 다양한 모임 추천
-	if userProfile.ActivityScore >= 70 {
+	if v.Activity >= 70 {
 		query = query.Order("scheduled_at ASC")
+	} else if v.Intimacy >= 60 {
+		// 친밀도가 높은 사람에게는 소규모 모임
+		query = query.Where("max_members <= ?", 20).Order("max_members ASC")
 	} else {
-		query = query.Order("max_members ASC")
+		query = query.Order("created_at DESC")
 	}
 
 	err = query.Limit(limit).Find(&meetings).Error
@@ -219,30 +185,12 @@ func MatchUsersToClubs() error {
 			continue
 		}
 
-		// 그룹의 모든 사용자를 해당 클럽에 추가
-		addedCount := 0
+		// 그룹의 모든 사용자를 해당 클럽에 추가 (이미 가입한 사용자는 건너뜀)
 		for _, user := range group.Users {
-			// 이미 가입했는지 확인
-			var existingMember models.ClubMember
-			err := database.DB.Where("club_id = ? AND user_id = ?", bestClub.ID, user.ID).
-				First(&existingMember).Error
-
-			if err != nil { // 가입하지 않은 경우만 추가
-				member := models.ClubMember{
-					ClubID: bestClub.ID,
-					UserID: user.ID,
-				}
-				database.DB.Create(&member)
-				addedCount++
+			if _, err := JoinClub(bestClub.ID, user.ID); err != nil && !errors.Is(err, ErrAlreadyClubMember) {
+				log.Printf("Failed to join club: ClubID=%d, UserID=%d, err=%v", bestClub.ID, user.ID, err)
 			}
 		}
-
-		// 실제 추가된 멤버 수만큼 증가
-		if addedCount > 0 {
-			database.DB.Model(&models.Club{}).
-				Where("id = ?", bestClub.ID).
-				Update("member_count", database.DB.Raw("member_count + ?", addedCount))
-		}
 	}
 
 	return nil
@@ -348,38 +296,7 @@ func findBestClubForGroup(group UserGroup, clubs []models.Club) *models.Club {
 	return bestClub
 }
 
-// 클럽과 그룹의 매칭 점수 계산
+// 클럽과 그룹의 매칭 점수 계산 (그룹 평균 성향과 클럽 선호 벡터의 유사도)
 func calculateClubMatchScore(avgProfile models.UserProfile, club *models.Club) float64 {
-	// Vibe에 따른 성향 매칭
-	score := 0.0
-
-	switch club.Vibe {
-	case "energetic":
-		score += avgProfile.SocialityScore * 0.3
-		score += avgProfile.ActivityScore * 0.4
-		score += avgProfile.FlexibilityScore * 0.3
-	case "cozy":
-		score += avgProfile.IntimacyScore * 0.4
-		score += avgProfile.ImmersionScore * 0.3
-		score += (100 - avgProfile.ActivityScore) * 0.3
-	case "deep":
-		score += avgProfile.ImmersionScore * 0.5
-		score += avgProfile.IntimacyScore * 0.3
-		score += avgProfile.FlexibilityScore * 0.2
-	case "casual":
-		score += avgProfile.FlexibilityScore * 0.4
-		score += avgProfile.SocialityScore * 0.3
-		score += avgProfile.ActivityScore * 0.3
-	case "chill":
-		score += (100 - avgProfile.ActivityScore) * 0.4
-		score += avgProfile.FlexibilityScore * 0.3
-		score += avgProfile.IntimacyScore * 0.3
-	default:
-		// 균형잡힌 점수
-		score = (avgProfile.SocialityScore + avgProfile.ActivityScore +
-			avgProfile.IntimacyScore + avgProfile.ImmersionScore +
-			avgProfile.FlexibilityScore) / 5.0
-	}
-
-	return score
+	return utils.Similarity(profileVector(&avgProfile), ClubVector(club))
 }
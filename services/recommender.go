@@ -0,0 +1,425 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"math"
+	"ongi-back/database"
+	"ongi-back/models"
+	"ongi-back/utils"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 추천 전략 이름 (RECOMMEND_WEIGHTS 키)
+const (
+	StrategyProfileVector  = "profile_vector"
+	StrategySimilarMembers = "similar_members"
+	StrategyPopularity     = "popularity"
+)
+
+// RecommendOptions 추천 조건 (빈 값이면 필터를 적용하지 않음)
+type RecommendOptions struct {
+	Location string // 지역 (부분 일치, 예: 강남)
+	Category string // 카테고리 (대소문자 무시 일치)
+}
+
+// RecommendTarget 추천 대상 (회원 또는 게스트 세션)
+type RecommendTarget struct {
+	ProfileSubject
+	Vector  *utils.Vector5D
+	Options RecommendOptions
+
+	similarUserIDs []uint
+	similarLoaded  bool
+}
+
+// NewRecommendTarget 저장된 성향 벡터로 추천 대상 생성
+func NewRecommendTarget(subject ProfileSubject, options RecommendOptions) (*RecommendTarget, error) {
+	v, err := subject.Vector()
+	if err != nil {
+		return nil, err
+	}
+	return &RecommendTarget{ProfileSubject: subject, Vector: v, Options: options}, nil
+}
+
+// SimilarUserIDs 성향이 비슷한 회원 ID (처음 호출할 때 한 번만 조회)
+func (t *RecommendTarget) SimilarUserIDs() ([]uint, error) {
+	if t.similarLoaded {
+		return t.similarUserIDs, nil
+	}
+
+	profiles, err := GetSimilarProfiles(t.ProfileSubject, 20)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	for _, profile := range profiles {
+		if profile.UserID != nil {
+			ids = append(ids, *profile.UserID)
+		}
+	}
+
+	t.similarUserIDs = ids
+	t.similarLoaded = true
+	return ids, nil
+}
+
+// Recommender 클럽 점수를 계산하는 추천 전략
+// 점수는 전략마다 단위가 달라도 되며 (엔진이 정규화), 점수가 없는 클럽은 0으로 취급
+type Recommender interface {
+	Name() string
+	Score(target *RecommendTarget, clubs []models.Club) (map[uint]float64, error)
+}
+
+// ClubFilter 추천 후보에서 클럽을 제외하는 필터
+type ClubFilter interface {
+	Name() string
+	Keep(target *RecommendTarget, club *models.Club) bool
+}
+
+var (
+	recommenderMu sync.RWMutex
+	recommenders  = map[string]Recommender{}
+	clubFilters   []ClubFilter
+)
+
+// RegisterRecommender 추천 전략 등록 (같은 이름이면 교체)
+func RegisterRecommender(r Recommender) {
+	recommenderMu.Lock()
+	defer recommenderMu.Unlock()
+	recommenders[r.Name()] = r
+}
+
+// GetRecommender 이름으로 추천 전략 조회
+func GetRecommender(name string) (Recommender, bool) {
+	recommenderMu.RLock()
+	defer recommenderMu.RUnlock()
+	r, ok := recommenders[name]
+	return r, ok
+}
+
+// RegisterClubFilter 추천 필터 등록 (같은 이름이면 교체)
+func RegisterClubFilter(f ClubFilter) {
+	recommenderMu.Lock()
+	defer recommenderMu.Unlock()
+	for i, existing := range clubFilters {
+		if existing.Name() == f.Name() {
+			clubFilters[i] = f
+			return
+		}
+	}
+	clubFilters = append(clubFilters, f)
+}
+
+func init() {
+	RegisterRecommender(&ProfileVectorRecommender{})
+	RegisterRecommender(&SimilarMembersRecommender{})
+	RegisterRecommender(&PopularityRecommender{})
+	RegisterClubFilter(&LocationFilter{})
+	RegisterClubFilter(&CategoryFilter{})
+}
+
+// ProfileVectorRecommender 성향 벡터와 클럽 선호 벡터의 유사도 (0-100)
+type ProfileVectorRecommender struct{}
+
+func (r *ProfileVectorRecommender) Name() string { return StrategyProfileVector }
+
+// Score 클럽별 벡터 유사도
+func (r *ProfileVectorRecommender) Score(target *RecommendTarget, clubs []models.Club) (map[uint]float64, error) {
+	scores := make(map[uint]float64, len(clubs))
+	if target.Vector == nil {
+		return scores, nil
+	}
+	for i := range clubs {
+		scores[clubs[i].ID] = utils.Similarity(target.Vector, ClubVector(&clubs[i]))
+	}
+	return scores, nil
+}
+
+// SimilarMembersRecommender 성향이 비슷한 회원이 많이 가입한 클럽 (협업 필터링)
+type SimilarMembersRecommender struct{}
+
+func (r *SimilarMembersRecommender) Name() string { return StrategySimilarMembers }
+
+// Score 클럽별 유사 회원 수
+func (r *SimilarMembersRecommender) Score(target *RecommendTarget, clubs []models.Club) (map[uint]float64, error) {
+	scores := make(map[uint]float64)
+
+	userIDs, err := target.SimilarUserIDs()
+	if err != nil || len(userIDs) == 0 || len(clubs) == 0 {
+		return scores, err
+	}
+
+	clubIDs := make([]uint, len(clubs))
+	for i, club := range clubs {
+		clubIDs[i] = club.ID
+	}
+
+	var counts []struct {
+		ClubID uint
+		Count  int64
+	}
+	if err := database.DB.Model(&models.ClubMember{}).
+		Select("club_id, COUNT(*) as count").
+		Where("user_id IN ? AND club_id IN ?", userIDs, clubIDs).
+		Group("club_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	for _, cc := range counts {
+		scores[cc.ClubID] = float64(cc.Count)
+	}
+	return scores, nil
+}
+
+// PopularityRecommender 멤버 수가 많은 클럽
+type PopularityRecommender struct{}
+
+func (r *PopularityRecommender) Name() string { return StrategyPopularity }
+
+// Score 클럽별 멤버 수
+func (r *PopularityRecommender) Score(target *RecommendTarget, clubs []models.Club) (map[uint]float64, error) {
+	scores := make(map[uint]float64, len(clubs))
+	for _, club := range clubs {
+		scores[club.ID] = float64(club.MemberCount)
+	}
+	return scores, nil
+}
+
+// LocationFilter 지역 조건과 일치하는 클럽만 추천
+type LocationFilter struct{}
+
+func (f *LocationFilter) Name() string { return "location" }
+
+// Keep 지역 조건이 없거나 클럽 지역에 포함되면 true
+func (f *LocationFilter) Keep(target *RecommendTarget, club *models.Club) bool {
+	location := strings.TrimSpace(target.Options.Location)
+	return location == "" || strings.Contains(strings.ToLower(club.Location), strings.ToLower(location))
+}
+
+// CategoryFilter 카테고리 조건과 일치하는 클럽만 추천
+type CategoryFilter struct{}
+
+func (f *CategoryFilter) Name() string { return "category" }
+
+// Keep 카테고리 조건이 없거나 일치하면 true
+func (f *CategoryFilter) Keep(target *RecommendTarget, club *models.Club) bool {
+	category := strings.TrimSpace(target.Options.Category)
+	return category == "" || strings.EqualFold(club.Category, category)
+}
+
+// StrategyScore 전략별 점수 기여도
+type StrategyScore struct {
+	Raw          float64 `json:"raw"`          // 전략이 계산한 원점수
+	Normalized   float64 `json:"normalized"`   // 후보 중 최고점 대비 비율 (0-1)
+	Weight       float64 `json:"weight"`       // 가중치 (전체 합 1로 환산)
+	Contribution float64 `json:"contribution"` // 최종 점수에 더해진 값 (0-100)
+}
+
+// ClubRecommendation 추천 결과 (클럽 정보 + 최종 점수 + 전략별 기여도)
+type ClubRecommendation struct {
+	models.Club
+	Score          float64                  `json:"score"` // 0-100
+	Contributions  map[string]StrategyScore `json:"contributions"`
+	MatchBreakdown []DimensionMatch         `json:"match_breakdown,omitempty"`
+}
+
+// RecommendationEngine 등록된 전략 점수를 정규화해 가중 합산
+type RecommendationEngine struct {
+	Weights map[string]float64 // 전략 이름 → 가중치 (0 이하이거나 등록되지 않은 전략은 무시)
+}
+
+// NewRecommendationEngine 가중치로 엔진 생성
+func NewRecommendationEngine(weights map[string]float64) *RecommendationEngine {
+	return &RecommendationEngine{Weights: weights}
+}
+
+// GlobalRecommendationEngine 회원/게스트 클럽 추천에 공통으로 사용하는 엔진
+// nil이면 처음 추천할 때 RECOMMEND_WEIGHTS로 생성 (.env는 config.Load에서 읽으므로 패키지 초기화 시점에는 만들지 않음)
+var (
+	GlobalRecommendationEngine *RecommendationEngine
+	globalEngineOnce           sync.Once
+)
+
+// recommendationEngine 전역 엔진 (처음 호출할 때 생성)
+func recommendationEngine() *RecommendationEngine {
+	globalEngineOnce.Do(func() {
+		if GlobalRecommendationEngine == nil {
+			GlobalRecommendationEngine = NewRecommendationEngine(LoadRecommendationWeights())
+		}
+	})
+	return GlobalRecommendationEngine
+}
+
+// defaultRecommendationWeights 기본 가중치
+var defaultRecommendationWeights = map[string]float64{
+	StrategyProfileVector:  0.6,
+	StrategySimilarMembers: 0.3,
+	StrategyPopularity:     0.1,
+}
+
+// LoadRecommendationWeights 환경 변수로 전략 가중치 로드
+// RECOMMEND_WEIGHTS="profile_vector=0.6,similar_members=0.3,popularity=0.1" (지정한 항목만 덮어씀)
+func LoadRecommendationWeights() map[string]float64 {
+	weights := make(map[string]float64, len(defaultRecommendationWeights))
+	for name, weight := range defaultRecommendationWeights {
+		weights[name] = weight
+	}
+
+	for _, pair := range strings.Split(os.Getenv("RECOMMEND_WEIGHTS"), ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 {
+			log.Printf("Invalid recommendation weight %q", pair)
+			continue
+		}
+		weights[strings.TrimSpace(name)] = weight
+	}
+	return weights
+}
+
+// activeStrategies 가중치가 있는 등록된 전략 (이름 순)
+func (e *RecommendationEngine) activeStrategies() ([]Recommender, float64) {
+	recommenderMu.RLock()
+	defer recommenderMu.RUnlock()
+
+	var strategies []Recommender
+	var total float64
+	for name, weight := range e.Weights {
+		r, ok := recommenders[name]
+		if !ok || weight <= 0 {
+			continue
+		}
+		strategies = append(strategies, r)
+		total += weight
+	}
+	sort.Slice(strategies, func(i, j int) bool {
+		return strategies[i].Name() < strategies[j].Name()
+	})
+	return strategies, total
+}
+
+// candidates 정원이 차지 않고 모든 필터를 통과한 클럽
+func (e *RecommendationEngine) candidates(target *RecommendTarget, clubs []models.Club) []models.Club {
+	recommenderMu.RLock()
+	filters := append([]ClubFilter(nil), clubFilters...)
+	recommenderMu.RUnlock()
+
+	result := make([]models.Club, 0, len(clubs))
+	for i := range clubs {
+		club := &clubs[i]
+		if club.MaxMembers > 0 && club.MemberCount >= club.MaxMembers {
+			continue
+		}
+		keep := true
+		for _, filter := range filters {
+			if !filter.Keep(target, club) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			result = append(result, *club)
+		}
+	}
+	return result
+}
+
+// Rank 후보 클럽을 최종 점수 높은 순으로 정렬 (동점이면 ID 순)
+func (e *RecommendationEngine) Rank(target *RecommendTarget, clubs []models.Club, limit int) ([]ClubRecommendation, error) {
+	candidates := e.candidates(target, clubs)
+	strategies, totalWeight := e.activeStrategies()
+
+	results := make([]ClubRecommendation, len(candidates))
+	for i, club := range candidates {
+		results[i] = ClubRecommendation{Club: club, Contributions: make(map[string]StrategyScore, len(strategies))}
+		if target.Vector != nil {
+			results[i].MatchBreakdown = MatchClub(target.Vector, club).MatchBreakdown
+		}
+	}
+
+	for _, strategy := range strategies {
+		scores, err := strategy.Score(target, candidates)
+		if err != nil {
+			return nil, err
+		}
+
+		// 후보 중 최고점으로 나눠 0-1로 정규화
+		var max float64
+		for _, score := range scores {
+			max = math.Max(max, score)
+		}
+
+		weight := e.Weights[strategy.Name()] / totalWeight
+		for i := range results {
+			raw := scores[results[i].ID]
+			normalized := 0.0
+			if max > 0 {
+				normalized = raw / max
+			}
+			contribution := normalized * weight * 100
+			results[i].Contributions[strategy.Name()] = StrategyScore{
+				Raw:          math.Round(raw*10) / 10,
+				Normalized:   math.Round(normalized*1000) / 1000,
+				Weight:       math.Round(weight*1000) / 1000,
+				Contribution: math.Round(contribution*10) / 10,
+			}
+			results[i].Score += contribution
+		}
+	}
+
+	for i := range results {
+		results[i].Score = math.Round(results[i].Score*10) / 10
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Recommend 전체 클럽을 조회해 추천 순위 계산
+func (e *RecommendationEngine) Recommend(target *RecommendTarget, limit int) ([]ClubRecommendation, error) {
+	var clubs []models.Club
+	if err := database.DB.Preload("Members").Find(&clubs).Error; err != nil {
+		return nil, err
+	}
+	return e.Rank(target, clubs, limit)
+}
+
+// RecommendClubs 회원/게스트 클럽 추천 (전략 점수를 합산한 단일 목록, 설문 전이면 빈 목록)
+func RecommendClubs(subject ProfileSubject, options RecommendOptions, limit int) ([]ClubRecommendation, error) {
+	target, err := NewRecommendTarget(subject, options)
+	if errors.Is(err, ErrNoPreferenceVector) {
+		return []ClubRecommendation{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return recommendationEngine().Recommend(target, limit)
+}
+
+// ClubsFromRecommendations 추천 결과에서 클럽 목록만 추출
+func ClubsFromRecommendations(recommendations []ClubRecommendation) []models.Club {
+	clubs := make([]models.Club, len(recommendations))
+	for i, rec := range recommendations {
+		clubs[i] = rec.Club
+	}
+	return clubs
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"ongi-back/models"
+	"reflect"
+	"testing"
+)
+
+// stubRecommender 고정된 점수를 반환하는 테스트용 전략
+type stubRecommender struct {
+	name   string
+	scores map[uint]float64
+	err    error
+}
+
+func (r *stubRecommender) Name() string { return r.name }
+
+func (r *stubRecommender) Score(target *RecommendTarget, clubs []models.Club) (map[uint]float64, error) {
+	return r.scores, r.err
+}
+
+// registerStubs 테스트용 전략을 등록하고 테스트가 끝나면 제거
+func registerStubs(t *testing.T, stubs ...*stubRecommender) {
+	t.Helper()
+	for _, stub := range stubs {
+		RegisterRecommender(stub)
+	}
+	t.Cleanup(func() {
+		recommenderMu.Lock()
+		defer recommenderMu.Unlock()
+		for _, stub := range stubs {
+			delete(recommenders, stub.name)
+		}
+	})
+}
+
+// testClub 테스트용 클럽
+func testClub(id uint, category, location string, members, max int) models.Club {
+	club := models.Club{Category: category, Location: location, MemberCount: members, MaxMembers: max}
+	club.ID = id
+	return club
+}
+
+// recommendationIDs 추천 결과의 클럽 ID 목록
+func recommendationIDs(recommendations []ClubRecommendation) []uint {
+	ids := make([]uint, len(recommendations))
+	for i, rec := range recommendations {
+		ids[i] = rec.ID
+	}
+	return ids
+}
+
+func TestRankNormalizesByMaxScore(t *testing.T) {
+	registerStubs(t, &stubRecommender{name: "test_max", scores: map[uint]float64{1: 10, 2: 5, 3: 0}})
+
+	engine := NewRecommendationEngine(map[string]float64{"test_max": 2})
+	clubs := []models.Club{testClub(1, "", "", 0, 0), testClub(2, "", "", 0, 0), testClub(3, "", "", 0, 0)}
+
+	results, err := engine.Rank(&RecommendTarget{}, clubs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[uint]StrategyScore{
+		1: {Raw: 10, Normalized: 1, Weight: 1, Contribution: 100},
+		2: {Raw: 5, Normalized: 0.5, Weight: 1, Contribution: 50},
+		3: {Raw: 0, Normalized: 0, Weight: 1, Contribution: 0},
+	}
+	for _, rec := range results {
+		if got := rec.Contributions["test_max"]; got != want[rec.ID] {
+			t.Errorf("club %d contribution = %+v, want %+v", rec.ID, got, want[rec.ID])
+		}
+		if rec.Score != want[rec.ID].Contribution {
+			t.Errorf("club %d score = %v, want %v", rec.ID, rec.Score, want[rec.ID].Contribution)
+		}
+	}
+	if got := recommendationIDs(results); !reflect.DeepEqual(got, []uint{1, 2, 3}) {
+		t.Errorf("order = %v, want [1 2 3]", got)
+	}
+}
+
+func TestRankWithAllZeroScores(t *testing.T) {
+	registerStubs(t, &stubRecommender{name: "test_zero", scores: map[uint]float64{}})
+
+	engine := NewRecommendationEngine(map[string]float64{"test_zero": 1})
+	results, err := engine.Rank(&RecommendTarget{}, []models.Club{testClub(1, "", "", 0, 0)}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Score != 0 || math.IsNaN(results[0].Contributions["test_zero"].Normalized) {
+		t.Fatalf("unexpected result: %+v", results)
+	}
+}
+
+func TestRankRescalesWeights(t *testing.T) {
+	registerStubs(t,
+		&stubRecommender{name: "test_a", scores: map[uint]float64{1: 1, 2: 0}},
+		&stubRecommender{name: "test_b", scores: map[uint]float64{1: 0, 2: 1}},
+		&stubRecommender{name: "test_disabled", scores: map[uint]float64{1: 100, 2: 100}},
+	)
+
+	// 등록되지 않은 전략과 가중치 0인 전략은 제외하고 나머지 합이 1이 되도록 환산
+	engine := NewRecommendationEngine(map[string]float64{
+		"test_a":        3,
+		"test_b":        1,
+		"test_disabled": 0,
+		"test_missing":  5,
+	})
+	clubs := []models.Club{testClub(1, "", "", 0, 0), testClub(2, "", "", 0, 0)}
+
+	results, err := engine.Rank(&RecommendTarget{}, clubs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := recommendationIDs(results); !reflect.DeepEqual(got, []uint{1, 2}) {
+		t.Fatalf("order = %v, want [1 2]", got)
+	}
+	if results[0].Score != 75 || results[1].Score != 25 {
+		t.Errorf("scores = %v, %v, want 75, 25", results[0].Score, results[1].Score)
+	}
+	for _, rec := range results {
+		if len(rec.Contributions) != 2 {
+			t.Errorf("club %d contributions = %v, want only test_a and test_b", rec.ID, rec.Contributions)
+		}
+		if rec.Contributions["test_a"].Weight != 0.75 || rec.Contributions["test_b"].Weight != 0.25 {
+			t.Errorf("club %d weights = %+v", rec.ID, rec.Contributions)
+		}
+	}
+}
+
+func TestRankAppliesFiltersAndCapacity(t *testing.T) {
+	registerStubs(t, &stubRecommender{name: "test_flat", scores: map[uint]float64{1: 1, 2: 1, 3: 1, 4: 1, 5: 1, 6: 1}})
+
+	engine := NewRecommendationEngine(map[string]float64{"test_flat": 1})
+	clubs := []models.Club{
+		testClub(1, "운동", "서울 강남구", 5, 10),
+		testClub(2, "운동", "서울 강남구", 10, 10), // 정원 마감
+		testClub(3, "독서", "서울 강남구", 0, 0),   // 카테고리 불일치
+		testClub(4, "운동", "서울 마포구", 0, 0),   // 지역 불일치
+		testClub(5, "운동", "서울 강남구", 50, 0),  // 정원 제한 없음
+		testClub(6, "운동", "서울 강남구", 11, 10), // 정원 초과
+	}
+
+	target := &RecommendTarget{Options: RecommendOptions{Location: "강남", Category: "운동"}}
+	results, err := engine.Rank(target, clubs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recommendationIDs(results); !reflect.DeepEqual(got, []uint{1, 5}) {
+		t.Errorf("filtered = %v, want [1 5]", got)
+	}
+
+	// 조건이 없으면 정원만 확인
+	results, err = engine.Rank(&RecommendTarget{}, clubs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recommendationIDs(results); !reflect.DeepEqual(got, []uint{1, 3, 4, 5}) {
+		t.Errorf("unfiltered = %v, want [1 3 4 5]", got)
+	}
+}
+
+func TestRankBreaksTiesByIDAndLimits(t *testing.T) {
+	registerStubs(t, &stubRecommender{name: "test_tie", scores: map[uint]float64{9: 2, 4: 1, 7: 2, 2: 1}})
+
+	engine := NewRecommendationEngine(map[string]float64{"test_tie": 1})
+	clubs := []models.Club{testClub(9, "", "", 0, 0), testClub(4, "", "", 0, 0), testClub(7, "", "", 0, 0), testClub(2, "", "", 0, 0)}
+
+	results, err := engine.Rank(&RecommendTarget{}, clubs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recommendationIDs(results); !reflect.DeepEqual(got, []uint{7, 9, 2, 4}) {
+		t.Errorf("order = %v, want [7 9 2 4]", got)
+	}
+
+	results, err = engine.Rank(&RecommendTarget{}, clubs, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recommendationIDs(results); !reflect.DeepEqual(got, []uint{7, 9, 2}) {
+		t.Errorf("limited = %v, want [7 9 2]", got)
+	}
+}
+
+func TestRankReturnsStrategyError(t *testing.T) {
+	failure := errors.New("strategy failed")
+	registerStubs(t, &stubRecommender{name: "test_error", err: failure})
+
+	engine := NewRecommendationEngine(map[string]float64{"test_error": 1})
+	if _, err := engine.Rank(&RecommendTarget{}, []models.Club{testClub(1, "", "", 0, 0)}, 0); !errors.Is(err, failure) {
+		t.Fatalf("err = %v, want %v", err, failure)
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoomArchived       = errors.New("chat room is archived")
+	ErrInvalidRoomName    = errors.New("invalid chat room name")
+	ErrDirectRoomSettings = errors.New("direct chat room settings cannot be changed")
+)
+
+// maxRoomNameLength 채팅방 이름 최대 글자 수
+const maxRoomNameLength = 50
+
+// UpdateRoomInput 채팅방 설정 변경 (nil인 항목은 변경하지 않음)
+type UpdateRoomInput struct {
+	Name        *string
+	Description *string
+	AvatarURL   *string // 빈 문자열이면 제거
+	Archived    *bool
+}
+
+// MemberSettingsInput 멤버별 채팅방 설정 (nil인 항목은 변경하지 않음)
+type MemberSettingsInput struct {
+	Pinned             *bool
+	NotificationsMuted *bool
+}
+
+// UpdateChatRoom 채팅방 이름/설명/대표 이미지/보관 상태 변경 (admin만 가능)
+// 보관된 채팅방은 보관 해제 외의 변경 불가
+func UpdateChatRoom(roomID, actorID uint, input UpdateRoomInput) (*models.ChatRoom, error) {
+	var room *models.ChatRoom
+	var notice *models.ChatMessage
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		room, _, err = requireAdmin(tx, roomID, actorID)
+		if err != nil {
+			return err
+		}
+		if room.RoomType == RoomTypeDirect {
+			return ErrDirectRoomSettings
+		}
+
+		// 보관된 채팅방은 보관 해제와 함께 요청한 경우에만 변경 가능
+		unarchive := input.Archived != nil && !*input.Archived && room.ArchivedAt != nil
+		if room.ArchivedAt != nil && !unarchive &&
+			(input.Name != nil || input.Description != nil || input.AvatarURL != nil) {
+			return ErrRoomArchived
+		}
+
+		updates := map[string]interface{}{}
+		if unarchive {
+			updates["archived_at"] = nil
+		} else if input.Archived != nil && *input.Archived && room.ArchivedAt == nil {
+			updates["archived_at"] = time.Now()
+		}
+
+		if input.Name != nil {
+			name := strings.TrimSpace(*input.Name)
+			if name == "" || utf8.RuneCountInString(name) > maxRoomNameLength {
+				return ErrInvalidRoomName
+			}
+			if name != room.Name {
+				updates["name"] = name
+				notice, err = createSystemMessage(tx, roomID, models.SystemPayload{
+					Event:   SystemEventRoomRenamed,
+					ActorID: actorID,
+					Params:  map[string]string{"old_name": room.Name, "new_name": name},
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if input.Description != nil {
+			updates["description"] = *input.Description
+		}
+		if input.AvatarURL != nil {
+			if *input.AvatarURL == "" {
+				updates["avatar_url"] = nil
+			} else {
+				updates["avatar_url"] = *input.AvatarURL
+			}
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.ChatRoom{}).Where("id = ?", roomID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if archivedAt, ok := updates["archived_at"]; ok {
+			action := "archive"
+			if archivedAt == nil {
+				action = "unarchive"
+			}
+			if err := writeModerationLog(tx, roomID, actorID, actorID, action, ""); err != nil {
+				return err
+			}
+		}
+
+		return tx.Preload("Creator").Preload("Club").First(room, roomID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if GlobalHub != nil {
+		GlobalHub.BroadcastMessage(roomID, "room_updated", actorID, room)
+	}
+	broadcastSystemMessages(notice)
+	return room, nil
+}
+
+// UpdateMemberSettings 본인의 채팅방 고정/알림 끄기 설정 변경
+func UpdateMemberSettings(roomID, userID uint, input MemberSettingsInput) (*models.ChatRoomMember, error) {
+	member, err := findRoomMember(database.DB, roomID, userID)
+	if err != nil {
+		return nil, ErrNotRoomMember
+	}
+
+	updates := map[string]interface{}{}
+	if input.Pinned != nil {
+		if *input.Pinned && member.PinnedAt == nil {
+			updates["pinned_at"] = time.Now()
+		} else if !*input.Pinned {
+			updates["pinned_at"] = nil
+		}
+	}
+	if input.NotificationsMuted != nil {
+		updates["notifications_muted"] = *input.NotificationsMuted
+	}
+
+	if len(updates) > 0 {
+		if err := database.DB.Model(member).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return findRoomMember(database.DB, roomID, userID)
+}
+
+// LeaveChatRoom 채팅방 나가기 (방장이면 다른 멤버에게 위임, 마지막 멤버면 채팅방 정리)
+func LeaveChatRoom(roomID, userID uint) error {
+	return RemoveChatMember(roomID, userID, userID)
+}
+
+// requireWritableRoom 보관된 채팅방은 읽기 전용이므로 ErrRoomArchived
+func requireWritableRoom(tx *gorm.DB, roomID uint) error {
+	var room models.ChatRoom
+	if err := tx.Select("id", "archived_at").First(&room, roomID).Error; err != nil {
+		return ErrChatRoomNotFound
+	}
+	if room.ArchivedAt != nil {
+		return ErrRoomArchived
+	}
+	return nil
+}
+
+// closeEmptyRoom 멤버가 없는 채팅방 정리
+// 사용자가 보낸 메시지가 있으면 보관하고, 없으면 채팅방을 삭제 (삭제하면 true)
+// 삭제한 채팅방의 첨부파일을 반환하며, 저장소 파일은 트랜잭션 커밋 후 호출한 쪽에서 삭제
+func closeEmptyRoom(tx *gorm.DB, room *models.ChatRoom) (bool, []models.ChatAttachment, error) {
+	var messages int64
+	if err := tx.Model(&models.ChatMessage{}).
+		Where("chat_room_id = ? AND message_type <> ?", room.ID, MessageTypeSystem).
+		Count(&messages).Error; err != nil {
+		return false, nil, err
+	}
+
+	if messages > 0 {
+		if room.ArchivedAt != nil {
+			return false, nil, nil
+		}
+		return false, nil, tx.Model(&models.ChatRoom{}).Where("id = ?", room.ID).Update("archived_at", time.Now()).Error
+	}
+
+	// 시스템 메시지와 관련 기록만 남은 채팅방은 삭제
+	if err := tx.Where("chat_message_id IN (?)",
+		tx.Model(&models.ChatMessage{}).Select("id").Where("chat_room_id = ?", room.ID)).
+		Delete(&models.ChatMessageReaction{}).Error; err != nil {
+		return false, nil, err
+	}
+
+	// 업로드만 하고 보내지 않은 첨부파일
+	var attachments []models.ChatAttachment
+	if err := tx.Where("chat_room_id = ?", room.ID).Find(&attachments).Error; err != nil {
+		return false, nil, err
+	}
+
+	for _, model := range []interface{}{
+		&models.ChatMessage{},
+		&models.ChatAttachment{},
+		&models.ChatRoomBan{},
+		&models.ChatModerationLog{},
+	} {
+		if err := tx.Where("chat_room_id = ?", room.ID).Delete(model).Error; err != nil {
+			return false, nil, err
+		}
+	}
+	if err := tx.Delete(&models.ChatRoom{}, room.ID).Error; err != nil {
+		return false, nil, err
+	}
+	return true, attachments, nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"testing"
+)
+
+// roomExists 채팅방이 남아 있는지와 보관 여부
+func roomExists(t *testing.T, roomID uint) (exists, archived bool) {
+	t.Helper()
+	var rooms []models.ChatRoom
+	if err := database.DB.Where("id = ?", roomID).Find(&rooms).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rooms) == 0 {
+		return false, false
+	}
+	return true, rooms[0].ArchivedAt != nil
+}
+
+func TestUpdateChatRoomArchive(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "settings", 2)
+	room := createTestRoom(t, users, []string{RoleAdmin, RoleMember})
+	admin, member := users[0].ID, users[1].ID
+
+	archived, unarchived := true, false
+	name, blank := "새 이름", "   "
+
+	if _, err := UpdateChatRoom(room.ID, member, UpdateRoomInput{Archived: &archived}); !errors.Is(err, ErrNotRoomAdmin) {
+		t.Errorf("member archive: err = %v, want ErrNotRoomAdmin", err)
+	}
+	if _, err := UpdateChatRoom(room.ID, admin, UpdateRoomInput{Name: &blank}); !errors.Is(err, ErrInvalidRoomName) {
+		t.Errorf("blank name: err = %v, want ErrInvalidRoomName", err)
+	}
+
+	updated, err := UpdateChatRoom(room.ID, admin, UpdateRoomInput{Archived: &archived})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ArchivedAt == nil {
+		t.Fatal("room was not archived")
+	}
+
+	// 보관된 채팅방은 읽기 전용
+	if _, err := UpdateChatRoom(room.ID, admin, UpdateRoomInput{Name: &name}); !errors.Is(err, ErrRoomArchived) {
+		t.Errorf("rename archived room: err = %v, want ErrRoomArchived", err)
+	}
+	if _, _, err := SendChatMessage(room.ID, member, SendMessageInput{Message: "hello"}); !errors.Is(err, ErrRoomArchived) {
+		t.Errorf("send to archived room: err = %v, want ErrRoomArchived", err)
+	}
+	message := createTestMessage(t, room.ID, member, "")
+	if _, err := EditChatMessage(room.ID, message.ID, member, "edited"); !errors.Is(err, ErrRoomArchived) {
+		t.Errorf("edit in archived room: err = %v, want ErrRoomArchived", err)
+	}
+	if _, err := DeleteChatMessage(room.ID, message.ID, member); !errors.Is(err, ErrRoomArchived) {
+		t.Errorf("delete in archived room: err = %v, want ErrRoomArchived", err)
+	}
+
+	// 보관 해제와 함께 요청한 변경은 적용
+	updated, err = UpdateChatRoom(room.ID, admin, UpdateRoomInput{Archived: &unarchived, Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ArchivedAt != nil || updated.Name != name {
+		t.Errorf("unarchive: archived_at=%v name=%q, want nil and %q", updated.ArchivedAt, updated.Name, name)
+	}
+
+	var actions []string
+	if err := database.DB.Model(&models.ChatModerationLog{}).Where("chat_room_id = ?", room.ID).
+		Order("id").Pluck("action", &actions).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 || actions[0] != "archive" || actions[1] != "unarchive" {
+		t.Errorf("moderation log = %v, want [archive unarchive]", actions)
+	}
+}
+
+func TestUpdateChatRoomRejectsDirectRoom(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "settingsdirect", 2)
+	room := createTestRoom(t, users, []string{RoleAdmin, RoleAdmin})
+	if err := database.DB.Model(&models.ChatRoom{}).Where("id = ?", room.ID).Update("room_type", RoomTypeDirect).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	name := "direct"
+	if _, err := UpdateChatRoom(room.ID, users[0].ID, UpdateRoomInput{Name: &name}); !errors.Is(err, ErrDirectRoomSettings) {
+		t.Errorf("err = %v, want ErrDirectRoomSettings", err)
+	}
+}
+
+func TestLeaveChatRoomClosesEmptyRoom(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "leave", 2)
+
+	cases := []struct {
+		name         string
+		userMessage  bool
+		wantExists   bool
+		wantArchived bool
+	}{
+		// 시스템 메시지만 있으면 삭제
+		{"only system messages", false, false, false},
+		// 사용자가 보낸 메시지가 있으면 보관
+		{"with user messages", true, true, true},
+	}
+	for _, tc := range cases {
+		room := createTestRoom(t, users, []string{RoleAdmin, RoleMember})
+		if _, err := PostSystemMessage(room.ID, models.SystemPayload{Event: SystemEventRoomCreated, ActorID: users[0].ID}); err != nil {
+			t.Fatal(err)
+		}
+		if tc.userMessage {
+			createTestMessage(t, room.ID, users[1].ID, "")
+		}
+
+		if err := LeaveChatRoom(room.ID, users[0].ID); err != nil {
+			t.Fatalf("%s: owner leave: %v", tc.name, err)
+		}
+		// 방장이 나가면 남은 멤버가 방장
+		if got := roomOwner(t, room.ID); got != users[1].ID {
+			t.Errorf("%s: owner = %d, want %d", tc.name, got, users[1].ID)
+		}
+		if exists, archived := roomExists(t, room.ID); !exists || archived {
+			t.Errorf("%s: room exists=%v archived=%v after first leave", tc.name, exists, archived)
+		}
+
+		if err := LeaveChatRoom(room.ID, users[1].ID); err != nil {
+			t.Fatalf("%s: last leave: %v", tc.name, err)
+		}
+		if exists, archived := roomExists(t, room.ID); exists != tc.wantExists || archived != tc.wantArchived {
+			t.Errorf("%s: room exists=%v archived=%v, want exists=%v archived=%v",
+				tc.name, exists, archived, tc.wantExists, tc.wantArchived)
+		}
+
+		if err := LeaveChatRoom(room.ID, users[1].ID); err == nil {
+			t.Errorf("%s: leaving twice succeeded", tc.name)
+		}
+	}
+}
+
+func TestUpdateMemberSettings(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "memberset", 2)
+	room := createTestRoom(t, users[:1], []string{RoleAdmin})
+	yes, no := true, false
+
+	member, err := UpdateMemberSettings(room.ID, users[0].ID, MemberSettingsInput{Pinned: &yes, NotificationsMuted: &yes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.PinnedAt == nil || !member.NotificationsMuted {
+		t.Errorf("pinned_at=%v muted=%v, want pinned and muted", member.PinnedAt, member.NotificationsMuted)
+	}
+	pinnedAt := *member.PinnedAt
+
+	// 이미 고정된 채팅방은 고정 시각을 유지
+	if member, err = UpdateMemberSettings(room.ID, users[0].ID, MemberSettingsInput{Pinned: &yes}); err != nil {
+		t.Fatal(err)
+	}
+	if member.PinnedAt == nil || !member.PinnedAt.Equal(pinnedAt) || !member.NotificationsMuted {
+		t.Errorf("re-pin changed settings: pinned_at=%v muted=%v", member.PinnedAt, member.NotificationsMuted)
+	}
+
+	if member, err = UpdateMemberSettings(room.ID, users[0].ID, MemberSettingsInput{Pinned: &no, NotificationsMuted: &no}); err != nil {
+		t.Fatal(err)
+	}
+	if member.PinnedAt != nil || member.NotificationsMuted {
+		t.Errorf("pinned_at=%v muted=%v, want unpinned and unmuted", member.PinnedAt, member.NotificationsMuted)
+	}
+
+	if _, err := UpdateMemberSettings(room.ID, users[1].ID, MemberSettingsInput{Pinned: &yes}); !errors.Is(err, ErrNotRoomMember) {
+		t.Errorf("non-member: err = %v, want ErrNotRoomMember", err)
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"errors"
+	"html"
+	"ongi-back/database"
+	"ongi-back/models"
+	"strings"
+	"time"
+)
+
+// ErrEmptySearchQuery 검색어 없음
+var ErrEmptySearchQuery = errors.New("search query is empty")
+
+// 검색 설정
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 50
+	maxSearchTerms     = 5
+	snippetRadius      = 40 // 검색어 앞뒤로 보여줄 글자 수 (rune)
+)
+
+// SearchQuery 메시지 검색 조건
+type SearchQuery struct {
+	Query    string
+	RoomID   uint       // 특정 채팅방만
+	AuthorID uint       // 특정 작성자만
+	From     *time.Time // 이 시간 이후
+	To       *time.Time // 이 시간 이전
+	BeforeID uint       // 페이지네이션 커서 (이 ID보다 이전 메시지)
+	Limit    int
+}
+
+// SearchHit 검색 결과 항목
+type SearchHit struct {
+	Message models.ChatMessage `json:"message"`
+	Snippet string             `json:"snippet"` // 검색어를 <mark>로 감싼 HTML 이스케이프된 발췌
+}
+
+// SearchResult 메시지 검색 결과 (최신순)
+type SearchResult struct {
+	Results      []SearchHit `json:"results"`
+	HasMore      bool        `json:"has_more"`
+	NextBeforeID uint        `json:"next_before_id,omitempty"` // 다음 페이지 요청 시 before_id
+}
+
+// SearchChatMessages 사용자가 속한 채팅방의 메시지 검색
+// 한국어는 형태소 분석 없이도 부분 일치가 되도록 ILIKE + pg_trgm GIN 인덱스를 사용 (공백으로 나눈 검색어는 모두 포함해야 함)
+func SearchChatMessages(userID uint, query SearchQuery) (*SearchResult, error) {
+	terms := searchTerms(query.Query)
+	if len(terms) == 0 {
+		return nil, ErrEmptySearchQuery
+	}
+	limit := normalizeLimit(query.Limit, DefaultSearchLimit, MaxSearchLimit)
+
+	db := database.DB.
+		Preload("User").
+		Preload("Attachment").
+		Where("deleted_at IS NULL").
+		Where("chat_room_id IN (?)",
+			database.DB.Model(&models.ChatRoomMember{}).Select("chat_room_id").Where("user_id = ?", userID))
+
+	for _, term := range terms {
+		db = db.Where(`message ILIKE ? ESCAPE '\'`, "%"+escapeLike(term)+"%")
+	}
+	if query.RoomID != 0 {
+		db = db.Where("chat_room_id = ?", query.RoomID)
+	}
+	if query.AuthorID != 0 {
+		db = db.Where("user_id = ?", query.AuthorID)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at < ?", *query.To)
+	}
+	if query.BeforeID != 0 {
+		db = db.Where("id < ?", query.BeforeID)
+	}
+
+	var messages []models.ChatMessage
+	if err := db.Order("id DESC").Limit(limit + 1).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{Results: []SearchHit{}}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		result.HasMore = true
+	}
+
+	for _, message := range messages {
+		result.Results = append(result.Results, SearchHit{
+			Message: message,
+			Snippet: highlightSnippet(message.Message, terms),
+		})
+	}
+	if result.HasMore {
+		result.NextBeforeID = messages[len(messages)-1].ID
+	}
+
+	return result, nil
+}
+
+// searchTerms 공백으로 검색어 분리 (중복 제거, 최대 maxSearchTerms개)
+func searchTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range strings.Fields(query) {
+		lower := strings.ToLower(term)
+		if seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		terms = append(terms, term)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// escapeLike LIKE 패턴 특수문자 이스케이프
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
+
+// highlightSnippet 첫 번째 일치 위치 주변을 잘라 검색어를 <mark>로 감싼 발췌 생성
+func highlightSnippet(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+
+	// 소문자 변환 후 길이가 달라지는 문자가 있으면 하이라이트 없이 앞부분만 사용
+	if len(lower) != len(runes) {
+		return html.EscapeString(truncateRunes(runes, 0, snippetRadius*2))
+	}
+
+	// 일치 구간 표시
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) != string(needle) {
+				continue
+			}
+			for j := i; j < i+len(needle); j++ {
+				marked[j] = true
+			}
+			if first == -1 || i < first {
+				first = i
+			}
+		}
+	}
+	if first == -1 {
+		first = 0
+	}
+
+	start := first - snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end := first + snippetRadius*2
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	inMark := false
+	for i := start; i < end; i++ {
+		if marked[i] && !inMark {
+			b.WriteString("<mark>")
+			inMark = true
+		} else if !marked[i] && inMark {
+			b.WriteString("</mark>")
+			inMark = false
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+	}
+	if inMark {
+		b.WriteString("</mark>")
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+// truncateRunes start부터 최대 n글자
+func truncateRunes(runes []rune, start, n int) string {
+	end := start + n
+	if end > len(runes) {
+		end = len(runes)
+	}
+	if end < len(runes) {
+		return string(runes[start:end]) + "…"
+	}
+	return string(runes[start:end])
+}
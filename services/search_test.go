@@ -0,0 +1,135 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"whitespace only", " \t\n ", nil},
+		{"single term", "모임", []string{"모임"}},
+		{"splits on any whitespace", "  등산\t모임\n일정 ", []string{"등산", "모임", "일정"}},
+		{"case-insensitive duplicates keep first spelling", "Go go GO golang", []string{"Go", "golang"}},
+		{"limited to max terms", "a b c d e f g", []string{"a", "b", "c", "d", "e"}},
+		{"duplicates do not count toward limit", "a a b b c d e f", []string{"a", "b", "c", "d", "e"}},
+		{"special characters kept as-is", `100% a_b c\d`, []string{"100%", "a_b", `c\d`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchTerms(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("searchTerms(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		term string
+		want string
+	}{
+		{"모임", "모임"},
+		{"100%", `100\%`},
+		{"a_b", `a\_b`},
+		{`c:\temp`, `c:\\temp`},
+		// 백슬래시를 먼저 이스케이프해야 \%가 \\\%가 됨
+		{`\%`, `\\\%`},
+		{`%_\`, `\%\_\\`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			if got := escapeLike(tt.term); got != tt.want {
+				t.Errorf("escapeLike(%q) = %q, want %q", tt.term, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	long := strings.Repeat("가", 60) + "모임" + strings.Repeat("나", 100)
+
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{
+			name:  "single match",
+			text:  "오늘 모임 어때요",
+			terms: []string{"모임"},
+			want:  "오늘 <mark>모임</mark> 어때요",
+		},
+		{
+			name:  "case-insensitive match keeps original text",
+			text:  "Hello GoLang",
+			terms: []string{"golang"},
+			want:  "Hello <mark>GoLang</mark>",
+		},
+		{
+			name:  "multiple terms and repeated matches",
+			text:  "등산 모임, 등산 후 식사",
+			terms: []string{"등산", "식사"},
+			want:  "<mark>등산</mark> 모임, <mark>등산</mark> 후 <mark>식사</mark>",
+		},
+		{
+			name:  "adjacent and overlapping matches merge into one mark",
+			text:  "abcd",
+			terms: []string{"ab", "bc", "cd"},
+			want:  "<mark>abcd</mark>",
+		},
+		{
+			name:  "html is escaped inside and outside marks",
+			text:  `<b>"a&b"</b>`,
+			terms: []string{"a&b"},
+			want:  "&lt;b&gt;&#34;<mark>a&amp;b</mark>&#34;&lt;/b&gt;",
+		},
+		{
+			name:  "like wildcards are matched literally",
+			text:  "할인 100% 적용, 1000 아님",
+			terms: []string{"100%"},
+			want:  "할인 <mark>100%</mark> 적용, 1000 아님",
+		},
+		{
+			name:  "lowercase changes byte length but not rune count",
+			text:  "İstanbul 여행",
+			terms: []string{"istanbul"},
+			want:  "<mark>İstanbul</mark> 여행",
+		},
+		{
+			name:  "kelvin sign folds to k",
+			text:  "\u212Aorea",
+			terms: []string{"korea"},
+			want:  "<mark>\u212Aorea</mark>",
+		},
+		{
+			name:  "no match shows beginning",
+			text:  "abc",
+			terms: []string{"xyz"},
+			want:  "abc",
+		},
+		{
+			name:  "long text is cut around first match",
+			text:  long,
+			terms: []string{"모임"},
+			want:  "…" + strings.Repeat("가", snippetRadius) + "<mark>모임</mark>" + strings.Repeat("나", snippetRadius*2-2) + "…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightSnippet(tt.text, tt.terms); got != tt.want {
+				t.Errorf("highlightSnippet(%q, %q)\n got  %q\n want %q", tt.text, tt.terms, got, tt.want)
+			}
+		})
+	}
+}
@@ -79,45 +79,6 @@ func SubmitGuestAnswers(sessionID string, answers []models.AnswerPayload) error
 	return nil
 }
 
-// CalculateGuestScores - 비회원 세션 점수 계산
-func CalculateGuestScores(sessionID string) (*ScoreResult, error) {
-	var answers []models.GuestAnswer
-
-	err := database.DB.Preload("Option").
-		Where("session_id = ?", sessionID).
-		Find(&answers).Error
-
-	if err != nil {
-		return nil, err
-	}
-
-	if len(answers) == 0 {
-		return nil, fmt.Errorf("no answers found for session")
-	}
-
-	scores := &ScoreResult{}
-	categoryScores := make(map[string][]int)
-
-	// 각 답변의 점수를 카테고리별로 분류
-	for _, answer := range answers {
-		weight := answer.Option.Weight
-		score := answer.Option.Score
-
-		if weight != "" {
-			categoryScores[weight] = append(categoryScores[weight], score)
-		}
-	}
-
-	// 카테고리별 평균 계산
-	scores.SocialityScore = calculateAverage(categoryScores["sociality"])
-	scores.ActivityScore = calculateAverage(categoryScores["activity"])
-	scores.IntimacyScore = calculateAverage(categoryScores["intimacy"])
-	scores.ImmersionScore = calculateAverage(categoryScores["immersion"])
-	scores.FlexibilityScore = calculateAverage(categoryScores["flexibility"])
-
-	return scores, nil
-}
-
 // SaveGuestResult - 비회원 세션 결과 저장
 func SaveGuestResult(sessionID string, scores *ScoreResult, profileType string, summary string) error {
 	return database.DB.Model(&models.GuestSession{}).
@@ -159,17 +120,27 @@ func CreateSessionVector(sessionID string, userID *uint, scores *ScoreResult) er
 
 	if result.Error == nil {
 		// 업데이트
-		return database.DB.Model(&existing).Updates(sessionVector).Error
+		err := database.DB.Model(&existing).Updates(sessionVector).Error
+		if err != nil {
+			return err
+		}
 	} else {
 		// 생성
-		return database.DB.Create(&sessionVector).Error
+		if err := database.DB.Create(&sessionVector).Error; err != nil {
+			return err
+		}
 	}
+
+	IndexSessionVector(sessionID, v)
+	return nil
 }
 
 // LinkSessionToUser - 세션을 사용자 계정과 연동
 func LinkSessionToUser(sessionID string, userID uint) error {
+	var profile models.UserProfile
+
 	// 트랜잭션으로 처리
-	return database.DB.Transaction(func(tx *gorm.DB) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
 		// 1. 세션을 사용자와 연결
 		err := tx.Model(&models.GuestSession{}).
 			Where("id = ?", sessionID).
@@ -210,7 +181,7 @@ func LinkSessionToUser(sessionID string, userID uint) error {
 			return err
 		}
 
-		profile := models.UserProfile{
+		profile = models.UserProfile{
 			UserID:           userID,
 			SocialityScore:   session.SocialityScore,
 			ActivityScore:    session.ActivityScore,
@@ -240,14 +211,33 @@ func LinkSessionToUser(sessionID string, userID uint) error {
 			Where("session_id = ?", sessionID).
 			Update("user_id", userID).Error
 	})
+	if err != nil {
+		return err
+	}
+
+	IndexMemberProfile(&profile)
+	return nil
 }
 
+// sessionVectorRetention 소프트 삭제된 세션 벡터를 영구 삭제하기 전까지 보관하는 기간
+// 메모리 인덱스 동기화(PROFILE_INDEX_REFRESH)가 삭제를 읽을 수 있도록 동기화 주기보다 충분히 길게 유지
+const sessionVectorRetention = 24 * time.Hour
+
 // CleanExpiredSessions - 만료된 세션 정리
 func CleanExpiredSessions() error {
 	now := time.Now()
 
+	// 인덱스에서 제거할 세션 ID
+	var expiredIDs []string
+	err := database.DB.Model(&models.GuestSession{}).
+		Where("expires_at < ? AND is_linked = false", now).
+		Pluck("id", &expiredIDs).Error
+	if err != nil {
+		return err
+	}
+
 	// 만료된 세션의 SessionVector 삭제
-	err := database.DB.
+	err = database.DB.
 		Where("session_id IN (?)",
 			database.DB.Model(&models.GuestSession{}).
 				Select("id").
@@ -258,6 +248,15 @@ func CleanExpiredSessions() error {
 	if err != nil {
 		return err
 	}
+	unindexSessions(expiredIDs)
+
+	// 다른 인스턴스의 인덱스에 삭제가 반영될 시간이 지난 세션 벡터 영구 삭제
+	err = database.DB.Unscoped().
+		Where("deleted_at < ?", now.Add(-sessionVectorRetention)).
+		Delete(&models.SessionVector{}).Error
+	if err != nil {
+		return err
+	}
 
 	// 만료된 세션의 GuestAnswer 삭제
 	err = database.DB.
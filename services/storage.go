@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrObjectNotFound 저장소에 파일이 없음
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage 첨부파일 저장소
+// 로컬 디스크(개발/테스트)와 S3 호환 저장소 구현을 교체해서 사용할 수 있음
+type Storage interface {
+	// Put key 위치에 파일 저장
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Open 저장된 파일 읽기 (호출한 쪽에서 Close)
+	Open(key string) (io.ReadCloser, error)
+	// Delete 파일 삭제
+	Delete(key string) error
+}
+
+// GlobalStorage 첨부파일 저장소 인스턴스
+var GlobalStorage Storage
+
+// LocalStorage 로컬 파일 시스템 저장소
+type LocalStorage struct {
+	Root string
+}
+
+// NewLocalStorage 로컬 저장소 생성 (디렉토리가 없으면 생성)
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{Root: root}, nil
+}
+
+// path key를 Root 아래 경로로 변환 ("/"를 기준으로 정리하므로 Root 밖으로 나갈 수 없음)
+func (s *LocalStorage) path(key string) (string, error) {
+	if strings.TrimSpace(key) == "" {
+		return "", fmt.Errorf("invalid storage key: %q", key)
+	}
+	return filepath.Join(s.Root, filepath.Clean("/"+key)), nil
+}
+
+// Put 파일 저장 (임시 파일에 쓴 뒤 rename)
+func (s *LocalStorage) Put(key string, r io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open 파일 열기
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+// Delete 파일 삭제
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// InitStorage 첨부파일 저장소 초기화 (local, s3)
+// local: CHAT_STORAGE_DIR (기본값 ./uploads)
+// s3: S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY
+func InitStorage(storageType string) error {
+	AttachmentConfig = LoadAttachmentConfig()
+
+	switch storageType {
+	case "s3":
+		storage, err := NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    getEnvDefault("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		})
+		if err != nil {
+			return err
+		}
+		GlobalStorage = storage
+
+	default:
+		storage, err := NewLocalStorage(getEnvDefault("CHAT_STORAGE_DIR", "./uploads"))
+		if err != nil {
+			return err
+		}
+		GlobalStorage = storage
+		storageType = "local"
+	}
+
+	log.Printf("Attachment storage initialized (%s)", storageType)
+	return nil
+}
+
+// getEnvDefault 환경 변수 조회 (없으면 기본값)
+func getEnvDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+	"ongi-back/database"
+	"ongi-back/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MessageTypeSystem 시스템 메시지 타입
+const MessageTypeSystem = "system"
+
+// 시스템 메시지 이벤트
+const (
+	SystemEventRoomCreated  = "room_created"
+	SystemEventMemberJoin   = "member_join"
+	SystemEventMemberLeave  = "member_leave"
+	SystemEventMemberKicked = "member_kicked"
+	SystemEventMemberBanned = "member_banned"
+	SystemEventRoleChanged  = "role_changed"
+	SystemEventOwnerChanged = "owner_changed"
+	SystemEventRoomRenamed  = "room_renamed"
+)
+
+// PostSystemMessage 시스템 메시지 저장 후 브로드캐스트 (트랜잭션 밖에서 사용)
+func PostSystemMessage(roomID uint, payload models.SystemPayload) (*models.ChatMessage, error) {
+	message, err := createSystemMessage(database.DB, roomID, payload)
+	if err != nil {
+		return nil, err
+	}
+	broadcastSystemMessages(message)
+	return message, nil
+}
+
+// createSystemMessage 시스템 메시지 저장 (콘텐츠 필터를 거치지 않으며 안 읽은 메시지 수에서 제외됨)
+// 브로드캐스트는 트랜잭션 커밋 후 broadcastSystemMessages로 전송
+func createSystemMessage(tx *gorm.DB, roomID uint, payload models.SystemPayload) (*models.ChatMessage, error) {
+	var users []models.User
+	if err := tx.Select("id", "name").Where("id IN ?", []uint{payload.ActorID, payload.TargetID}).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if user.ID == payload.ActorID {
+			payload.ActorName = user.Name
+		}
+		if user.ID == payload.TargetID {
+			payload.TargetName = user.Name
+		}
+	}
+
+	// user_id는 not null이므로 수행한 사용자가 없으면 대상 사용자로 저장
+	authorID := payload.ActorID
+	if authorID == 0 {
+		authorID = payload.TargetID
+	}
+
+	message := models.ChatMessage{
+		ChatRoomID:  roomID,
+		UserID:      authorID,
+		Message:     systemMessageText(payload),
+		MessageType: MessageTypeSystem,
+		System:      &payload,
+	}
+	if err := tx.Create(&message).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.ChatRoom{}).Where("id = ?", roomID).Updates(map[string]interface{}{
+		"last_message":    message.Message,
+		"last_message_at": time.Now(),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Preload("User").First(&message, message.ID).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// broadcastSystemMessages 저장된 시스템 메시지를 message 이벤트로 전송
+func broadcastSystemMessages(messages ...*models.ChatMessage) {
+	if GlobalHub == nil {
+		return
+	}
+	for _, message := range messages {
+		if message != nil {
+			GlobalHub.BroadcastMessage(message.ChatRoomID, "message", message.UserID, message)
+		}
+	}
+}
+
+// systemMessageText 시스템 메시지 기본 문구 (한국어)
+func systemMessageText(p models.SystemPayload) string {
+	switch p.Event {
+	case SystemEventRoomCreated:
+		return fmt.Sprintf("%s님이 채팅방을 만들었습니다", p.ActorName)
+	case SystemEventMemberJoin:
+		if p.ActorID != 0 && p.ActorID != p.TargetID {
+			return fmt.Sprintf("%s님이 %s님을 초대했습니다", p.ActorName, p.TargetName)
+		}
+		return fmt.Sprintf("%s님이 입장했습니다", p.TargetName)
+	case SystemEventMemberLeave:
+		return fmt.Sprintf("%s님이 나갔습니다", p.TargetName)
+	case SystemEventMemberKicked:
+		return fmt.Sprintf("%s님이 %s님을 내보냈습니다", p.ActorName, p.TargetName)
+	case SystemEventMemberBanned:
+		return fmt.Sprintf("%s님이 %s님을 차단했습니다", p.ActorName, p.TargetName)
+	case SystemEventRoleChanged:
+		if p.Params["role"] == RoleAdmin {
+			return fmt.Sprintf("%s님이 관리자가 되었습니다", p.TargetName)
+		}
+		return fmt.Sprintf("%s님의 관리자 권한이 해제되었습니다", p.TargetName)
+	case SystemEventOwnerChanged:
+		return fmt.Sprintf("%s님이 방장이 되었습니다", p.TargetName)
+	case SystemEventRoomRenamed:
+		return fmt.Sprintf("%s님이 채팅방 이름을 '%s'(으)로 변경했습니다", p.ActorName, p.Params["new_name"])
+	}
+	return p.Event
+}
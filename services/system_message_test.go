@@ -0,0 +1,103 @@
+package services
+
+import (
+	"ongi-back/database"
+	"ongi-back/models"
+	"testing"
+)
+
+func TestPostSystemMessage(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "system", 2)
+	room := createTestRoom(t, users, []string{RoleAdmin, RoleMember})
+	actor, target := users[0], users[1]
+
+	message, err := PostSystemMessage(room.ID, models.SystemPayload{
+		Event: SystemEventMemberJoin, ActorID: actor.ID, TargetID: target.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageType != MessageTypeSystem || message.UserID != actor.ID {
+		t.Errorf("message type=%q user=%d, want system by %d", message.MessageType, message.UserID, actor.ID)
+	}
+	if message.System == nil || message.System.ActorName != actor.Name || message.System.TargetName != target.Name {
+		t.Fatalf("payload = %+v, want names %q and %q", message.System, actor.Name, target.Name)
+	}
+	if want := actor.Name + "님이 " + target.Name + "님을 초대했습니다"; message.Message != want {
+		t.Errorf("message = %q, want %q", message.Message, want)
+	}
+
+	// 저장된 payload와 채팅방 마지막 메시지
+	var stored models.ChatMessage
+	if err := database.DB.First(&stored, message.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.System == nil || stored.System.Event != SystemEventMemberJoin || stored.System.TargetID != target.ID {
+		t.Errorf("stored payload = %+v", stored.System)
+	}
+	var updated models.ChatRoom
+	if err := database.DB.First(&updated, room.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if updated.LastMessage == nil || *updated.LastMessage != message.Message {
+		t.Errorf("room last_message = %v, want %q", updated.LastMessage, message.Message)
+	}
+
+	// 수행한 사용자가 없으면 대상 사용자를 작성자로 저장
+	message, err = PostSystemMessage(room.ID, models.SystemPayload{Event: SystemEventMemberLeave, TargetID: target.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.UserID != target.ID || message.Message != target.Name+"님이 나갔습니다" {
+		t.Errorf("leave message user=%d text=%q", message.UserID, message.Message)
+	}
+}
+
+// TestSystemMessagesExcludedFromUnread 시스템 메시지는 안 읽은 메시지 수와 메시지별 안 읽은 멤버 수에서 제외
+func TestSystemMessagesExcludedFromUnread(t *testing.T) {
+	openTestDB(t)
+	users := createTestUsers(t, "systemunread", 2)
+	room := createTestRoom(t, users, []string{RoleAdmin, RoleMember})
+	a, b := users[0].ID, users[1].ID
+
+	text := createTestMessage(t, room.ID, a, "")
+	// a가 수행한 이벤트와, 수행자 없이 b가 작성자로 저장되는 이벤트
+	promoted, err := PostSystemMessage(room.ID, models.SystemPayload{
+		Event: SystemEventRoleChanged, ActorID: a, TargetID: b, Params: map[string]string{"role": RoleAdmin},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	left, err := PostSystemMessage(room.ID, models.SystemPayload{Event: SystemEventMemberLeave, TargetID: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		user uint
+		want int
+	}{
+		{a, 0},
+		{b, 1},
+	} {
+		counts, err := GetUnreadCounts(tc.user, []uint{room.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if counts[room.ID] != tc.want {
+			t.Errorf("user %d unread = %d, want %d", tc.user, counts[room.ID], tc.want)
+		}
+	}
+
+	messages := []models.ChatMessage{text, *promoted, *left}
+	if err := FillUnreadMemberCounts(room.ID, messages); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{1, 0, 0} {
+		if messages[i].UnreadMemberCount != want {
+			t.Errorf("message %d (%s) unread members = %d, want %d",
+				messages[i].ID, messages[i].MessageType, messages[i].UnreadMemberCount, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"ongi-back/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// TokenPair Access Token + Refresh Token
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // Access Token 유효 기간 (초)
+}
+
+// IssueTokenPair 로그인 시 새 토큰 패밀리로 토큰 발급
+func IssueTokenPair(user models.User, userAgent string) (*TokenPair, error) {
+	familyID, err := GenerateSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	var pair *TokenPair
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var txErr error
+		pair, _, txErr = createTokenPair(tx, user, familyID, userAgent)
+		return txErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// RotateRefreshToken Refresh Token 회전 (기존 토큰 폐기 후 같은 패밀리로 재발급)
+// 이미 폐기된 토큰이 다시 사용되면 탈취로 간주하여 패밀리 전체를 폐기
+func RotateRefreshToken(rawToken string, userAgent string) (*TokenPair, *models.User, error) {
+	var pair *TokenPair
+	var user models.User
+	reused := false
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var stored models.RefreshToken
+		if err := tx.Preload("User").
+			Where("token_hash = ?", utils.HashToken(rawToken)).
+			First(&stored).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		if stored.RevokedAt != nil {
+			reused = true
+			return ErrRefreshTokenReused
+		}
+
+		if time.Now().After(stored.ExpiresAt) {
+			return ErrRefreshTokenExpired
+		}
+
+		newPair, newToken, err := createTokenPair(tx, stored.User, stored.FamilyID, userAgent)
+		if err != nil {
+			return err
+		}
+
+		// 기존 토큰을 폐기하고 대체 토큰 기록 (동시 요청 시 한 번만 성공)
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", stored.ID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": newToken.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return ErrRefreshTokenReused
+		}
+
+		pair = newPair
+		user = stored.User
+		return nil
+	})
+
+	if reused {
+		// 트랜잭션 밖에서 패밀리 전체 폐기 (롤백되지 않도록)
+		revokeFamilyByToken(rawToken)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pair, &user, nil
+}
+
+// RevokeRefreshToken 로그아웃 - 해당 Refresh Token의 패밀리 폐기
+func RevokeRefreshToken(rawToken string) error {
+	var stored models.RefreshToken
+	if err := database.DB.Where("token_hash = ?", utils.HashToken(rawToken)).First(&stored).Error; err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	return revokeFamily(stored.FamilyID)
+}
+
+// RevokeAllRefreshTokens 모든 기기에서 로그아웃 - 사용자의 모든 Refresh Token 폐기
+func RevokeAllRefreshTokens(userID uint) error {
+	return database.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// createTokenPair Access Token 생성 및 Refresh Token 저장
+func createTokenPair(tx *gorm.DB, user models.User, familyID string, userAgent string) (*TokenPair, *models.RefreshToken, error) {
+	accessToken, err := utils.GenerateJWT(user.ID, user.Email)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawRefresh, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(rawRefresh),
+		FamilyID:  familyID,
+		UserAgent: userAgent,
+		ExpiresAt: time.Now().Add(utils.RefreshTokenExpiry),
+	}
+
+	if err := tx.Create(&refreshToken).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: rawRefresh,
+		ExpiresIn:    int64(utils.AccessTokenExpiry.Seconds()),
+	}, &refreshToken, nil
+}
+
+// revokeFamilyByToken 토큰이 속한 패밀리 전체 폐기
+func revokeFamilyByToken(rawToken string) {
+	var stored models.RefreshToken
+	if err := database.DB.Where("token_hash = ?", utils.HashToken(rawToken)).First(&stored).Error; err != nil {
+		return
+	}
+	revokeFamily(stored.FamilyID)
+}
+
+// revokeFamily 패밀리의 모든 활성 토큰 폐기
+func revokeFamily(familyID string) error {
+	return database.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"ongi-back/database"
+	"ongi-back/models"
+	"ongi-back/utils"
+	"sync"
+	"testing"
+)
+
+// issueTestTokens 테스트 사용자에게 새 토큰 패밀리 발급 (테스트가 끝나면 토큰 삭제)
+func issueTestTokens(t *testing.T, user models.User) *TokenPair {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	pair, err := IssueTokenPair(user, "test-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		database.DB.Where("user_id = ?", user.ID).Delete(&models.RefreshToken{})
+	})
+	return pair
+}
+
+// storedRefreshToken 원문 토큰으로 저장된 Refresh Token 조회
+func storedRefreshToken(t *testing.T, rawToken string) models.RefreshToken {
+	t.Helper()
+	var stored models.RefreshToken
+	if err := database.DB.Where("token_hash = ?", utils.HashToken(rawToken)).First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	return stored
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	openTestDB(t)
+	user := createTestUsers(t, "token-rotate", 1)[0]
+	first := issueTestTokens(t, user)
+
+	second, rotatedUser, err := RotateRefreshToken(first.RefreshToken, "test-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotatedUser.ID != user.ID || second.RefreshToken == first.RefreshToken || second.AccessToken == "" {
+		t.Fatalf("unexpected rotation result: user=%d pair=%+v", rotatedUser.ID, second)
+	}
+
+	old := storedRefreshToken(t, first.RefreshToken)
+	replacement := storedRefreshToken(t, second.RefreshToken)
+	if old.RevokedAt == nil || old.ReplacedByID == nil || *old.ReplacedByID != replacement.ID {
+		t.Errorf("old token not revoked and linked: %+v", old)
+	}
+	if replacement.RevokedAt != nil || replacement.FamilyID != old.FamilyID {
+		t.Errorf("replacement should be active in the same family: %+v", replacement)
+	}
+
+	if _, _, err := RotateRefreshToken("unknown-token", "test-agent"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("unknown token: err=%v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRotateRefreshTokenReuseRevokesFamily(t *testing.T) {
+	openTestDB(t)
+	user := createTestUsers(t, "token-reuse", 1)[0]
+	first := issueTestTokens(t, user)
+	other := issueTestTokens(t, user) // 다른 기기의 로그인은 영향 없음
+
+	second, _, err := RotateRefreshToken(first.RefreshToken, "test-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := RotateRefreshToken(first.RefreshToken, "attacker"); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reused token: err=%v, want ErrRefreshTokenReused", err)
+	}
+	if stored := storedRefreshToken(t, second.RefreshToken); stored.RevokedAt == nil {
+		t.Error("token issued by the legitimate rotation should be revoked after reuse")
+	}
+	if _, _, err := RotateRefreshToken(second.RefreshToken, "test-agent"); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("revoked family token: err=%v, want ErrRefreshTokenReused", err)
+	}
+
+	if stored := storedRefreshToken(t, other.RefreshToken); stored.RevokedAt != nil {
+		t.Error("other token family should stay active")
+	}
+}
+
+func TestRotateRefreshTokenConcurrently(t *testing.T) {
+	openTestDB(t)
+	user := createTestUsers(t, "token-concurrent", 1)[0]
+	pair := issueTestTokens(t, user)
+
+	const attempts = 5
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = RotateRefreshToken(pair.RefreshToken, "test-agent")
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrRefreshTokenReused):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d rotations succeeded, want exactly 1", succeeded)
+	}
+
+	// 같은 토큰이 두 번 쓰였으므로 회전으로 발급된 토큰까지 패밀리 전체 폐기
+	family := storedRefreshToken(t, pair.RefreshToken).FamilyID
+	var active int64
+	database.DB.Model(&models.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", family).Count(&active)
+	if active != 0 {
+		t.Errorf("%d tokens still active in the reused family", active)
+	}
+}
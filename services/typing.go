@@ -0,0 +1,101 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTypingTimeout typing_stop 없이 이 시간이 지나면 자동으로 입력 종료 처리
+	DefaultTypingTimeout = 5 * time.Second
+
+	// typingMinInterval 클라이언트별 typing 프레임 최소 간격 (초과 프레임은 무시)
+	typingMinInterval = 500 * time.Millisecond
+)
+
+// TypingTracker 채팅방별 입력 중 상태 관리 (DB에 저장하지 않음)
+type TypingTracker struct {
+	mu      sync.Mutex
+	entries map[typingKey]*typingEntry
+	timeout time.Duration
+
+	// 상태 변경 시 호출 (typing=true: 입력 시작, false: 입력 종료)
+	onChange func(roomID, userID uint, typing bool)
+}
+
+type typingKey struct {
+	roomID uint
+	userID uint
+}
+
+type typingEntry struct {
+	timer      *time.Timer
+	generation int // 오래된 타이머 무시용
+}
+
+// NewTypingTracker TypingTracker 생성
+func NewTypingTracker(timeout time.Duration, onChange func(roomID, userID uint, typing bool)) *TypingTracker {
+	return &TypingTracker{
+		entries:  make(map[typingKey]*typingEntry),
+		timeout:  timeout,
+		onChange: onChange,
+	}
+}
+
+// Start 입력 시작 - 이미 입력 중이면 만료 시간만 연장
+func (t *TypingTracker) Start(roomID, userID uint) {
+	key := typingKey{roomID: roomID, userID: userID}
+
+	t.mu.Lock()
+	entry, typing := t.entries[key]
+	if !typing {
+		entry = &typingEntry{}
+		t.entries[key] = entry
+	} else {
+		entry.timer.Stop()
+	}
+
+	entry.generation++
+	generation := entry.generation
+	entry.timer = time.AfterFunc(t.timeout, func() {
+		t.expire(key, generation)
+	})
+	t.mu.Unlock()
+
+	if !typing && t.onChange != nil {
+		t.onChange(roomID, userID, true)
+	}
+}
+
+// Stop 입력 종료
+func (t *TypingTracker) Stop(roomID, userID uint) {
+	key := typingKey{roomID: roomID, userID: userID}
+
+	t.mu.Lock()
+	entry, typing := t.entries[key]
+	if typing {
+		entry.timer.Stop()
+		delete(t.entries, key)
+	}
+	t.mu.Unlock()
+
+	if typing && t.onChange != nil {
+		t.onChange(roomID, userID, false)
+	}
+}
+
+// expire 시간 초과로 입력 종료
+func (t *TypingTracker) expire(key typingKey, generation int) {
+	t.mu.Lock()
+	entry, typing := t.entries[key]
+	if !typing || entry.generation != generation {
+		t.mu.Unlock()
+		return
+	}
+	delete(t.entries, key)
+	t.mu.Unlock()
+
+	if t.onChange != nil {
+		t.onChange(key.roomID, key.userID, false)
+	}
+}
@@ -2,8 +2,14 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
+	"ongi-back/database"
+	"ongi-back/models"
+	"os"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/gofiber/websocket/v2"
 )
@@ -15,6 +21,71 @@ type Client struct {
 	Send     chan []byte
 	UserID   uint
 	RoomID   uint
+
+	lastTypingAt time.Time // typing 프레임 rate limit용
+}
+
+// ClientConfig WebSocket 클라이언트 연결 설정
+type ClientConfig struct {
+	WriteWait      time.Duration // 메시지 쓰기 제한 시간
+	PongWait       time.Duration // pong 응답 대기 시간 (초과 시 연결 종료)
+	PingInterval   time.Duration // ping 전송 주기 (PongWait보다 짧아야 함)
+	MaxMessageSize int64         // 클라이언트가 보낼 수 있는 최대 프레임 크기 (bytes)
+}
+
+const (
+	// maxEscapedRuneBytes JSON 문자열에서 글자 하나가 차지할 수 있는 최대 크기 (이모지를 "\uD83D\uDE00"처럼 escape한 경우)
+	maxEscapedRuneBytes = 12
+
+	// sendFrameOverhead send 프레임에서 메시지 본문을 제외한 필드(type, client_message_id, file_url 등) 여유분
+	sendFrameOverhead = 2048
+)
+
+// sendFrameSize 최대 길이(글자 수)의 메시지를 담은 send 프레임이 들어가는 프레임 크기
+// 콘텐츠 필터를 통과하는 메시지는 WebSocket에서도 프레임 제한에 걸리지 않아야 함 (HTTP 전송과 같은 규칙)
+func sendFrameSize(maxLength int) int64 {
+	return int64(maxLength)*maxEscapedRuneBytes + sendFrameOverhead
+}
+
+// DefaultClientConfig 기본 연결 설정
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		WriteWait:      10 * time.Second,
+		PongWait:       60 * time.Second,
+		PingInterval:   54 * time.Second,
+		MaxMessageSize: sendFrameSize(DefaultMaxMessageLength),
+	}
+}
+
+// LoadClientConfig 환경 변수로 기본 연결 설정 덮어쓰기
+// WS_WRITE_WAIT, WS_PONG_WAIT, WS_PING_INTERVAL (예: "30s"), WS_MAX_MESSAGE_SIZE (bytes)
+// WS_MAX_MESSAGE_SIZE는 CHAT_MAX_MESSAGE_LENGTH 길이의 메시지가 들어가는 크기보다 작게 설정할 수 없음
+func LoadClientConfig() ClientConfig {
+	config := DefaultClientConfig()
+
+	if d, err := time.ParseDuration(os.Getenv("WS_WRITE_WAIT")); err == nil && d > 0 {
+		config.WriteWait = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("WS_PONG_WAIT")); err == nil && d > 0 {
+		config.PongWait = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("WS_PING_INTERVAL")); err == nil && d > 0 {
+		config.PingInterval = d
+	}
+	if n, err := strconv.ParseInt(os.Getenv("WS_MAX_MESSAGE_SIZE"), 10, 64); err == nil && n > 0 {
+		config.MaxMessageSize = n
+	}
+
+	if min := sendFrameSize(LoadContentFilterConfig().MaxLength); config.MaxMessageSize < min {
+		config.MaxMessageSize = min
+	}
+
+	// ping은 pong 대기 시간 안에 도착해야 함
+	if config.PingInterval >= config.PongWait {
+		config.PingInterval = config.PongWait * 9 / 10
+	}
+
+	return config
 }
 
 // Hub WebSocket 연결 관리
@@ -29,25 +100,125 @@ type Hub struct {
 	Register   chan *Client
 	Unregister chan *Client
 
+	// 특정 클라이언트에게만 보내는 메시지 (ack, error 등)
+	Direct chan *DirectMessage
+
+	// 인스턴스 간 메시지 전달 (memory, postgres)
+	broker Broker
+
+	// 클라이언트 연결 설정 (heartbeat, deadline)
+	ClientConfig ClientConfig
+
+	// 채팅방별 접속 상태
+	Presence *PresenceTracker
+
+	// 채팅방별 입력 중 상태
+	Typing *TypingTracker
+
 	mu sync.RWMutex
 }
 
+// DirectMessage 특정 클라이언트 전용 메시지
+type DirectMessage struct {
+	Client  *Client
+	Message *Message
+}
+
 // Message WebSocket 메시지 구조
 type Message struct {
-	Type       string      `json:"type"` // message, read, member_join, member_leave
+	Type       string      `json:"type"` // message, read, member_join, member_leave, typing_start, typing_stop, ack, error
 	RoomID     uint        `json:"room_id"`
 	UserID     uint        `json:"user_id"`
 	Data       interface{} `json:"data"`
+
+	// Hub 내부 필드 - 클라이언트에는 전송되지 않으며 브로커로는 brokerEnvelope에 담아 전달
+	ExcludeUserID    uint `json:"-"` // 이 사용자에게는 전송하지 않음 (본인 이벤트)
+	DisconnectUserID uint `json:"-"` // 전송 후 이 사용자의 연결을 종료 (강퇴/나가기)
+
+	// 브로커 전달용 참조 - Data 대신 ID만 보내고 받는 Hub가 DB에서 다시 조회
+	Ref *MessageRef `json:"-"`
 }
 
-// NewHub Hub 생성
+// MessageRef 브로커로 전달할 때 Data를 대신하는 DB 레코드 참조
+// DB에 저장되지 않고 조회 시 계산되는 메시지 필드는 함께 전달
+type MessageRef struct {
+	Kind              string                   `json:"kind"` // chat_message, chat_room
+	ID                uint                     `json:"id"`
+	Reactions         []models.ReactionSummary `json:"reactions,omitempty"`
+	UnreadMemberCount int                      `json:"unread_member_count,omitempty"`
+}
+
+// IncomingFrame 클라이언트로부터 받은 메시지 구조
+type IncomingFrame struct {
+	Type   string          `json:"type"` // send, typing_start, typing_stop
+	RoomID uint            `json:"room_id"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// SendFrameData send 프레임 데이터
+type SendFrameData struct {
+	ClientMessageID string `json:"client_message_id"` // 클라이언트 생성 멱등성 키
+	Message         string `json:"message"`
+	MessageType     string `json:"message_type"`
+	FileURL         string `json:"file_url"`
+	AttachmentID    uint   `json:"attachment_id"`
+	ReplyToID       uint   `json:"reply_to_id"`
+}
+
+// NewHub 단일 인스턴스용 Hub 생성 (MemoryBroker 사용)
 func NewHub() *Hub {
-	return &Hub{
+	hub, _ := NewHubWithBroker(NewMemoryBroker())
+	return hub
+}
+
+// NewHubWithBroker 지정한 브로커를 사용하는 Hub 생성
+// 브로커로 전달된 메시지는 이 Hub에 연결된 클라이언트에게 브로드캐스트됨
+func NewHubWithBroker(broker Broker) (*Hub, error) {
+	hub := &Hub{
 		Rooms:      make(map[uint]map[*Client]bool),
 		Broadcast:  make(chan *Message, 256),
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
+		Direct:     make(chan *DirectMessage, 256),
+		broker:     broker,
+
+		ClientConfig: DefaultClientConfig(),
+	}
+
+	hub.Presence = NewPresenceTracker(DefaultPresenceDebounce, func(roomID, userID uint, online bool) {
+		msgType, status := "member_online", "online"
+		if !online {
+			msgType, status = "member_offline", "offline"
+		}
+		hub.BroadcastMessage(roomID, msgType, userID, map[string]interface{}{
+			"user_id": userID,
+			"status":  status,
+		})
+	})
+
+	hub.Typing = NewTypingTracker(DefaultTypingTimeout, func(roomID, userID uint, typing bool) {
+		msgType := "typing_start"
+		if !typing {
+			msgType = "typing_stop"
+		}
+		hub.BroadcastEphemeral(roomID, msgType, userID, map[string]interface{}{
+			"user_id": userID,
+		})
+	})
+
+	if err := broker.Subscribe(func(msg *Message) {
+		if msg.Ref != nil {
+			if err := resolveMessageRef(msg); err != nil {
+				log.Printf("Failed to load broker message: Type=%s, Ref=%s/%d, err=%v", msg.Type, msg.Ref.Kind, msg.Ref.ID, err)
+				return
+			}
+		}
+		hub.Broadcast <- msg
+	}); err != nil {
+		return nil, err
 	}
+
+	return hub, nil
 }
 
 // Run Hub 실행
@@ -78,36 +249,94 @@ func (h *Hub) Run() {
 			log.Printf("Client unregistered: UserID=%d, RoomID=%d", client.UserID, client.RoomID)
 
 		case message := <-h.Broadcast:
-			h.mu.RLock()
+			h.mu.Lock()
 			if clients, ok := h.Rooms[message.RoomID]; ok {
 				messageBytes, err := json.Marshal(message)
 				if err != nil {
 					log.Printf("Error marshaling message: %v", err)
-					h.mu.RUnlock()
+					h.mu.Unlock()
 					continue
 				}
 
 				for client := range clients {
+					if message.ExcludeUserID != 0 && client.UserID == message.ExcludeUserID {
+						continue
+					}
+
 					select {
 					case client.Send <- messageBytes:
+						// 채팅방에서 제거된 사용자: 이 이벤트까지 전송한 뒤 연결 종료
+						if message.DisconnectUserID != 0 && client.UserID == message.DisconnectUserID {
+							close(client.Send)
+							delete(clients, client)
+							if len(clients) == 0 {
+								delete(h.Rooms, message.RoomID)
+							}
+						}
 					default:
+						// 느린 클라이언트: Send를 닫으면 WritePump가 연결을 종료하고
+						// ReadPump가 끝나면서 member_offline이 전송됨
+						log.Printf("Client send buffer full, dropping: UserID=%d, RoomID=%d", client.UserID, client.RoomID)
 						close(client.Send)
 						delete(clients, client)
+						if len(clients) == 0 {
+							delete(h.Rooms, message.RoomID)
+						}
 					}
 				}
 			}
+			h.mu.Unlock()
+
+		case direct := <-h.Direct:
+			h.mu.RLock()
+			if clients, ok := h.Rooms[direct.Client.RoomID]; ok && clients[direct.Client] {
+				messageBytes, err := json.Marshal(direct.Message)
+				if err != nil {
+					log.Printf("Error marshaling message: %v", err)
+					h.mu.RUnlock()
+					continue
+				}
+
+				select {
+				case direct.Client.Send <- messageBytes:
+				default:
+					log.Printf("Client send buffer full: UserID=%d, RoomID=%d", direct.Client.UserID, direct.Client.RoomID)
+				}
+			}
 			h.mu.RUnlock()
 		}
 	}
 }
 
+// Serve WritePump를 시작하고 ReadPump가 끝나면 WritePump 종료까지 대기
+// 핸들러가 반환되면 Conn이 재사용되므로 WritePump가 끝난 뒤에 반환해야 함
+func (c *Client) Serve() {
+	writeDone := make(chan struct{})
+	go func() {
+		defer close(writeDone)
+		c.WritePump()
+	}()
+
+	// ReadPump가 끝나면 Hub에서 해제되어 Send가 닫히므로 WritePump도 종료됨
+	c.ReadPump()
+	<-writeDone
+}
+
 // ReadPump 클라이언트로부터 메시지 읽기
+// PongWait 안에 pong(또는 메시지)이 오지 않으면 연결을 끊고 Hub에서 해제
 func (c *Client) ReadPump() {
 	defer func() {
 		c.Hub.Unregister <- c
 		c.Conn.Close()
 	}()
 
+	config := c.Hub.ClientConfig
+	c.Conn.SetReadLimit(config.MaxMessageSize)
+	c.Conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		return c.Conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	})
+
 	for {
 		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
@@ -117,57 +346,149 @@ func (c *Client) ReadPump() {
 			break
 		}
 
+		// 메시지를 받은 경우에도 연결이 살아있는 것으로 간주
+		c.Conn.SetReadDeadline(time.Now().Add(config.PongWait))
+
 		// 클라이언트로부터 받은 메시지 처리
-		var msg Message
-		if err := json.Unmarshal(message, &msg); err != nil {
+		var frame IncomingFrame
+		if err := json.Unmarshal(message, &frame); err != nil {
 			log.Printf("Error unmarshaling message: %v", err)
 			continue
 		}
 
-		// 메시지 유효성 검증
-		if msg.RoomID != c.RoomID {
-			log.Printf("Invalid room ID: expected=%d, got=%d", c.RoomID, msg.RoomID)
+		// 메시지 유효성 검증 (room_id 생략 시 연결된 채팅방)
+		if frame.RoomID != 0 && frame.RoomID != c.RoomID {
+			log.Printf("Invalid room ID: expected=%d, got=%d", c.RoomID, frame.RoomID)
 			continue
 		}
 
-		// 브로드캐스트 (서버에서 처리 후 다시 보내는 방식이므로 여기서는 무시)
-		// 실제 메시지는 HTTP API를 통해 저장되고, 저장 후 Hub를 통해 브로드캐스트됨
+		switch frame.Type {
+		case "send":
+			c.handleSend(frame.Data)
+		case "typing_start", "typing_stop":
+			c.handleTyping(frame.Type)
+		default:
+			log.Printf("Unknown frame type: %s", frame.Type)
+		}
 	}
 }
 
+// handleSend send 프레임 처리 - 메시지 저장 후 보낸 사람에게 ack 전송
+func (c *Client) handleSend(data json.RawMessage) {
+	var payload SendFrameData
+	if err := json.Unmarshal(data, &payload); err != nil {
+		c.sendError("", "Invalid send payload")
+		return
+	}
+
+	if payload.ClientMessageID == "" {
+		c.sendError("", "client_message_id is required")
+		return
+	}
+
+	message, duplicate, err := SendChatMessage(c.RoomID, c.UserID, SendMessageInput{
+		Message:         payload.Message,
+		MessageType:     payload.MessageType,
+		FileURL:         payload.FileURL,
+		AttachmentID:    payload.AttachmentID,
+		ClientMessageID: payload.ClientMessageID,
+		ReplyToID:       payload.ReplyToID,
+	})
+	if err != nil {
+		// 속도 제한은 다시 보낼 수 있는 시간을 함께 전달
+		var rateErr *RateLimitError
+		if errors.As(err, &rateErr) {
+			c.Hub.SendToClient(c, "error", map[string]interface{}{
+				"client_message_id": payload.ClientMessageID,
+				"error":             err.Error(),
+				"code":              "rate_limited",
+				"retry_after_ms":    rateErr.RetryAfter.Milliseconds(),
+			})
+			return
+		}
+		c.sendError(payload.ClientMessageID, err.Error())
+		return
+	}
+
+	c.Hub.SendToClient(c, "ack", map[string]interface{}{
+		"client_message_id": payload.ClientMessageID,
+		"message_id":        message.ID,
+		"created_at":        message.CreatedAt,
+		"duplicate":         duplicate,
+	})
+}
+
+// handleTyping 입력 중 프레임 처리
+// typing_start는 클라이언트별로 typingMinInterval에 한 번만 처리 (stop은 항상 처리)
+func (c *Client) handleTyping(frameType string) {
+	if frameType == "typing_stop" {
+		c.Hub.Typing.Stop(c.RoomID, c.UserID)
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(c.lastTypingAt) < typingMinInterval {
+		return
+	}
+	c.lastTypingAt = now
+
+	c.Hub.Typing.Start(c.RoomID, c.UserID)
+}
+
+// sendError 보낸 사람에게 에러 전송
+func (c *Client) sendError(clientMessageID string, errMsg string) {
+	c.Hub.SendToClient(c, "error", map[string]interface{}{
+		"client_message_id": clientMessageID,
+		"error":             errMsg,
+	})
+}
+
 // WritePump 클라이언트로 메시지 쓰기
+// 주기적으로 ping을 보내고, 쓰기가 WriteWait을 넘기면 연결 종료
 func (c *Client) WritePump() {
+	config := c.Hub.ClientConfig
+	ticker := time.NewTicker(config.PingInterval)
 	defer func() {
+		ticker.Stop()
 		c.Conn.Close()
 	}()
 
 	for {
-		message, ok := <-c.Send
-		if !ok {
-			c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
-			return
-		}
+		select {
+		case message, ok := <-c.Send:
+			c.Conn.SetWriteDeadline(time.Now().Add(config.WriteWait))
+			if !ok {
+				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
 
-		w, err := c.Conn.NextWriter(websocket.TextMessage)
-		if err != nil {
-			return
-		}
-		w.Write(message)
+			w, err := c.Conn.NextWriter(websocket.TextMessage)
+			if err != nil {
+				return
+			}
+			w.Write(message)
 
-		// 대기 중인 메시지들도 한번에 전송
-		n := len(c.Send)
-		for i := 0; i < n; i++ {
-			w.Write([]byte{'\n'})
-			w.Write(<-c.Send)
-		}
+			// 대기 중인 메시지들도 한번에 전송
+			n := len(c.Send)
+			for i := 0; i < n; i++ {
+				w.Write([]byte{'\n'})
+				w.Write(<-c.Send)
+			}
 
-		if err := w.Close(); err != nil {
-			return
+			if err := w.Close(); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.Conn.SetWriteDeadline(time.Now().Add(config.WriteWait))
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		}
 	}
 }
 
-// BroadcastMessage 메시지 브로드캐스트
+// BroadcastMessage 메시지 브로드캐스트 (브로커를 통해 모든 인스턴스에 전달)
 func (h *Hub) BroadcastMessage(roomID uint, msgType string, userID uint, data interface{}) {
 	message := &Message{
 		Type:   msgType,
@@ -175,15 +496,90 @@ func (h *Hub) BroadcastMessage(roomID uint, msgType string, userID uint, data in
 		UserID: userID,
 		Data:   data,
 	}
-	h.Broadcast <- message
+
+	if err := h.broker.Publish(message); err != nil {
+		// 브로커 전달 실패 시 최소한 이 인스턴스의 클라이언트에게는 전달
+		log.Printf("Broker publish failed, delivering locally: %v", err)
+		h.Broadcast <- message
+	}
+}
+
+// BroadcastAndDisconnect 브로드캐스트 후 disconnectUserID의 연결 종료 (모든 인스턴스에 적용)
+func (h *Hub) BroadcastAndDisconnect(roomID uint, msgType string, userID uint, data interface{}, disconnectUserID uint) {
+	message := &Message{
+		Type:             msgType,
+		RoomID:           roomID,
+		UserID:           userID,
+		Data:             data,
+		DisconnectUserID: disconnectUserID,
+	}
+
+	if err := h.broker.Publish(message); err != nil {
+		log.Printf("Broker publish failed, delivering locally: %v", err)
+		h.Broadcast <- message
+	}
+}
+
+// BroadcastEphemeral 저장하지 않는 이벤트를 보낸 사람을 제외한 멤버에게 브로드캐스트
+func (h *Hub) BroadcastEphemeral(roomID uint, msgType string, userID uint, data interface{}) {
+	message := &Message{
+		Type:          msgType,
+		RoomID:        roomID,
+		UserID:        userID,
+		Data:          data,
+		ExcludeUserID: userID,
+	}
+
+	if err := h.broker.Publish(message); err != nil {
+		log.Printf("Broker publish failed, delivering locally: %v", err)
+		h.Broadcast <- message
+	}
+}
+
+// Close 브로커 구독 종료
+func (h *Hub) Close() error {
+	return h.broker.Close()
+}
+
+// SendToClient 특정 클라이언트에게만 메시지 전송
+func (h *Hub) SendToClient(client *Client, msgType string, data interface{}) {
+	h.Direct <- &DirectMessage{
+		Client: client,
+		Message: &Message{
+			Type:   msgType,
+			RoomID: client.RoomID,
+			UserID: client.UserID,
+			Data:   data,
+		},
+	}
 }
 
 // 전역 Hub 인스턴스
 var GlobalHub *Hub
 
 // InitHub Hub 초기화
-func InitHub() {
-	GlobalHub = NewHub()
+// brokerType: "memory" (기본값, 단일 인스턴스) 또는 "postgres" (LISTEN/NOTIFY, 다중 인스턴스)
+func InitHub(brokerType string) {
+	var broker Broker = NewMemoryBroker()
+	if brokerType == "postgres" {
+		broker = NewPostgresBroker(database.DB, database.DSN(), DefaultBrokerChannel)
+	}
+
+	hub, err := NewHubWithBroker(broker)
+	if err != nil {
+		log.Printf("Failed to start %s broker, falling back to memory: %v", brokerType, err)
+		hub = NewHub()
+		brokerType = "memory"
+	}
+
+	// 여러 인스턴스가 접속 상태를 공유하도록 DB에 기록
+	if brokerType == "postgres" {
+		hub.Presence.UseStore(NewPostgresPresenceStore(database.DB, newInstanceID(), DefaultPresenceTTL))
+		hub.Presence.StartHeartbeat(DefaultPresenceHeartbeat)
+	}
+
+	hub.ClientConfig = LoadClientConfig()
+	GlobalHub = hub
 	go GlobalHub.Run()
-	log.Println("WebSocket Hub initialized")
+	log.Printf("WebSocket Hub initialized (broker: %s)", brokerType)
 }
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	fastws "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+)
+
+// startTestWebSocketServer 테스트용 Hub와 WebSocket 서버 시작 (ws URL 반환)
+func startTestWebSocketServer(t *testing.T, config ClientConfig) (*Hub, string) {
+	t.Helper()
+
+	hub := NewHub()
+	hub.ClientConfig = config
+	go hub.Run()
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ws", websocket.New(func(conn *websocket.Conn) {
+		client := &Client{Hub: hub, Conn: conn, Send: make(chan []byte, 256), UserID: 1, RoomID: 1}
+		hub.Register <- client
+		client.Serve()
+	}))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(ln)
+	t.Cleanup(func() { app.Shutdown() })
+
+	return hub, "ws://" + ln.Addr().String() + "/ws"
+}
+
+func testClientConfig() ClientConfig {
+	return ClientConfig{
+		WriteWait:      time.Second,
+		PongWait:       400 * time.Millisecond,
+		PingInterval:   100 * time.Millisecond,
+		MaxMessageSize: 8 * 1024,
+	}
+}
+
+func connectedClients(hub *Hub) int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	return len(hub.Rooms[1])
+}
+
+// TestReadPumpClosesSilentPeer ping에 응답하지 않는 클라이언트는 PongWait 안에 연결이 끊기고 Hub에서 해제됨
+func TestReadPumpClosesSilentPeer(t *testing.T) {
+	config := testClientConfig()
+	hub, url := startTestWebSocketServer(t, config)
+
+	conn, _, err := fastws.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// pong을 보내지 않는 peer (half-open 연결)
+	conn.SetPingHandler(func(string) error { return nil })
+
+	start := time.Now()
+	conn.SetReadDeadline(start.Add(5 * config.PongWait))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+
+	// 연결 후 ReadDeadline이 걸리므로 PongWait과 약간의 처리 시간 안에 종료되어야 함
+	if elapsed := time.Since(start); elapsed > config.PongWait+200*time.Millisecond {
+		t.Fatalf("server closed silent peer after %s, want within %s", elapsed, config.PongWait)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for connectedClients(hub) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("silent peer was not unregistered from hub")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestReadPumpKeepsRespondingPeer pong에 응답하는 클라이언트는 PongWait이 지나도 연결 유지
+func TestReadPumpKeepsRespondingPeer(t *testing.T) {
+	config := testClientConfig()
+	hub, url := startTestWebSocketServer(t, config)
+
+	conn, _, err := fastws.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// 기본 ping 핸들러가 pong으로 응답 (읽기 중에만 처리되므로 계속 읽음)
+	closed := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-closed:
+		t.Fatalf("responding peer was disconnected: %v", err)
+	case <-time.After(3 * config.PongWait):
+	}
+
+	if connectedClients(hub) != 1 {
+		t.Fatal("responding peer is not registered")
+	}
+}
+
+// TestMaxMessageSizeFitsLongestMessage 콘텐츠 필터가 허용하는 가장 긴 메시지는 escape해서 보내도 프레임 제한 안에 들어감
+func TestMaxMessageSizeFitsLongestMessage(t *testing.T) {
+	t.Setenv("WS_MAX_MESSAGE_SIZE", "8192")
+	t.Setenv("CHAT_MAX_MESSAGE_LENGTH", "")
+	config := LoadClientConfig()
+
+	// "😀"를 모두 \uD83D\uDE00으로 escape한 send 프레임
+	data, _ := json.Marshal(SendFrameData{
+		ClientMessageID: strings.Repeat("c", 64),
+		FileURL:         "https://example.com/" + strings.Repeat("f", 200),
+	})
+	text := strings.Repeat(`\uD83D\uDE00`, DefaultMaxMessageLength)
+	frame := `{"type":"send","room_id":4294967295,"data":` +
+		strings.Replace(string(data), `"message":""`, `"message":"`+text+`"`, 1) + `}`
+
+	var parsed IncomingFrame
+	var payload SendFrameData
+	if err := json.Unmarshal([]byte(frame), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(parsed.Data, &payload); err != nil || payload.Message != strings.Repeat("😀", DefaultMaxMessageLength) {
+		t.Fatalf("unexpected frame payload: %v", err)
+	}
+	if int64(len(frame)) > config.MaxMessageSize {
+		t.Fatalf("frame of %d bytes exceeds read limit %d", len(frame), config.MaxMessageSize)
+	}
+}
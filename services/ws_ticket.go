@@ -0,0 +1,31 @@
+package services
+
+import (
+	"log"
+	"ongi-back/database"
+	"ongi-back/models"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ConsumeWSTicket 티켓 ID를 사용 처리 (이미 사용된 티켓이면 false)
+// 사용 기록은 DB에 저장하므로 여러 인스턴스 중 한 곳에서만 한 번 사용 가능
+func ConsumeWSTicket(ticketID string, expiresAt time.Time) bool {
+	// 만료된 항목 정리 (만료된 티켓은 검증 단계에서 거부되므로 기록이 필요 없음)
+	if err := database.DB.Where("expires_at < ?", time.Now()).Delete(&models.UsedWSTicket{}).Error; err != nil {
+		log.Printf("Failed to clean used websocket tickets: %v", err)
+	}
+
+	// ticket_id가 기본 키라 동시에 사용해도 한 요청만 삽입됨
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UsedWSTicket{
+		TicketID:  ticketID,
+		ExpiresAt: expiresAt,
+	})
+	if result.Error != nil {
+		log.Printf("Failed to record websocket ticket: %v", result.Error)
+		return false
+	}
+
+	return result.RowsAffected == 1
+}
@@ -64,7 +64,6 @@ type Result struct {
 	Descriptions    []string `json:"descriptions"`
 	Recommendations struct {
 		Clubs        []Club `json:"clubs"`
-		SimilarClubs []Club `json:"similar_clubs"`
 		SimilarUsers []User `json:"similar_users"`
 	} `json:"recommendations"`
 }
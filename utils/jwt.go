@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"time"
@@ -8,6 +11,16 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// 토큰 유효 기간
+const (
+	AccessTokenExpiry  = 15 * time.Minute    // Access Token: 15분
+	RefreshTokenExpiry = 30 * 24 * time.Hour // Refresh Token: 30일
+	WSTicketExpiry     = 30 * time.Second    // WebSocket 연결 티켓: 30초
+)
+
+// wsTicketSubject WebSocket 티켓 구분용 subject (Access Token으로 사용 불가)
+const wsTicketSubject = "ws_ticket"
+
 // Claims JWT 클레임 구조체
 type Claims struct {
 	UserID uint   `json:"user_id"`
@@ -22,8 +35,8 @@ func GenerateJWT(userID uint, email string) (string, error) {
 		jwtSecret = "your-secret-key-change-in-production" // fallback
 	}
 
-	// 토큰 만료 시간: 7일
-	expirationTime := time.Now().Add(7 * 24 * time.Hour)
+	// 토큰 만료 시간: 15분 (Refresh Token으로 재발급)
+	expirationTime := time.Now().Add(AccessTokenExpiry)
 
 	claims := &Claims{
 		UserID: userID,
@@ -68,5 +81,91 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 		return nil, fmt.Errorf("invalid token")
 	}
 
+	if claims.Subject == wsTicketSubject {
+		return nil, fmt.Errorf("websocket ticket cannot be used as access token")
+	}
+
+	return claims, nil
+}
+
+// WSTicketClaims WebSocket 연결 티켓 클레임
+type WSTicketClaims struct {
+	UserID uint `json:"user_id"`
+	RoomID uint `json:"room_id"`
+	jwt.RegisteredClaims
+}
+
+// GenerateWSTicket 특정 채팅방에 대한 단기 WebSocket 연결 티켓 생성
+func GenerateWSTicket(userID, roomID uint) (*WSTicketClaims, string, error) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		jwtSecret = "your-secret-key-change-in-production" // fallback
+	}
+
+	ticketID, err := GenerateRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	claims := &WSTicketClaims{
+		UserID: userID,
+		RoomID: roomID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        ticketID,
+			Subject:   wsTicketSubject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(WSTicketExpiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "ongi-back",
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	ticket, err := token.SignedString([]byte(jwtSecret))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign ticket: %w", err)
+	}
+
+	return claims, ticket, nil
+}
+
+// ValidateWSTicket WebSocket 연결 티켓 검증
+func ValidateWSTicket(ticket string) (*WSTicketClaims, error) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		jwtSecret = "your-secret-key-change-in-production" // fallback
+	}
+
+	claims := &WSTicketClaims{}
+
+	token, err := jwt.ParseWithClaims(ticket, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ticket: %w", err)
+	}
+
+	if !token.Valid || claims.Subject != wsTicketSubject {
+		return nil, fmt.Errorf("invalid ticket")
+	}
+
 	return claims, nil
 }
+
+// GenerateRefreshToken 불투명(opaque) Refresh Token 생성
+func GenerateRefreshToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// HashToken 토큰을 DB 저장용 SHA-256 해시로 변환
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// AttachmentURLExpiry 첨부파일 다운로드 URL 유효 기간
+const AttachmentURLExpiry = 15 * time.Minute
+
+// SignAttachmentURL 첨부파일 다운로드용 서명 URL 생성
+// URL에는 발급받은 사용자 ID가 포함되어, 다운로드 시점에 채팅방 멤버인지 다시 확인할 수 있음
+func SignAttachmentURL(attachmentID, userID uint, variant string, expiresAt time.Time) string {
+	expires := expiresAt.Unix()
+
+	query := url.Values{}
+	if variant != "" {
+		query.Set("variant", variant)
+	}
+	query.Set("uid", strconv.FormatUint(uint64(userID), 10))
+	query.Set("exp", strconv.FormatInt(expires, 10))
+	query.Set("sig", attachmentSignature(attachmentID, userID, variant, expires))
+
+	return fmt.Sprintf("/api/v1/attachments/%d?%s", attachmentID, query.Encode())
+}
+
+// VerifyAttachmentSignature 서명 URL 검증 (만료 포함)
+func VerifyAttachmentSignature(attachmentID, userID uint, variant string, expires int64, signature string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+
+	expected := attachmentSignature(attachmentID, userID, variant, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// attachmentSignature HMAC-SHA256(attachmentID|userID|variant|expires)
+func attachmentSignature(attachmentID, userID uint, variant string, expires int64) string {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		secret = "your-secret-key-change-in-production" // fallback
+	}
+
+	mac := hmac.New(sha256.New, []byte("attachment:"+secret))
+	fmt.Fprintf(mac, "%d|%d|%s|%d", attachmentID, userID, variant, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
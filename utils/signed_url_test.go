@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseSignedURL 서명 URL에서 검증에 필요한 값 추출
+func parseSignedURL(t *testing.T, raw string) (uint, uint, string, int64, string) {
+	t.Helper()
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("invalid signed URL %q: %v", raw, err)
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(parsed.Path, "/api/v1/attachments/"), 10, 32)
+	if err != nil {
+		t.Fatalf("unexpected path %q", parsed.Path)
+	}
+	query := parsed.Query()
+	uid, _ := strconv.ParseUint(query.Get("uid"), 10, 32)
+	exp, _ := strconv.ParseInt(query.Get("exp"), 10, 64)
+	return uint(id), uint(uid), query.Get("variant"), exp, query.Get("sig")
+}
+
+func TestVerifyAttachmentSignature(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	expiresAt := time.Now().Add(AttachmentURLExpiry)
+	id, uid, variant, exp, sig := parseSignedURL(t, SignAttachmentURL(42, 7, "thumbnail", expiresAt))
+	if id != 42 || uid != 7 || variant != "thumbnail" || exp != expiresAt.Unix() {
+		t.Fatalf("unexpected signed URL values: id=%d uid=%d variant=%q exp=%d", id, uid, variant, exp)
+	}
+
+	expired := time.Now().Add(-time.Second)
+	_, _, _, expiredExp, expiredSig := parseSignedURL(t, SignAttachmentURL(42, 7, "thumbnail", expired))
+
+	tests := []struct {
+		name         string
+		attachmentID uint
+		userID       uint
+		variant      string
+		expires      int64
+		signature    string
+		want         bool
+	}{
+		{"valid", id, uid, variant, exp, sig, true},
+		{"other attachment", id + 1, uid, variant, exp, sig, false},
+		{"other user", id, uid + 1, variant, exp, sig, false},
+		{"original instead of thumbnail", id, uid, "", exp, sig, false},
+		{"extended expiry", id, uid, variant, exp + 3600, sig, false},
+		{"tampered signature", id, uid, variant, exp, strings.Repeat("0", len(sig)), false},
+		{"truncated signature", id, uid, variant, exp, sig[:len(sig)-2], false},
+		{"empty signature", id, uid, variant, exp, "", false},
+		{"expired", id, uid, variant, expiredExp, expiredSig, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VerifyAttachmentSignature(tt.attachmentID, tt.userID, tt.variant, tt.expires, tt.signature)
+			if got != tt.want {
+				t.Errorf("VerifyAttachmentSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachmentSignatureDependsOnSecret(t *testing.T) {
+	expires := time.Now().Add(AttachmentURLExpiry).Unix()
+
+	t.Setenv("JWT_SECRET", "secret-a")
+	signature := attachmentSignature(1, 2, "", expires)
+
+	t.Setenv("JWT_SECRET", "secret-b")
+	if VerifyAttachmentSignature(1, 2, "", expires, signature) {
+		t.Fatal("signature from another secret should not verify")
+	}
+}
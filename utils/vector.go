@@ -2,7 +2,6 @@ package utils
 
 import (
 	"math"
-	"sync"
 )
 
 // Vector5D - 5차원 벡터 (성향 점수)
@@ -77,16 +76,7 @@ func CosineSimilarity(v1, v2 *Vector5D) float64 {
 
 // Similarity - 유사도를 0-100 점수로 변환
 func Similarity(v1, v2 *Vector5D) float64 {
-	distance := EuclideanDistance(v1, v2)
-	maxDistance := math.Sqrt(5 * 100 * 100) // 최대 거리 (각 차원 최대 100)
-	similarity := (1 - (distance / maxDistance)) * 100
-
-	// 음수 방지
-	if similarity < 0 {
-		similarity = 0
-	}
-
-	return math.Round(similarity*10) / 10
+	return DistanceToSimilarity(EuclideanDistance(v1, v2))
 }
 
 // SimilarityScore - 코사인 유사도를 0-100 점수로 변환
@@ -97,54 +87,6 @@ func SimilarityScore(v1, v2 *Vector5D) float64 {
 	return math.Round(score*10) / 10
 }
 
-// BatchSimilarity - 여러 벡터와의 유사도를 병렬로 계산
-type SimilarityResult struct {
-	Index      int
-	Similarity float64
-}
-
-func BatchSimilarity(target *Vector5D, vectors []*Vector5D, workers int) []SimilarityResult {
-	if workers <= 0 {
-		workers = 4 // 기본 워커 수
-	}
-
-	results := make([]SimilarityResult, len(vectors))
-	var wg sync.WaitGroup
-
-	// 작업을 워커들에게 분배
-	chunkSize := len(vectors) / workers
-	if chunkSize == 0 {
-		chunkSize = 1
-	}
-
-	for w := 0; w < workers; w++ {
-		start := w * chunkSize
-		end := start + chunkSize
-
-		if w == workers-1 {
-			end = len(vectors) // 마지막 워커는 나머지 전부 처리
-		}
-
-		if start >= len(vectors) {
-			break
-		}
-
-		wg.Add(1)
-		go func(start, end int) {
-			defer wg.Done()
-			for i := start; i < end && i < len(vectors); i++ {
-				results[i] = SimilarityResult{
-					Index:      i,
-					Similarity: SimilarityScore(target, vectors[i]),
-				}
-			}
-		}(start, end)
-	}
-
-	wg.Wait()
-	return results
-}
-
 // WeightedVector - 가중치를 적용한 벡터
 func (v *Vector5D) ApplyWeights(weights *Vector5D) *Vector5D {
 	return &Vector5D{
@@ -0,0 +1,387 @@
+package utils
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Neighbor - 최근접 이웃 검색 결과
+type Neighbor struct {
+	ID         string
+	Vector     Vector5D
+	Distance   float64 // 유클리드 거리
+	Similarity float64 // 0-100 점수 (거리 기준이면 Similarity와 같은 값)
+}
+
+// VectorIndex - 5차원 벡터 최근접 이웃 검색 인덱스 (ID당 벡터 하나)
+type VectorIndex interface {
+	Upsert(id string, v Vector5D)
+	Remove(id string)
+	Len() int
+	IDs() []string
+	// Search - target과 가장 가까운 k개 (skip이 true인 ID 제외, 가까운 순)
+	Search(target Vector5D, k int, skip func(id string) bool) []Neighbor
+}
+
+// DistanceToSimilarity - 유클리드 거리를 0-100 유사도 점수로 변환
+func DistanceToSimilarity(distance float64) float64 {
+	maxDistance := math.Sqrt(5 * 100 * 100) // 최대 거리 (각 차원 최대 100)
+	similarity := (1 - (distance / maxDistance)) * 100
+
+	// 음수 방지
+	if similarity < 0 {
+		similarity = 0
+	}
+
+	return math.Round(similarity*10) / 10
+}
+
+// array - 좌표 배열 (k-d 트리 축 인덱스용)
+func (v *Vector5D) array() [5]float64 {
+	return [5]float64{v.Sociality, v.Activity, v.Intimacy, v.Immersion, v.Flexibility}
+}
+
+func squaredDistance(a, b [5]float64) float64 {
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return sum
+}
+
+// neighborHeap - 가장 먼 후보가 맨 위에 오는 최대 힙 (상위 k개 유지용)
+type neighborHeap []heapItem
+
+type heapItem struct {
+	id    string
+	point [5]float64
+	dist  float64 // 거리 제곱
+}
+
+func (h neighborHeap) Len() int { return len(h) }
+func (h neighborHeap) Less(i, j int) bool {
+	if h[i].dist != h[j].dist {
+		return h[i].dist > h[j].dist
+	}
+	return h[i].id > h[j].id
+}
+func (h neighborHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *neighborHeap) Push(x interface{}) { *h = append(*h, x.(heapItem)) }
+func (h *neighborHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// offer - 상위 k개에 들어가면 추가
+func (h *neighborHeap) offer(k int, item heapItem) {
+	if h.Len() < k {
+		heap.Push(h, item)
+		return
+	}
+	top := (*h)[0]
+	if item.dist < top.dist || (item.dist == top.dist && item.id < top.id) {
+		(*h)[0] = item
+		heap.Fix(h, 0)
+	}
+}
+
+// worst - 현재 k번째 후보의 거리 제곱 (아직 k개가 안 되면 +Inf)
+func (h neighborHeap) worst(k int) float64 {
+	if len(h) < k {
+		return math.Inf(1)
+	}
+	return h[0].dist
+}
+
+// sorted - 가까운 순으로 정렬한 결과
+func (h neighborHeap) sorted() []Neighbor {
+	items := []heapItem(h)
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].dist != items[j].dist {
+			return items[i].dist < items[j].dist
+		}
+		return items[i].id < items[j].id
+	})
+
+	neighbors := make([]Neighbor, len(items))
+	for i, item := range items {
+		distance := math.Sqrt(item.dist)
+		neighbors[i] = Neighbor{
+			ID:         item.id,
+			Vector:     *FromSlice(item.point[:]),
+			Distance:   distance,
+			Similarity: DistanceToSimilarity(distance),
+		}
+	}
+	return neighbors
+}
+
+// KDTree - k-d 트리 인덱스
+// 삽입은 트리 끝에 붙이고 삭제는 표시만 하며, 삭제된 노드가 많아지거나
+// 크기가 마지막 재구성 때의 두 배가 되면 중앙값 기준으로 다시 구성 (균형 유지)
+type KDTree struct {
+	mu      sync.RWMutex
+	nodes   []kdNode
+	root    int
+	live    map[string]int // ID → 살아있는 노드 인덱스
+	builtAt int            // 마지막 재구성 때의 노드 수
+}
+
+type kdNode struct {
+	id          string
+	point       [5]float64
+	left, right int // 자식 노드 인덱스 (-1이면 없음)
+	deleted     bool
+}
+
+// NewKDTree - 빈 k-d 트리 생성
+func NewKDTree() *KDTree {
+	return &KDTree{root: -1, live: make(map[string]int)}
+}
+
+// Len - 인덱스된 벡터 수
+func (t *KDTree) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.live)
+}
+
+// IDs - 인덱스된 ID 목록
+func (t *KDTree) IDs() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	ids := make([]string, 0, len(t.live))
+	for id := range t.live {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Upsert - 벡터 추가 또는 교체
+func (t *KDTree) Upsert(id string, v Vector5D) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i, ok := t.live[id]; ok {
+		if t.nodes[i].point == v.array() {
+			return
+		}
+		t.nodes[i].deleted = true
+	}
+
+	t.nodes = append(t.nodes, kdNode{id: id, point: v.array(), left: -1, right: -1})
+	n := len(t.nodes) - 1
+	t.live[id] = n
+
+	if t.root < 0 {
+		t.root = n
+	} else {
+		cur, depth := t.root, 0
+		for {
+			axis := depth % 5
+			next := &t.nodes[cur].right
+			if t.nodes[n].point[axis] < t.nodes[cur].point[axis] {
+				next = &t.nodes[cur].left
+			}
+			if *next < 0 {
+				*next = n
+				break
+			}
+			cur, depth = *next, depth+1
+		}
+	}
+
+	t.maybeRebuild()
+}
+
+// Remove - 벡터 삭제
+func (t *KDTree) Remove(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i, ok := t.live[id]; ok {
+		t.nodes[i].deleted = true
+		delete(t.live, id)
+		t.maybeRebuild()
+	}
+}
+
+// maybeRebuild - 삭제된 노드가 절반을 넘거나 마지막 재구성 이후 두 배로 커지면 재구성
+func (t *KDTree) maybeRebuild() {
+	deleted := len(t.nodes) - len(t.live)
+	if deleted > len(t.live) || len(t.nodes) > 2*t.builtAt+64 {
+		t.rebuild()
+	}
+}
+
+// rebuild - 살아있는 노드로 균형 잡힌 트리 재구성
+func (t *KDTree) rebuild() {
+	nodes := make([]kdNode, 0, len(t.live))
+	for _, i := range t.live {
+		nodes = append(nodes, kdNode{id: t.nodes[i].id, point: t.nodes[i].point})
+	}
+	// 같은 데이터면 같은 트리가 되도록 ID 순으로 시작
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+
+	t.nodes = nodes
+	t.root = t.build(0, len(nodes), 0)
+	t.builtAt = len(nodes)
+
+	t.live = make(map[string]int, len(nodes))
+	for i := range t.nodes {
+		t.live[t.nodes[i].id] = i
+	}
+}
+
+// build - nodes[lo:hi]를 축 기준 중앙값으로 나눠 서브트리 구성 (중앙값 노드 인덱스 반환)
+func (t *KDTree) build(lo, hi, depth int) int {
+	if lo >= hi {
+		return -1
+	}
+
+	// 축 기준 중앙값을 mid에 두고 왼쪽은 작거나 같은 값, 오른쪽은 크거나 같은 값
+	axis := depth % 5
+	mid := lo + (hi-lo)/2
+	selectNth(t.nodes[lo:hi], mid-lo, axis)
+
+	t.nodes[mid].left = t.build(lo, mid, depth+1)
+	t.nodes[mid].right = t.build(mid+1, hi, depth+1)
+	return mid
+}
+
+// selectNth - nodes[n]에 axis 기준 n번째 값이 오도록 부분 정렬 (quickselect)
+func selectNth(nodes []kdNode, n, axis int) {
+	lo, hi := 0, len(nodes)-1
+	for lo < hi {
+		pivot := nodes[lo+(hi-lo)/2].point[axis]
+		i, j := lo, hi
+		for i <= j {
+			for nodes[i].point[axis] < pivot {
+				i++
+			}
+			for nodes[j].point[axis] > pivot {
+				j--
+			}
+			if i <= j {
+				nodes[i], nodes[j] = nodes[j], nodes[i]
+				i++
+				j--
+			}
+		}
+		if n <= j {
+			hi = j
+		} else if n >= i {
+			lo = i
+		} else {
+			return
+		}
+	}
+}
+
+// Search - k개 최근접 이웃 검색
+func (t *KDTree) Search(target Vector5D, k int, skip func(id string) bool) []Neighbor {
+	if k <= 0 {
+		return []Neighbor{}
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	h := &neighborHeap{}
+	t.search(t.root, 0, target.array(), k, skip, h)
+	return h.sorted()
+}
+
+func (t *KDTree) search(i, depth int, target [5]float64, k int, skip func(string) bool, h *neighborHeap) {
+	if i < 0 {
+		return
+	}
+
+	node := &t.nodes[i]
+	if !node.deleted && (skip == nil || !skip(node.id)) {
+		h.offer(k, heapItem{id: node.id, point: node.point, dist: squaredDistance(node.point, target)})
+	}
+
+	axis := depth % 5
+	diff := target[axis] - node.point[axis]
+	near, far := node.right, node.left
+	if diff < 0 {
+		near, far = node.left, node.right
+	}
+
+	t.search(near, depth+1, target, k, skip, h)
+	// 분할 평면까지의 거리가 현재 k번째 후보보다 가까울 때만 반대편 탐색
+	if diff*diff <= h.worst(k) {
+		t.search(far, depth+1, target, k, skip, h)
+	}
+}
+
+// BruteForceIndex - 전체 비교 인덱스 (k-d 트리 결과 검증 및 소규모 데이터용)
+type BruteForceIndex struct {
+	mu     sync.RWMutex
+	points map[string][5]float64
+}
+
+// NewBruteForceIndex - 빈 전체 비교 인덱스 생성
+func NewBruteForceIndex() *BruteForceIndex {
+	return &BruteForceIndex{points: make(map[string][5]float64)}
+}
+
+// Len - 인덱스된 벡터 수
+func (b *BruteForceIndex) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.points)
+}
+
+// IDs - 인덱스된 ID 목록
+func (b *BruteForceIndex) IDs() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	ids := make([]string, 0, len(b.points))
+	for id := range b.points {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Upsert - 벡터 추가 또는 교체
+func (b *BruteForceIndex) Upsert(id string, v Vector5D) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.points[id] = v.array()
+}
+
+// Remove - 벡터 삭제
+func (b *BruteForceIndex) Remove(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.points, id)
+}
+
+// Search - 모든 벡터와 거리를 비교해 k개 최근접 이웃 검색
+func (b *BruteForceIndex) Search(target Vector5D, k int, skip func(id string) bool) []Neighbor {
+	if k <= 0 {
+		return []Neighbor{}
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	t := target.array()
+	h := &neighborHeap{}
+	for id, point := range b.points {
+		if skip != nil && skip(id) {
+			continue
+		}
+		h.offer(k, heapItem{id: id, point: point, dist: squaredDistance(point, t)})
+	}
+	return h.sorted()
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// randomProfile 설문 점수 분포와 비슷하게 20-100 범위 정수 점수 생성
+func randomProfile(rng *rand.Rand) Vector5D {
+	score := func() float64 { return float64(20 + rng.Intn(81)) }
+	return Vector5D{
+		Sociality:   score(),
+		Activity:    score(),
+		Intimacy:    score(),
+		Immersion:   score(),
+		Flexibility: score(),
+	}
+}
+
+// coarseProfile 좌표가 몇 가지 값뿐이라 같은 거리(동점)가 많이 생기는 점수
+func coarseProfile(rng *rand.Rand) Vector5D {
+	score := func() float64 { return float64(20 * rng.Intn(6)) }
+	return Vector5D{
+		Sociality:   score(),
+		Activity:    score(),
+		Intimacy:    score(),
+		Immersion:   score(),
+		Flexibility: score(),
+	}
+}
+
+func assertSameNeighbors(t *testing.T, step int, got, want []Neighbor) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("step %d: kdtree returned %d neighbors, brute force %d", step, len(got), len(want))
+	}
+	for i := range got {
+		if got[i].ID != want[i].ID || got[i].Distance != want[i].Distance {
+			t.Fatalf("step %d: neighbor %d = %s (%.3f), brute force %s (%.3f)",
+				step, i, got[i].ID, got[i].Distance, want[i].ID, want[i].Distance)
+		}
+	}
+}
+
+// TestKDTreeMatchesBruteForce 무작위 추가/수정/삭제 중에도 k-d 트리와 전체 비교 결과가 같은지 (동점, skip 포함)
+func TestKDTreeMatchesBruteForce(t *testing.T) {
+	for name, profile := range map[string]func(*rand.Rand) Vector5D{
+		"random": randomProfile,
+		"ties":   coarseProfile,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			tree := NewKDTree()
+			brute := NewBruteForceIndex()
+
+			const ids = 2000
+			for step := 0; step < 20000; step++ {
+				id := strconv.Itoa(rng.Intn(ids))
+				if rng.Intn(4) == 0 {
+					tree.Remove(id)
+					brute.Remove(id)
+				} else {
+					v := profile(rng)
+					tree.Upsert(id, v)
+					brute.Upsert(id, v)
+				}
+
+				if step%200 != 0 {
+					continue
+				}
+				if tree.Len() != brute.Len() {
+					t.Fatalf("step %d: kdtree len %d, brute force len %d", step, tree.Len(), brute.Len())
+				}
+
+				target := profile(rng)
+				k := 1 + rng.Intn(30)
+				assertSameNeighbors(t, step, tree.Search(target, k, nil), brute.Search(target, k, nil))
+
+				mod := 2 + rng.Intn(5)
+				skip := func(id string) bool {
+					n, _ := strconv.Atoi(id)
+					return n%mod == 0
+				}
+				assertSameNeighbors(t, step, tree.Search(target, k, skip), brute.Search(target, k, skip))
+			}
+		})
+	}
+}
+
+// TestKDTreeSearchSmallIndex 인덱스보다 큰 k, 빈 인덱스, k <= 0
+func TestKDTreeSearchSmallIndex(t *testing.T) {
+	tree := NewKDTree()
+	if got := tree.Search(Vector5D{}, 5, nil); len(got) != 0 {
+		t.Fatalf("empty index returned %d neighbors", len(got))
+	}
+
+	tree.Upsert("a", Vector5D{Sociality: 10})
+	tree.Upsert("b", Vector5D{Sociality: 20})
+	if got := tree.Search(Vector5D{}, 5, nil); len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Fatalf("search = %+v", got)
+	}
+	if got := tree.Search(Vector5D{}, 0, nil); len(got) != 0 {
+		t.Fatalf("k=0 returned %d neighbors", len(got))
+	}
+}
+
+func benchmarkSearch(b *testing.B, index VectorIndex, n int) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		index.Upsert(strconv.Itoa(i), randomProfile(rng))
+	}
+	targets := make([]Vector5D, 1000)
+	for i := range targets {
+		targets[i] = randomProfile(rng)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Search(targets[i%len(targets)], 20, nil)
+	}
+}
+
+func BenchmarkKDTreeSearch10k(b *testing.B)      { benchmarkSearch(b, NewKDTree(), 10000) }
+func BenchmarkKDTreeSearch100k(b *testing.B)     { benchmarkSearch(b, NewKDTree(), 100000) }
+func BenchmarkBruteForceSearch10k(b *testing.B)  { benchmarkSearch(b, NewBruteForceIndex(), 10000) }
+func BenchmarkBruteForceSearch100k(b *testing.B) { benchmarkSearch(b, NewBruteForceIndex(), 100000) }